@@ -0,0 +1,91 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// backupCmd represents the backup command
+func backupCmd() *cobra.Command {
+	var output string
+	c := &cobra.Command{
+		Use:   "backup <LoadBalancer>...",
+		Short: "Write the configuration of a LoadBalancer + everything attached to a file",
+		Long: `Write the configuration of one or more LoadBalancers + everything attached to
+a YAML spec: listeners with their TLS references, pools with algorithm and
+session persistence, members, health monitors and L7 policies with their rules.
+
+Each LoadBalancer is written to its own file, oli-backup-<id>-<timestamp>.yaml
+by default. Recreate a LoadBalancer from it with "oli restore <file>".`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && len(args) > 1 {
+				return fmt.Errorf("--output can only be used with a single loadbalancer")
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			osClient, err := newOpenStackProvider(client.Config{})
+			if err != nil {
+				return err
+			}
+			ids, err := resolveLoadBalancers(ctx, osClient, args)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				path := output
+				if path == "" {
+					path = backupPath(".", id)
+				}
+				if err := backupLoadBalancer(ctx, osClient, id, path); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	c.Flags().StringVarP(&output, "output", "o", "", "Spec file to write (default is oli-backup-<id>-<timestamp>.yaml).")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd())
+}
+
+// backupPath returns the default path of the backup of a LoadBalancer.
+func backupPath(dir, id string) string {
+	return filepath.Join(dir, fmt.Sprintf("oli-backup-%s-%s.yaml", id, time.Now().Format("20060102-150405")))
+}
+
+// backupLoadBalancer writes the spec of a LoadBalancer to path.
+func backupLoadBalancer(ctx context.Context, osClient client.OpenStackProvider, id, path string) error {
+	s, err := osClient.ExportLoadBalancer(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to back up loadbalancer %s: %w", id, err)
+	}
+	if err := s.Write(path); err != nil {
+		return err
+	}
+	fmt.Printf("wrote backup of loadbalancer %s to %s\n", id, path)
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/spec"
+)
+
+func TestDeleteTakesBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := fakecloud.NewServer()
+	defer s.Close()
+	// the command polls every 2 seconds
+	s.PendingTicks = 0
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web", VipSubnetID: "subnet-1"})
+	setFakeEnv(s)
+
+	c := deleteCmd()
+	c.SetArgs([]string{lb.ID, "--no-dry-run", "--yes", "--backup-dir", dir, "--journal", filepath.Join(dir, "delete.journal")})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("delete failed: %s", err)
+	}
+	if s.Exists(lb.ID) {
+		t.Errorf("loadbalancer %s was not deleted", lb.ID)
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, "oli-backup-"+lb.ID+"-*.yaml"))
+	if len(backups) != 1 {
+		t.Fatalf("got backups %v, want one", backups)
+	}
+	backup, err := spec.Read(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup.LoadBalancer.ID != lb.ID || backup.LoadBalancer.Name != "web" {
+		t.Errorf("backup describes loadbalancer %s (%s)", backup.LoadBalancer.ID, backup.LoadBalancer.Name)
+	}
+}
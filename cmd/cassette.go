@@ -0,0 +1,153 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/transport"
+)
+
+// sessionFile holds the metadata of a cassette next to the recorded
+// exchanges.
+const sessionFile = "session.json"
+
+// Auth methods of a recorded session.
+const (
+	authPassword              = "password"
+	authApplicationCredential = "application_credential"
+	authToken                 = "token"
+)
+
+// cassetteSession is everything besides the exchanges needed to replay a
+// recorded command. It never contains secrets.
+type cassetteSession struct {
+	RecordedAt       time.Time `json:"recorded_at"`
+	Args             []string  `json:"args"`
+	IdentityEndpoint string    `json:"identity_endpoint"`
+	// AuthMethod is how the recording authenticated, sessions without one
+	// used a password.
+	AuthMethod                string `json:"auth_method,omitempty"`
+	Username                  string `json:"username,omitempty"`
+	UserID                    string `json:"user_id,omitempty"`
+	ApplicationCredentialID   string `json:"application_credential_id,omitempty"`
+	ApplicationCredentialName string `json:"application_credential_name,omitempty"`
+	DomainName                string `json:"domain_name,omitempty"`
+	DomainID                  string `json:"domain_id,omitempty"`
+	TenantName                string `json:"tenant_name,omitempty"`
+	TenantID                  string `json:"tenant_id,omitempty"`
+	Region                    string `json:"region,omitempty"`
+}
+
+// authMethod returns the auth method gophercloud uses for opts.
+func authMethod(opts gophercloud.AuthOptions) string {
+	switch {
+	case opts.TokenID != "":
+		return authToken
+	case opts.ApplicationCredentialID != "" || opts.ApplicationCredentialName != "":
+		return authApplicationCredential
+	default:
+		return authPassword
+	}
+}
+
+// writeSession stores the session of the current command in dir. The
+// credentials are taken from the environment unless opts is set.
+func writeSession(dir string, opts *gophercloud.AuthOptions) error {
+	if opts == nil {
+		// missing credentials are reported when the client authenticates
+		env, _ := client.AuthOptionsFromEnv()
+		opts = &env
+	}
+	session := cassetteSession{
+		RecordedAt:                time.Now().UTC(),
+		Args:                      os.Args[1:],
+		IdentityEndpoint:          opts.IdentityEndpoint,
+		AuthMethod:                authMethod(*opts),
+		Username:                  opts.Username,
+		UserID:                    opts.UserID,
+		ApplicationCredentialID:   opts.ApplicationCredentialID,
+		ApplicationCredentialName: opts.ApplicationCredentialName,
+		DomainName:                opts.DomainName,
+		DomainID:                  opts.DomainID,
+		TenantName:                opts.TenantName,
+		TenantID:                  opts.TenantID,
+		Region:                    os.Getenv("OS_REGION_NAME"),
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, sessionFile), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write session: %s", err)
+	}
+	return nil
+}
+
+// readSession returns the client config replaying the cassette in dir.
+func readSession(dir string) (client.Config, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, sessionFile))
+	if err != nil {
+		return client.Config{}, fmt.Errorf("failed to read session: %s", err)
+	}
+	var session cassetteSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return client.Config{}, fmt.Errorf("failed to parse session: %s", err)
+	}
+	opts := &gophercloud.AuthOptions{
+		IdentityEndpoint: session.IdentityEndpoint,
+		TenantName:       session.TenantName,
+		TenantID:         session.TenantID,
+	}
+	// the recorded secrets were redacted and are not checked
+	switch session.AuthMethod {
+	case authApplicationCredential:
+		opts.ApplicationCredentialID = session.ApplicationCredentialID
+		opts.ApplicationCredentialName = session.ApplicationCredentialName
+		opts.ApplicationCredentialSecret = transport.Redacted
+		// a credential given by name belongs to a user
+		if opts.ApplicationCredentialID == "" {
+			opts.Username = session.Username
+			opts.UserID = session.UserID
+			opts.DomainName = session.DomainName
+			opts.DomainID = session.DomainID
+		}
+	case authToken:
+		// a token already carries the user and its domain
+		opts.TokenID = transport.Redacted
+	case authPassword, "":
+		opts.Username = session.Username
+		opts.UserID = session.UserID
+		opts.Password = transport.Redacted
+		opts.DomainName = session.DomainName
+		opts.DomainID = session.DomainID
+	default:
+		return client.Config{}, fmt.Errorf("unknown auth method %q in session", session.AuthMethod)
+	}
+	return client.Config{
+		AuthOptions: opts,
+		Region:      session.Region,
+		// there is nothing to wait for in a recording
+		PollInterval: time.Millisecond,
+	}, nil
+}
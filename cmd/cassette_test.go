@@ -0,0 +1,111 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/fakecloud"
+)
+
+func setFakeEnv(s *fakecloud.Server) {
+	env := map[string]string{
+		"OS_AUTH_URL":         s.AuthOptions().IdentityEndpoint,
+		"OS_USERNAME":         fakecloud.Username,
+		"OS_PASSWORD":         fakecloud.Password,
+		"OS_USER_DOMAIN_NAME": fakecloud.DomainName,
+		"OS_TENANT_ID":        fakecloud.ProjectID,
+		"OS_REGION_NAME":      fakecloud.Region,
+	}
+	for key, value := range env {
+		os.Setenv(key, value)
+	}
+}
+
+func TestListRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := fakecloud.NewServer()
+	seedListTree(s)
+	setFakeEnv(s)
+
+	listCloud := func() string {
+		p, err := newOpenStackProvider(client.Config{})
+		if err != nil {
+			t.Fatalf("failed to create provider: %s", err)
+		}
+		return list(t, p, false)
+	}
+
+	recordDir = dir
+	recorded := listCloud()
+	recordDir = ""
+	s.Close()
+	os.Setenv("OS_PASSWORD", "")
+
+	replayDir = dir
+	defer func() { replayDir = "" }()
+	if replayed := listCloud(); replayed != recorded {
+		t.Errorf("replayed output differs, got:\n%s\nwant:\n%s", replayed, recorded)
+	}
+
+	// sessions recorded with other auth methods replay with the same method
+	path := filepath.Join(dir, sessionFile)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recordedSession cassetteSession
+	if err := json.Unmarshal(data, &recordedSession); err != nil {
+		t.Fatal(err)
+	}
+	if recordedSession.AuthMethod != authPassword {
+		t.Fatalf("recorded auth method %q, want %q", recordedSession.AuthMethod, authPassword)
+	}
+	for _, tc := range []struct {
+		method string
+		change func(*cassetteSession)
+	}{
+		{authApplicationCredential, func(s *cassetteSession) { s.Username, s.ApplicationCredentialID = "", "ac-1" }},
+		{authApplicationCredential, func(s *cassetteSession) { s.ApplicationCredentialName = "oli" }},
+		{authToken, func(s *cassetteSession) { s.Username = "" }},
+	} {
+		session := recordedSession
+		session.AuthMethod = tc.method
+		tc.change(&session)
+		changed, _ := json.Marshal(session)
+		if err := ioutil.WriteFile(path, changed, 0600); err != nil {
+			t.Fatal(err)
+		}
+		config, err := readSession(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := authMethod(*config.AuthOptions); got != tc.method {
+			t.Errorf("%s: replayed with %s", tc.method, got)
+		}
+		if replayed := listCloud(); replayed != recorded {
+			t.Errorf("%s: replayed output differs, got:\n%s\nwant:\n%s", tc.method, replayed, recorded)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// cloneCmd represents the clone command
+func cloneCmd() *cobra.Command {
+	var opts client.RestoreOptions
+	var toRegion, toProject, name, memberMap, mappingOut string
+	c := &cobra.Command{
+		Use:   "clone <LoadBalancer>",
+		Short: "Recreate a LoadBalancer + everything attached in another region, project or subnet",
+		Long: `Recreate a LoadBalancer + everything attached in another region, project or subnet.
+
+The LoadBalancer is read like "oli backup" does and recreated like "oli restore"
+does, waiting for every object to become ACTIVE before the next one is created.
+The original is left untouched.
+
+Subnets are usually not available in another region, pass --vip-subnet and
+--member-subnet there. --member-map remaps member addresses with a YAML file
+mapping old to new addresses:
+
+  10.0.0.5: 10.8.0.5
+  10.0.0.6: 10.8.0.6
+
+Addresses which are not in the file are kept.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if memberMap != "" {
+				addresses, err := readMemberMap(memberMap)
+				if err != nil {
+					return err
+				}
+				opts.MemberAddresses = addresses
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			source, err := newOpenStackProvider(client.Config{})
+			if err != nil {
+				return err
+			}
+			target := source
+			if toRegion != "" || toProject != "" {
+				if recordDir != "" || replayDir != "" {
+					return fmt.Errorf("--record and --replay cannot be used to clone into another region or project")
+				}
+				authOpts, err := client.AuthOptionsFromEnv()
+				if err != nil {
+					return fmt.Errorf("failed to get auth opts from environment: %s", err)
+				}
+				if toProject != "" {
+					authOpts.TenantID, authOpts.TenantName = toProject, ""
+				}
+				if target, err = newOpenStackProvider(client.Config{AuthOptions: &authOpts, Region: toRegion}); err != nil {
+					return err
+				}
+			}
+
+			ids, err := resolveLoadBalancers(ctx, source, args)
+			if err != nil {
+				return err
+			}
+			s, err := source.ExportLoadBalancer(ctx, ids[0])
+			if err != nil {
+				return err
+			}
+			if name != "" {
+				s.LoadBalancer.Name = name
+			}
+			report, err := target.RestoreLoadBalancer(ctx, s, opts)
+			if report != nil {
+				printRestoreReport(os.Stdout, report)
+				if mappingOut != "" {
+					if werr := writeRestoreReport(mappingOut, report); werr != nil && err == nil {
+						err = werr
+					}
+				}
+			}
+			return err
+		},
+	}
+	c.Flags().StringVar(&toRegion, "to-region", "", "Region to clone into (default is the current region).")
+	c.Flags().StringVar(&toProject, "to-project", "", "ID of the project to clone into (default is the current project).")
+	c.Flags().StringVar(&name, "name", "", "Name of the clone (default is the name of the original).")
+	c.Flags().StringVar(&opts.VipSubnetID, "vip-subnet", "", "Subnet for the VIP (default is the subnet of the original).")
+	c.Flags().StringVar(&opts.VipAddress, "vip-address", "", "VIP address to request for the clone.")
+	c.Flags().StringVar(&opts.MemberSubnetID, "member-subnet", "", "Subnet for all members (default is the subnet of each original member).")
+	c.Flags().StringVar(&memberMap, "member-map", "", "YAML file mapping old to new member addresses.")
+	c.Flags().StringVar(&mappingOut, "mapping-out", "", "Write the ID mapping as JSON to the given file.")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(cloneCmd())
+}
+
+// readMemberMap reads a YAML file mapping old to new member addresses.
+func readMemberMap(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read member map: %s", err)
+	}
+	addresses := map[string]string{}
+	if err := yaml.UnmarshalStrict(data, &addresses); err != nil {
+		return nil, fmt.Errorf("failed to parse member map %s: %s", path, err)
+	}
+	return addresses, nil
+}
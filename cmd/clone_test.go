@@ -0,0 +1,86 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+)
+
+func TestClone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-clone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	memberMap := filepath.Join(dir, "members.yaml")
+	if err := ioutil.WriteFile(memberMap, []byte("10.0.0.5: 10.8.0.5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := fakecloud.NewServer()
+	defer s.Close()
+	// the command polls every 2 seconds
+	s.PendingTicks = 0
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web", VipSubnetID: "subnet-1"})
+	listener := s.AddListener(lb.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+	pool := s.AddPool(lb.ID, listener.ID, pools.Pool{Name: "backends", Protocol: "HTTP", LBMethod: "ROUND_ROBIN"})
+	s.AddMember(pool.ID, pools.Member{Address: "10.0.0.5", ProtocolPort: 8080})
+	s.AddMember(pool.ID, pools.Member{Address: "10.0.0.6", ProtocolPort: 8080})
+	setFakeEnv(s)
+
+	c := cloneCmd()
+	c.SetArgs([]string{lb.ID, "--name", "web-clone", "--vip-subnet", "subnet-2", "--member-map", memberMap})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("clone failed: %s", err)
+	}
+
+	osClient := newFakeProvider(t, s)
+	ctx := context.Background()
+	lbs, err := osClient.ListLBaaS(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lbs) != 2 || lbs[0].ID != lb.ID {
+		t.Fatalf("got loadbalancers %v, want the original and its clone", lbs)
+	}
+	clone := lbs[1]
+	if clone.Name != "web-clone" || clone.VipSubnetID != "subnet-2" {
+		t.Errorf("got clone %s in subnet %s, want web-clone in subnet-2", clone.Name, clone.VipSubnetID)
+	}
+	clonePools, err := osClient.GetPoolsForLoadbalancerID(ctx, clone.ID)
+	if err != nil || len(clonePools) != 1 {
+		t.Fatalf("got pools %v (%v), want one", clonePools, err)
+	}
+	members, err := osClient.GetMembersForPoolID(ctx, clonePools[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addresses []string
+	for _, m := range members {
+		addresses = append(addresses, m.Address)
+	}
+	if len(addresses) != 2 || addresses[0] != "10.8.0.5" || addresses[1] != "10.0.0.6" {
+		t.Errorf("got member addresses %v, want [10.8.0.5 10.0.0.6]", addresses)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "os"
+
+// noColor disables colors, as does a non-empty NO_COLOR environment
+// variable (https://no-color.org).
+var noColor bool
+
+// useColor reports whether output to f is colored.
+func useColor(f *os.File) bool {
+	return !noColor && os.Getenv("NO_COLOR") == "" && isTerminal(f)
+}
@@ -0,0 +1,142 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// bashCompletionFunction completes the arguments of commands taking IDs with
+// the live objects of the tenant. Only IDs are ever inserted: names are shown
+// next to the IDs when bash lists several candidates, but not with
+// menu-complete (COMP_TYPE 37) and insert-completions (COMP_TYPE 42), which
+// insert the candidates as they are.
+const bashCompletionFunction = `
+__oli_complete_ids()
+{
+    local out id name
+    local -a ids names
+    out=$(oli __complete-ids "$1" "${cur}" 2>/dev/null) || return
+    while IFS=$'\t' read -r id name; do
+        [[ -n ${id} ]] || continue
+        ids+=("${id}")
+        names+=("${name}")
+    done <<< "${out}"
+    if [[ ${#ids[@]} -eq 1 || ${COMP_TYPE} -eq 37 || ${COMP_TYPE} -eq 42 ]]; then
+        COMPREPLY=("${ids[@]}")
+        return
+    fi
+    local i
+    for i in "${!ids[@]}"; do
+        if [[ -n ${names[i]} ]]; then
+            COMPREPLY+=("${ids[i]}  (${names[i]})")
+        else
+            COMPREPLY+=("${ids[i]}")
+        fi
+    done
+}
+
+__custom_func()
+{
+    case ${last_command} in
+        oli_delete | oli_backup | oli_clone | oli_migrate)
+            __oli_complete_ids loadbalancer
+            ;;
+        oli_describe)
+            __oli_complete_ids all
+            ;;
+    esac
+}
+`
+
+// completionCmd represents the completion command
+func completionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash",
+		Short: "Print the bash completion script",
+		Long: `Print the bash completion script.
+
+Load it in the current shell with
+
+  source <(oli completion bash)
+
+IDs of commands like delete and describe are completed with the live objects of
+your tenant, using the OS_* variables of the shell. zsh can use the script after
+"autoload -U bashcompinit && bashcompinit".`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if args[0] != "bash" {
+				return fmt.Errorf("unsupported shell %q, only bash is supported", args[0])
+			}
+			return rootCmd.GenBashCompletion(os.Stdout)
+		},
+	}
+}
+
+// completeIDsCmd lists the IDs and names of live objects for the completion
+// script.
+func completeIDsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "__complete-ids <kind|all> [prefix]",
+		Hidden: true,
+		Args:   cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prefix := ""
+			if len(args) == 2 {
+				prefix = args[1]
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			osClient, err := newOpenStackProvider(client.Config{})
+			if err != nil {
+				return err
+			}
+			var refs []inventory.Reference
+			if args[0] == client.KindLoadBalancer {
+				lbs, err := osClient.ListLBaaS(ctx)
+				if err != nil {
+					return err
+				}
+				refs = (&inventory.Inventory{LoadBalancers: lbs}).References()
+			} else {
+				inv, err := inventory.Collect(ctx, osClient)
+				if err != nil {
+					return err
+				}
+				refs = inv.References()
+			}
+			for _, r := range refs {
+				if strings.HasPrefix(r.ID, prefix) || strings.HasPrefix(r.Name, prefix) {
+					fmt.Printf("%s\t%s\n", r.ID, r.Name)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func init() {
+	rootCmd.BashCompletionFunction = bashCompletionFunction
+	rootCmd.AddCommand(completionCmd())
+	rootCmd.AddCommand(completeIDsCmd())
+}
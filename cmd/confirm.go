@@ -0,0 +1,113 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// Confirmation levels of real deletion runs.
+const (
+	// confirmPlan asks once for the whole plan.
+	confirmPlan = "plan"
+	// confirmLoadBalancer asks before every LoadBalancer.
+	confirmLoadBalancer = "loadbalancer"
+	// confirmName asks to type the name of every LoadBalancer.
+	confirmName = "name"
+)
+
+var confirmLevels = []string{confirmPlan, confirmLoadBalancer, confirmName}
+
+// validateConfirmLevel returns an error for an unknown confirmation level.
+func validateConfirmLevel(level string) error {
+	for _, l := range confirmLevels {
+		if l == level {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown confirmation level %q, must be one of %s", level, strings.Join(confirmLevels, ", "))
+}
+
+// confirmer asks the user before objects are deleted.
+type confirmer struct {
+	level string
+	in    *bufio.Reader
+	out   io.Writer
+}
+
+// ask prints a question and returns the answer without surrounding spaces.
+// Input which ends before a line is complete is an empty answer.
+func (c *confirmer) ask(question string) string {
+	fmt.Fprint(c.out, question)
+	answer, err := c.in.ReadString('\n')
+	if err != nil {
+		fmt.Fprintln(c.out)
+		return ""
+	}
+	return strings.TrimSpace(answer)
+}
+
+func yes(answer string) bool {
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
+
+// confirmPlan lists the plan and asks once for all of it. It is true for the
+// other levels, which ask per LoadBalancer.
+func (c *confirmer) confirmPlan(plan *client.Plan) bool {
+	if c.level != confirmPlan {
+		return true
+	}
+	n, objects := 0, 0
+	fmt.Fprintln(c.out, "The following loadbalancers will be deleted:")
+	for _, entry := range plan.Entries {
+		if entry.Protected {
+			continue
+		}
+		n++
+		objects += len(entry.Steps)
+		fmt.Fprintf(c.out, "  %s (%s) with %d objects\n", entry.LoadBalancerID, entry.Name, len(entry.Steps))
+	}
+	if n == 0 {
+		return true
+	}
+	return yes(c.ask(fmt.Sprintf("Delete %d loadbalancers with %d objects in total? [y/N] ", n, objects)))
+}
+
+// confirmEntry asks before a single LoadBalancer is deleted. On the name
+// level, LoadBalancers without a name are confirmed with their ID.
+func (c *confirmer) confirmEntry(entry client.PlanEntry) bool {
+	switch c.level {
+	case confirmLoadBalancer:
+		return yes(c.ask(fmt.Sprintf("Delete loadbalancer %s (%s) with %d objects? [y/N] ", entry.LoadBalancerID, entry.Name, len(entry.Steps))))
+	case confirmName:
+		name := entry.Name
+		if name == "" {
+			name = entry.LoadBalancerID
+		}
+		fmt.Fprintf(c.out, "Loadbalancer %s (%s) with %d objects will be deleted.\n", entry.LoadBalancerID, entry.Name, len(entry.Steps))
+		answer := c.ask(fmt.Sprintf("Type %q to confirm: ", name))
+		if answer != name && answer != "" {
+			fmt.Fprintf(c.out, "%q does not match\n", answer)
+		}
+		return answer == name
+	}
+	return true
+}
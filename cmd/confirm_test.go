@@ -0,0 +1,102 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+func TestDeleteConfirmation(t *testing.T) {
+	for _, tc := range []struct {
+		level   string
+		input   string
+		deleted []string
+	}{
+		{confirmPlan, "y\n", []string{"web", "api"}},
+		{confirmPlan, "\n", nil},
+		{confirmLoadBalancer, "n\nyes\n", []string{"api"}},
+		{confirmName, "api\napi\n", []string{"api"}},
+		// input ends before the second question
+		{confirmName, "web\n", []string{"web"}},
+	} {
+		s := fakecloud.NewServer()
+		// the command polls every 2 seconds
+		s.PendingTicks = 0
+		ids := map[string]string{}
+		for _, name := range []string{"web", "api"} {
+			ids[name] = s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: name}).ID
+		}
+		osClient := newFakeProvider(t, s)
+		inv, err := inventory.Collect(context.Background(), osClient)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plan, err := inv.PlanDeletion([]string{ids["web"], ids["api"]})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out := &bytes.Buffer{}
+		run := deleteRun{continueOnError: true, confirm: &confirmer{level: tc.level, in: bufio.NewReader(strings.NewReader(tc.input)), out: out}}
+		if err := run.execute(context.Background(), out, osClient, plan, nil); err != nil {
+			t.Errorf("%s %q: delete failed: %s", tc.level, tc.input, err)
+		}
+		deleted := map[string]bool{}
+		for _, name := range tc.deleted {
+			deleted[name] = true
+		}
+		for name, id := range ids {
+			if s.Exists(id) == deleted[name] {
+				t.Errorf("%s %q: loadbalancer %s exists: %t\n%s", tc.level, tc.input, name, s.Exists(id), out)
+			}
+		}
+		s.Close()
+	}
+}
+
+func TestDeleteRefusesWithoutTerminal(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+	setFakeEnv(s)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	c := deleteCmd()
+	c.SetArgs([]string{lb.ID, "--no-dry-run"})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("got error %v, want a refusal", err)
+	}
+	if !s.Exists(lb.ID) {
+		t.Errorf("loadbalancer was deleted")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/spf13/pflag"
+
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// criteria select the LoadBalancers marked by "oli mark" and deleted by
+// "oli sweep".
+type criteria struct {
+	empty       bool
+	status      string
+	namePattern string
+}
+
+func (c *criteria) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&c.empty, "empty", false, "Only LoadBalancers without listeners and pools.")
+	flags.StringVar(&c.status, "status", "", "Only LoadBalancers with this provisioning or operating status, e.g. ERROR.")
+	flags.StringVar(&c.namePattern, "name-pattern", "", "Only LoadBalancers whose name matches this regular expression.")
+}
+
+// isSet tells whether any criterion was given.
+func (c criteria) isSet() bool {
+	return c.empty || c.status != "" || c.namePattern != ""
+}
+
+// apply returns the LoadBalancers of inv matching all criteria.
+func (c criteria) apply(inv *inventory.Inventory) ([]loadbalancers.LoadBalancer, error) {
+	var result []loadbalancers.LoadBalancer
+	for _, lb := range inv.LoadBalancers {
+		ok, err := c.match(inv, lb)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, lb)
+		}
+	}
+	return result, nil
+}
+
+// match tells whether a LoadBalancer of inv matches all criteria.
+func (c criteria) match(inv *inventory.Inventory, lb loadbalancers.LoadBalancer) (bool, error) {
+	if c.empty && (len(inv.ListenersOf(lb.ID)) > 0 || len(inv.PoolsOf(lb.ID)) > 0) {
+		return false, nil
+	}
+	if c.status != "" && lb.ProvisioningStatus != c.status && lb.OperatingStatus != c.status {
+		return false, nil
+	}
+	if c.namePattern != "" {
+		pattern, err := regexp.Compile(c.namePattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid --name-pattern: %s", err)
+		}
+		if !pattern.MatchString(lb.Name) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// encode returns the criteria as stored in a mark. The name pattern is base64
+// encoded, since the tags API does not allow every character.
+func (c criteria) encode() []string {
+	var result []string
+	if c.empty {
+		result = append(result, "empty")
+	}
+	if c.status != "" {
+		result = append(result, "status="+c.status)
+	}
+	if c.namePattern != "" {
+		result = append(result, "name="+base64.RawURLEncoding.EncodeToString([]byte(c.namePattern)))
+	}
+	return result
+}
+
+// decodeCriteria parses the criteria stored in a mark.
+func decodeCriteria(encoded []string) (criteria, error) {
+	var c criteria
+	for _, e := range encoded {
+		parts := strings.SplitN(e, "=", 2)
+		switch {
+		case e == "empty":
+			c.empty = true
+		case parts[0] == "status" && len(parts) == 2:
+			c.status = parts[1]
+		case parts[0] == "name" && len(parts) == 2:
+			pattern, err := base64.RawURLEncoding.DecodeString(parts[1])
+			if err != nil {
+				return criteria{}, fmt.Errorf("invalid name pattern %q in mark", parts[1])
+			}
+			c.namePattern = string(pattern)
+		default:
+			return criteria{}, fmt.Errorf("unknown criterion %q in mark", e)
+		}
+	}
+	return c, nil
+}
+
+// validate checks the criteria before they are used.
+func (c criteria) validate() error {
+	if strings.ContainsAny(c.status, " \t\n;,/=") {
+		return fmt.Errorf("invalid --status %q", c.status)
+	}
+	if _, err := regexp.Compile(c.namePattern); err != nil {
+		return fmt.Errorf("invalid --name-pattern: %s", err)
+	}
+	return nil
+}
@@ -15,37 +15,345 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
 
 	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/journal"
 	"github.com/spf13/cobra"
 )
 
 // deleteCmd represents the delete command
 func deleteCmd() *cobra.Command {
 	var noDryRun bool
+	var continueOnError bool
+	var journalPath string
+	var resume string
+	var fromSnapshot string
+	var noBackup bool
+	var backupDir string
+	var confirm string
+	var assumeYes bool
 	c := &cobra.Command{
-		Use:   "delete <LoadBalancerID>",
+		Use:   "delete <LoadBalancer>...",
 		Short: "Delete a LoadBalancer + everything attached",
-		Long:  `Delete a LoadBalancer + everything attached.`,
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			osClient, err := client.NewOpenStackProvider(client.Config{
+		Long: `Delete one or more LoadBalancers + everything attached.
+
+LoadBalancers are given by ID, a unique ID prefix or their exact name.
+
+LoadBalancers whose description contains "` + client.ProtectionMarker + `" are never deleted.
+A summary of deleted, skipped, failed and protected objects is printed at the end.
+
+Real runs write a checkpoint journal. If a run is interrupted, continue it with
+"oli delete --resume <journal> --no-dry-run". Objects recorded in the journal are
+skipped, all others are checked again before they are deleted.
+
+Before a LoadBalancer is deleted for real, a backup of it is written to
+--backup-dir as by "oli backup". If the backup fails, the LoadBalancer is not
+deleted. Pass --no-backup to skip it. Resumed runs take no backups.
+
+Real runs ask for confirmation first, depending on --confirm:
+  plan          once for the whole plan (default)
+  loadbalancer  before every LoadBalancer
+  name          by typing the name of every LoadBalancer
+Pass --yes to skip all questions. Without --yes, stdin must be a terminal.
+
+Real runs send a notice before deleting and a summary afterwards to the
+notifications configured in the oli config file.
+
+With --from-snapshot the dry run is planned offline from a snapshot written by
+"oli snapshot", without credentials.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if resume != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateConfirmLevel(confirm); err != nil {
+				return err
+			}
+			if noDryRun && !assumeYes && !isTerminal(os.Stdin) {
+				return fmt.Errorf("refusing to delete without confirmation, stdin is not a terminal: pass --yes to delete anyway")
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			if fromSnapshot != "" {
+				if noDryRun || resume != "" {
+					return fmt.Errorf("--from-snapshot only supports dry runs")
+				}
+				return planFromSnapshot(os.Stdout, fromSnapshot, args, continueOnError)
+			}
+			// dry runs are not announced
+			var notifier *runNotifier
+			if noDryRun {
+				var err error
+				if notifier, err = newRunNotifier("delete"); err != nil {
+					return err
+				}
+			}
+			osClient, err := newOpenStackProvider(client.Config{
 				DryRun: !noDryRun,
 			})
 			if err != nil {
-				panic(fmt.Errorf("failed to create os client %s", err))
+				return err
 			}
-			err = osClient.DeleteLoadBalancer(args[0])
-			if err != nil {
-				panic(fmt.Errorf("failed to delete loadbalancer %s", err))
+
+			var j *journal.Journal
+			var inv *inventory.Inventory
+			var plan *client.Plan
+			var reports []*client.DeleteReport
+			if resume != "" {
+				if j, err = journal.Open(resume); err != nil {
+					return err
+				}
+				defer j.Close()
+				plan = &j.Plan
+				fmt.Printf("resuming from journal %s, %d objects already deleted\n", j.Name(), len(j.Done))
+			} else {
+				if inv, err = inventory.Collect(ctx, osClient); err != nil {
+					return err
+				}
+				plan = &client.Plan{}
+				for _, ref := range args {
+					id, err := resolve(inv, ref, client.KindLoadBalancer)
+					var p *client.Plan
+					if err == nil {
+						p, err = inv.PlanDeletion([]string{id})
+					}
+					if err != nil {
+						reports = append(reports, &client.DeleteReport{
+							LoadBalancerID: ref,
+							DryRun:         !noDryRun,
+							Failed:         []client.Failure{{Object: client.Object{Kind: client.KindLoadBalancer, ID: ref}, Err: err}},
+						})
+						if !continueOnError {
+							printDeleteSummary(os.Stdout, reports)
+							return err
+						}
+						continue
+					}
+					plan.Entries = append(plan.Entries, p.Entries...)
+				}
+				if noDryRun {
+					if j, err = createJournal(journalPath, *plan); err != nil {
+						return err
+					}
+					defer j.Close()
+				}
+			}
+
+			run := deleteRun{dryRun: !noDryRun, continueOnError: continueOnError, journal: j}
+			if noDryRun && !noBackup && resume == "" {
+				run.backupDir = backupDir
 			}
+			if noDryRun && !assumeYes {
+				run.confirm = &confirmer{level: confirm, in: bufio.NewReader(os.Stdin), out: os.Stdout}
+			}
+			if notifier != nil {
+				notifier.inv = inv
+				run.notify = notifier
+			}
+			return run.execute(ctx, os.Stdout, osClient, plan, reports)
 		},
 	}
 	c.Flags().BoolVar(&noDryRun, "no-dry-run", false, "The real deal!")
+	c.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Continue with the next LoadBalancer if deleting one fails.")
+	c.Flags().StringVar(&journalPath, "journal", "", "Checkpoint journal to write (default is oli-delete-<timestamp>.journal).")
+	c.Flags().StringVar(&resume, "resume", "", "Resume the run recorded in the given journal.")
+	c.Flags().BoolVar(&noBackup, "no-backup", false, "Do not back up LoadBalancers before deleting them.")
+	c.Flags().StringVar(&backupDir, "backup-dir", ".", "Directory for the backups taken before deleting.")
+	c.Flags().StringVar(&confirm, "confirm", confirmPlan, "Ask for confirmation once per \"plan\", per \"loadbalancer\" or by typing the \"name\" of each.")
+	c.Flags().BoolVar(&assumeYes, "yes", false, "Delete without asking for confirmation.")
+	c.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Plan a dry run offline on a snapshot written by \"oli snapshot\".")
 	return c
 }
 
 func init() {
 	rootCmd.AddCommand(deleteCmd())
 }
+
+// deleteRun holds the settings of a deletion run.
+type deleteRun struct {
+	dryRun          bool
+	continueOnError bool
+	// backupDir is where LoadBalancers are backed up before they are
+	// deleted. No backups are taken if it is empty.
+	backupDir string
+	// journal records the deleted objects of real runs.
+	journal *journal.Journal
+	// confirm asks before deleting, nothing is asked if it is nil.
+	confirm *confirmer
+	// notify announces the deletion and reports the outcome, nothing is
+	// sent if it is nil.
+	notify *runNotifier
+}
+
+// createJournal creates the checkpoint journal of a real run, named after the
+// current time if path is empty.
+func createJournal(path string, plan client.Plan) (*journal.Journal, error) {
+	if path == "" {
+		path = fmt.Sprintf("oli-delete-%s.journal", time.Now().Format("20060102-150405"))
+	}
+	j, err := journal.Create(path, plan)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("writing checkpoint journal to %s\n", j.Name())
+	return j, nil
+}
+
+// execute deletes the entries of a plan and prints the summary. reports holds
+// the LoadBalancers which could not be planned with --continue-on-error.
+func (r deleteRun) execute(ctx context.Context, out io.Writer, osClient client.OpenStackProvider, plan *client.Plan, reports []*client.DeleteReport) error {
+	_, err := r.deletePlan(ctx, out, osClient, plan, reports)
+	return err
+}
+
+// deletePlan is execute, returning the reports of all LoadBalancers. No
+// reports are returned if the plan was not confirmed.
+func (r deleteRun) deletePlan(ctx context.Context, out io.Writer, osClient client.OpenStackProvider, plan *client.Plan, reports []*client.DeleteReport) ([]*client.DeleteReport, error) {
+	if r.backupDir != "" {
+		if err := os.MkdirAll(r.backupDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create backup directory: %s", err)
+		}
+	}
+	if r.confirm != nil && !r.confirm.confirmPlan(plan) {
+		fmt.Fprintln(out, "aborted, nothing was deleted")
+		return nil, nil
+	}
+	if r.notify != nil {
+		r.notify.notice(plan, r.dryRun)
+	}
+	opts := client.DeleteOptions{}
+	if r.journal != nil {
+		opts.Done = r.journal.Done
+		opts.OnDeleted = r.journal.Record
+	}
+	var firstErr error
+	if len(reports) > 0 {
+		firstErr = reports[0].Failed[0].Err
+	}
+	for i, entry := range plan.Entries {
+		if r.confirm != nil && !entry.Protected && !r.confirm.confirmEntry(entry) {
+			fmt.Fprintf(out, "skipping loadbalancer %s\n", entry.LoadBalancerID)
+			reports = append(reports, &client.DeleteReport{LoadBalancerID: entry.LoadBalancerID, Skipped: entry.Steps})
+			continue
+		}
+		if r.backupDir != "" && !entry.Protected {
+			if err := backupLoadBalancer(ctx, osClient, entry.LoadBalancerID, backupPath(r.backupDir, entry.LoadBalancerID)); err != nil {
+				reports = append(reports, &client.DeleteReport{
+					LoadBalancerID: entry.LoadBalancerID,
+					Failed:         []client.Failure{{Object: client.Object{Kind: client.KindLoadBalancer, ID: entry.LoadBalancerID}, Err: err}},
+				})
+				if firstErr == nil {
+					firstErr = err
+				}
+				if ctx.Err() != nil || !r.continueOnError {
+					break
+				}
+				continue
+			}
+		}
+		report, err := osClient.DeletePlanEntry(ctx, entry, opts)
+		reports = append(reports, report)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if report.Interrupted {
+			fmt.Fprintf(out, "\nrun interrupted, %d loadbalancers were not started\n", len(plan.Entries)-i-1)
+			if r.journal != nil {
+				fmt.Fprintf(out, "continue with: oli delete --resume %s --no-dry-run\n", r.journal.Name())
+			}
+			break
+		}
+		if err != nil && !r.continueOnError {
+			break
+		}
+	}
+	printDeleteSummary(out, reports)
+	if r.notify != nil {
+		r.notify.summary(plan, reports, r.dryRun)
+	}
+	return reports, firstErr
+}
+
+// planFromSnapshot runs a dry run of deleting the given LoadBalancers against
+// a snapshot.
+func planFromSnapshot(out io.Writer, path string, ids []string, continueOnError bool) error {
+	inv, err := inventory.Load(path)
+	if err != nil {
+		return err
+	}
+	var reports []*client.DeleteReport
+	var firstErr error
+	for _, ref := range ids {
+		report := &client.DeleteReport{LoadBalancerID: ref, DryRun: true}
+		reports = append(reports, report)
+		id, err := resolve(inv, ref, client.KindLoadBalancer)
+		var plan *client.Plan
+		if err == nil {
+			report.LoadBalancerID = id
+			plan, err = inv.PlanDeletion([]string{id})
+		}
+		if err != nil {
+			report.Failed = []client.Failure{{Object: client.Object{Kind: client.KindLoadBalancer, ID: ref}, Err: err}}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if !continueOnError {
+				break
+			}
+			continue
+		}
+		entry := plan.Entries[0]
+		if entry.Protected {
+			fmt.Fprintf(out, "loadbalancer %s is protected by %q, skipping\n", id, client.ProtectionMarker)
+			report.Protected = entry.Steps
+			continue
+		}
+		for _, obj := range entry.Steps {
+			fmt.Fprintf(out, "Dry run: delete %s with id %s\n", obj.Kind, obj.ID)
+		}
+		report.Deleted = entry.Steps
+	}
+	printDeleteSummary(out, reports)
+	return firstErr
+}
+
+// printDeleteSummary prints a table with the outcome per LoadBalancer followed
+// by the details of every failure.
+func printDeleteSummary(out io.Writer, reports []*client.DeleteReport) {
+	if len(reports) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	if reports[0].DryRun {
+		fmt.Fprintln(out, "Summary (dry run, nothing was deleted):")
+	} else {
+		fmt.Fprintln(out, "Summary:")
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LOADBALANCER\tDELETED\tSKIPPED\tFAILED\tPROTECTED")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", r.LoadBalancerID, len(r.Deleted), len(r.Skipped), len(r.Failed), len(r.Protected))
+	}
+	w.Flush()
+	for _, r := range reports {
+		if r.Interrupted {
+			fmt.Fprintf(out, "\nloadbalancer %s: interrupted before all objects were deleted\n", r.LoadBalancerID)
+		}
+		for _, f := range r.Failed {
+			fmt.Fprintf(out, "\nfailed to delete %s %s (loadbalancer %s): %s\n", f.Kind, f.ID, r.LoadBalancerID, f.Err)
+			if f.Body != "" {
+				fmt.Fprintf(out, "  API response: %s\n", f.Body)
+			}
+		}
+	}
+}
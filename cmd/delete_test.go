@@ -0,0 +1,190 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/journal"
+)
+
+func TestDelete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-delete")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := fakecloud.NewServer()
+	defer s.Close()
+	// the command polls every 2 seconds
+	s.PendingTicks = 0
+	add := func(name, description string) loadbalancers.LoadBalancer {
+		lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: name, Description: description})
+		s.AddListener(lb.ID, listeners.Listener{Name: name + "-http", Protocol: "HTTP", ProtocolPort: 80})
+		return lb
+	}
+	web := add("web", "")
+	keep := add("keep", "database, "+client.ProtectionMarker)
+	broken := add("broken", "")
+	last := add("last", "")
+	other := add("other", "")
+	setFakeEnv(s)
+
+	journals := 0
+	deleteLBs := func(args ...string) error {
+		journals++
+		c := deleteCmd()
+		c.SetArgs(append(args, "--yes", "--no-backup", "--journal", filepath.Join(dir, strings.Repeat("j", journals))))
+		return c.Execute()
+	}
+
+	// dry runs only read
+	before := len(s.Requests())
+	if err := deleteLBs(web.ID, keep.ID); err != nil {
+		t.Fatalf("dry run failed: %s", err)
+	}
+	for _, req := range s.Requests()[before:] {
+		if !strings.HasPrefix(req, "GET ") && !strings.HasPrefix(req, "POST /v3/auth/tokens") {
+			t.Errorf("dry run sent %s", req)
+		}
+	}
+	if !s.Exists(web.ID) {
+		t.Fatalf("dry run deleted loadbalancer %s", web.ID)
+	}
+
+	// protected LoadBalancers are skipped by real runs
+	if err := deleteLBs(web.ID, keep.ID, "--no-dry-run"); err != nil {
+		t.Fatalf("delete failed: %s", err)
+	}
+	if s.Exists(web.ID) {
+		t.Errorf("loadbalancer %s was not deleted", web.ID)
+	}
+	if !s.Exists(keep.ID) || s.Count(fakecloud.Listeners) != 4 {
+		t.Errorf("protected loadbalancer %s or its listener was deleted", keep.ID)
+	}
+
+	// a failure stops the run
+	s.InjectError(http.MethodDelete, "/listeners/", http.StatusConflict, `{"faultcode": "Client", "faultstring": "Listener is in use"}`)
+	if err := deleteLBs(broken.ID, last.ID, "--no-dry-run"); err == nil {
+		t.Errorf("delete with a failure succeeded")
+	}
+	if !s.Exists(broken.ID) || !s.Exists(last.ID) {
+		t.Errorf("run went on after a failure")
+	}
+
+	// unless --continue-on-error is given
+	s.InjectError(http.MethodDelete, "/listeners/", http.StatusConflict, `{"faultcode": "Client", "faultstring": "Listener is in use"}`)
+	if err := deleteLBs(broken.ID, last.ID, other.ID, "--no-dry-run", "--continue-on-error"); err == nil {
+		t.Errorf("delete with a failure succeeded")
+	}
+	if !s.Exists(broken.ID) {
+		t.Errorf("loadbalancer %s was deleted despite the failure", broken.ID)
+	}
+	if s.Exists(last.ID) || s.Exists(other.ID) {
+		t.Errorf("run did not continue after a failure")
+	}
+}
+
+// roundTripperFunc turns a function into an http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDeleteInterrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-delete")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := fakecloud.NewServer()
+	defer s.Close()
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+	s.AddListener(lb.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+	s.AddListener(lb.ID, listeners.Listener{Name: "https", Protocol: "HTTPS", ProtocolPort: 443})
+
+	// SIGINT arrives while the second listener is being deleted
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	deletes := 0
+	opts := s.AuthOptions()
+	osClient, err := client.NewOpenStackProvider(client.Config{
+		AuthOptions:  &opts,
+		PollInterval: time.Millisecond,
+		WrapTransport: func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := next.RoundTrip(req)
+				if req.Method == http.MethodDelete {
+					if deletes++; deletes == 2 {
+						cancel()
+					}
+				}
+				return resp, err
+			})
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := osClient.PlanLoadBalancerDeletion(ctx, lb.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := journal.Create(filepath.Join(dir, "delete.journal"), client.Plan{Entries: []client.PlanEntry{plan}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		run := deleteRun{journal: j}
+		done <- run.execute(ctx, ioutil.Discard, osClient, &client.Plan{Entries: []client.PlanEntry{plan}}, nil)
+	}()
+	select {
+	case err = <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatalf("delete did not stop waiting after the interruption")
+	}
+	if got := exitCode(err); got != exitInterrupted {
+		t.Errorf("got exit code %d for %v, want %d", got, err, exitInterrupted)
+	}
+	if deletes != 2 || !s.Exists(lb.ID) {
+		t.Errorf("a step was started after the interruption")
+	}
+
+	resumed, err := journal.Open(j.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+	first := plan.Steps[0].ID
+	if len(resumed.Done) != 1 || !resumed.Done[first] {
+		t.Errorf("journal recorded %v, want only %s", resumed.Done, first)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// describeCmd represents the describe command
+func describeCmd() *cobra.Command {
+	var fromSnapshot string
+	c := &cobra.Command{
+		Use:   "describe <ID|name>",
+		Short: "Show all fields, parents and children of any LBaaS object",
+		Long: `Show all fields of a LoadBalancer, Listener, Pool, Member, Health Monitor or
+L7 Policy, together with its parents and children. The object is given by ID, a
+unique ID prefix or its exact name.
+
+The live provisioning and operating status is taken from the status tree of the
+LoadBalancer the object belongs to. LoadBalancers also show their traffic
+statistics. Neither is available with --from-snapshot.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signalContext()
+			defer stop()
+			var inv *inventory.Inventory
+			var osClient client.OpenStackProvider
+			var err error
+			if fromSnapshot != "" {
+				if inv, err = collectInventory(ctx, fromSnapshot); err != nil {
+					return err
+				}
+			} else {
+				if osClient, err = newOpenStackProvider(client.Config{}); err != nil {
+					return err
+				}
+				if inv, err = inventory.Collect(ctx, osClient); err != nil {
+					return err
+				}
+			}
+			id, err := resolve(inv, args[0])
+			if err != nil {
+				return err
+			}
+			d, err := inv.Describe(id)
+			if err != nil {
+				return err
+			}
+
+			var statuses []*loadbalancers.StatusTree
+			var stats *loadbalancers.Stats
+			if osClient != nil {
+				for _, lbID := range d.LoadBalancerIDs {
+					tree, err := osClient.GetLoadBalancerStatuses(ctx, lbID)
+					if err != nil {
+						return err
+					}
+					statuses = append(statuses, tree)
+				}
+				if d.Kind == client.KindLoadBalancer {
+					if stats, err = osClient.GetLoadBalancerStats(ctx, d.ID); err != nil {
+						return err
+					}
+				}
+			}
+			printDescription(os.Stdout, d, statuses, stats)
+			return nil
+		},
+	}
+	c.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Work offline on a snapshot written by \"oli snapshot\".")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd())
+}
+
+// printDescription prints an object with its relations, the live status from
+// the status trees of its LoadBalancers and, if given, the stats.
+func printDescription(out io.Writer, d *inventory.Description, statuses []*loadbalancers.StatusTree, stats *loadbalancers.Stats) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Kind:\t%s\n", d.Kind)
+	fmt.Fprintf(w, "ID:\t%s\n", d.ID)
+	fmt.Fprintf(w, "Name:\t%s\n", d.Name)
+	fmt.Fprintln(w, "\nFields:")
+	for _, p := range d.Properties {
+		fmt.Fprintf(w, "  %s\t%s\n", p.Name, p.Value)
+	}
+	printReferences := func(title string, refs []inventory.Reference) {
+		fmt.Fprintf(w, "\n%s:\n", title)
+		if len(refs) == 0 {
+			fmt.Fprintln(w, "  <none>")
+		}
+		for _, r := range refs {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", r.Kind, r.ID, r.Name)
+		}
+	}
+	printReferences("Parents", d.Parents)
+	printReferences("Children", d.Children)
+	if len(statuses) > 0 {
+		fmt.Fprintln(w, "\nLive status:")
+		for _, tree := range statuses {
+			if tree == nil || tree.Loadbalancer == nil {
+				continue
+			}
+			provisioning, operating, ok := findStatus(tree, d.ID)
+			if !ok {
+				fmt.Fprintf(w, "  loadbalancer %s\tnot in status tree\n", tree.Loadbalancer.ID)
+				continue
+			}
+			status := "provisioning " + provisioning
+			if operating != "" {
+				status += ", operating " + operating
+			}
+			fmt.Fprintf(w, "  loadbalancer %s\t%s\n", tree.Loadbalancer.ID, status)
+		}
+	}
+	if stats != nil {
+		fmt.Fprintln(w, "\nStats:")
+		fmt.Fprintf(w, "  active_connections\t%d\n", stats.ActiveConnections)
+		fmt.Fprintf(w, "  total_connections\t%d\n", stats.TotalConnections)
+		fmt.Fprintf(w, "  bytes_in\t%d\n", stats.BytesIn)
+		fmt.Fprintf(w, "  bytes_out\t%d\n", stats.BytesOut)
+		fmt.Fprintf(w, "  request_errors\t%d\n", stats.RequestErrors)
+	}
+	w.Flush()
+}
+
+// findStatus looks up the provisioning and operating status of an object in
+// the status tree of a LoadBalancer.
+func findStatus(tree *loadbalancers.StatusTree, id string) (string, string, bool) {
+	lb := tree.Loadbalancer
+	if lb.ID == id {
+		return lb.ProvisioningStatus, lb.OperatingStatus, true
+	}
+	for _, l := range lb.Listeners {
+		if l.ID == id {
+			return l.ProvisioningStatus, "", true
+		}
+		for _, p := range l.L7Policies {
+			if p.ID == id {
+				return p.ProvisioningStatus, p.OperatingStatus, true
+			}
+		}
+		for _, p := range l.Pools {
+			if p.ID == id {
+				return p.ProvisioningStatus, p.OperatingStatus, true
+			}
+			if p.Monitor.ID == id {
+				return p.Monitor.ProvisioningStatus, "", true
+			}
+			for _, m := range p.Members {
+				if m.ID == id {
+					return m.ProvisioningStatus, m.OperatingStatus, true
+				}
+			}
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,66 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+func TestDescribeLoadBalancer(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	seedListTree(s)
+	osClient := newFakeProvider(t, s)
+	ctx := context.Background()
+	lbs, err := osClient.ListLBaaS(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lb := lbs[0]
+	s.SetStats(lb.ID, loadbalancers.Stats{ActiveConnections: 3, TotalConnections: 42})
+
+	inv, err := inventory.Collect(ctx, osClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := inv.Describe(lb.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := osClient.GetLoadBalancerStatuses(ctx, lb.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := osClient.GetLoadBalancerStats(ctx, lb.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	printDescription(&out, d, []*loadbalancers.StatusTree{tree}, stats)
+
+	for _, want := range []string{"Name:  web", "vip_address", lb.VipAddress, "listener", "http", "provisioning ACTIVE, operating ONLINE", "active_connections  3", "total_connections   42"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
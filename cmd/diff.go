@@ -0,0 +1,122 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/renderer"
+)
+
+// diffCmd represents the diff command
+func diffCmd() *cobra.Command {
+	var live bool
+	var output string
+	c := &cobra.Command{
+		Use:   "diff <old-snapshot> [<new-snapshot>|--live]",
+		Short: "Show what changed between two snapshots or a snapshot and the live tenant",
+		Long: `Show what changed between two snapshots or a snapshot and the live tenant.
+
+Added, removed and changed LoadBalancers, Listeners, L7 Policies, Pools, Members
+and Health Monitors are listed. For changed objects every changed field is shown,
+e.g. status flips, admin state, member weights and pool membership.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if live {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", output)
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			old, err := inventory.Load(args[0])
+			if err != nil {
+				return err
+			}
+			newLabel := "live"
+			var current *inventory.Inventory
+			if live {
+				current, err = collectInventory(ctx, "")
+			} else {
+				newLabel = args[1]
+				current, err = inventory.Load(args[1])
+			}
+			if err != nil {
+				return err
+			}
+			d := inventory.Compare(old, current)
+			if output == "json" {
+				data, err := json.MarshalIndent(d, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode diff: %s", err)
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			printDiff(os.Stdout, d, args[0], newLabel, useColor(os.Stdout))
+			return nil
+		},
+	}
+	c.Flags().BoolVar(&live, "live", false, "Compare the snapshot with the live tenant.")
+	c.Flags().StringVarP(&output, "output", "o", "text", "Output format, one of text or json.")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd())
+}
+
+// printDiff prints the changes of d, one object per line followed by its
+// changed fields.
+func printDiff(out io.Writer, d *inventory.Diff, oldLabel, newLabel string, color bool) {
+	fmt.Fprintf(out, "--- %s (%s)\n", oldLabel, d.OldCollectedAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(out, "+++ %s (%s)\n", newLabel, d.NewCollectedAt.Local().Format(time.RFC3339))
+	for _, c := range d.Changes {
+		name := ""
+		if c.Name != "" {
+			name = " " + c.Name
+		}
+		switch c.Type {
+		case inventory.Added:
+			fmt.Fprintln(out, renderer.Colorize(color, renderer.ColorGreen, fmt.Sprintf("+ %s%s (%s)", c.Kind, name, c.ID)))
+		case inventory.Removed:
+			fmt.Fprintln(out, renderer.Colorize(color, renderer.ColorRed, fmt.Sprintf("- %s%s (%s)", c.Kind, name, c.ID)))
+		case inventory.Changed:
+			fmt.Fprintln(out, renderer.Colorize(color, renderer.ColorYellow, fmt.Sprintf("~ %s%s (%s)", c.Kind, name, c.ID)))
+			for _, f := range c.Fields {
+				fmt.Fprintf(out, "    %s: %s -> %s\n", f.Field, quote(f.Old), quote(f.New))
+			}
+		}
+	}
+	fmt.Fprintf(out, "%d added, %d removed, %d changed\n", d.Count(inventory.Added), d.Count(inventory.Removed), d.Count(inventory.Changed))
+}
+
+// quote makes empty values visible.
+func quote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	return s
+}
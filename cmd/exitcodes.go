@@ -0,0 +1,83 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// Exit codes returned by oli. They are part of the CLI contract, so only ever
+// add new ones.
+const (
+	exitOK               = 0
+	exitError            = 1
+	exitAuth             = 2
+	exitEndpointNotFound = 3
+	exitNotFound         = 4
+	exitConflict         = 5
+	exitPermissionDenied = 6
+	exitPartialDelete    = 7
+	exitInterrupted      = 130
+)
+
+const exitCodesHelp = `
+Exit Codes:
+  0    success
+  1    unspecified error (e.g. invalid arguments)
+  2    authentication failed or credentials missing
+  3    no matching endpoint in the service catalog
+  4    object not found
+  5    object is in a conflicting or immutable state
+  6    permission denied
+  7    delete failed after some objects were already removed
+  130  interrupted by SIGINT or SIGTERM`
+
+// exitCode maps an error returned by a command to the exit code of oli. The
+// error may wrap the typed errors of the client. An interruption wins over
+// everything else and a partial delete over its cause.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var (
+		interrupted      *client.InterruptedError
+		partialDelete    *client.PartialDeleteError
+		auth             *client.AuthError
+		endpointNotFound *client.EndpointNotFoundError
+		notFound         *client.NotFoundError
+		conflict         *client.ConflictError
+		permissionDenied *client.PermissionDeniedError
+	)
+	switch {
+	case errors.As(err, &interrupted):
+		return exitInterrupted
+	case errors.As(err, &partialDelete):
+		return exitPartialDelete
+	case errors.As(err, &auth):
+		return exitAuth
+	case errors.As(err, &endpointNotFound):
+		return exitEndpointNotFound
+	case errors.As(err, &notFound):
+		return exitNotFound
+	case errors.As(err, &conflict):
+		return exitConflict
+	case errors.As(err, &permissionDenied):
+		return exitPermissionDenied
+	default:
+		return exitError
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+func TestExitCode(t *testing.T) {
+	var auth, interrupted error = &client.AuthError{}, &client.InterruptedError{}
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{errors.New("invalid argument"), exitError},
+		{&client.AuthError{}, exitAuth},
+		{&client.EndpointNotFoundError{}, exitEndpointNotFound},
+		{client.NewNotFoundError("loadbalancer web not found"), exitNotFound},
+		{&client.ConflictError{}, exitConflict},
+		{&client.PermissionDeniedError{}, exitPermissionDenied},
+		{&client.PartialDeleteError{Deleted: []string{"listener-1"}}, exitPartialDelete},
+		{&client.InterruptedError{}, exitInterrupted},
+		// typed errors keep their exit code when commands wrap them
+		{fmt.Errorf("region RegionOne: %w", auth), exitAuth},
+		{fmt.Errorf("failed to back up loadbalancer lb-1: %w", client.NewNotFoundError("not found")), exitNotFound},
+		{fmt.Errorf("region RegionOne: %w", fmt.Errorf("run: %w", interrupted)), exitInterrupted},
+		// but not when they are formatted into the message
+		{fmt.Errorf("region RegionOne: %s", auth), exitError},
+	} {
+		if got := exitCode(tc.err); got != tc.want {
+			t.Errorf("%#v: got exit code %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// findCmd represents the find command
+func findCmd() *cobra.Command {
+	var regions []string
+	var allProjects bool
+	var fromSnapshot string
+	c := &cobra.Command{
+		Use:   "find <ip|cidr|subnet-id|port>",
+		Short: "Find the LBaaS objects using an IP, port or subnet",
+		Long: `Find the LBaaS objects using an IP address, a CIDR, a subnet ID or a port.
+
+VIP addresses, member addresses, listener and member ports and the subnets of
+VIPs, pools and members are searched. Every match is printed with its full path,
+e.g. LoadBalancer -> Listener -> Pool -> Member.
+
+--regions searches several regions, --all-projects the objects of all projects,
+which usually needs admin rights.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := strings.TrimSpace(args[0])
+			if query == "" {
+				return fmt.Errorf("the search query must not be empty")
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			if fromSnapshot != "" {
+				if len(regions) > 0 || allProjects {
+					return fmt.Errorf("--regions and --all-projects cannot be used with --from-snapshot")
+				}
+				inv, err := collectInventory(ctx, fromSnapshot)
+				if err != nil {
+					return err
+				}
+				printMatches(os.Stdout, []regionMatches{{inv.Metadata.Region, inv.Find(query)}})
+				return nil
+			}
+			if len(regions) == 0 {
+				regions = []string{os.Getenv("OS_REGION_NAME")}
+			}
+			if len(regions) > 1 && (recordDir != "" || replayDir != "") {
+				return fmt.Errorf("--record and --replay cannot be used with several regions")
+			}
+			var results []regionMatches
+			for _, region := range regions {
+				osClient, err := newOpenStackProvider(client.Config{Region: region, AllProjects: allProjects})
+				if err != nil {
+					return fmt.Errorf("region %s: %w", region, err)
+				}
+				inv, err := inventory.Collect(ctx, osClient)
+				if err != nil {
+					return fmt.Errorf("region %s: %w", region, err)
+				}
+				results = append(results, regionMatches{region, inv.Find(query)})
+			}
+			printMatches(os.Stdout, results)
+			return nil
+		},
+	}
+	c.Flags().StringSliceVar(&regions, "regions", nil, "Regions to search (default is OS_REGION_NAME).")
+	c.Flags().BoolVar(&allProjects, "all-projects", false, "Search the objects of all projects.")
+	c.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Work offline on a snapshot written by \"oli snapshot\".")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(findCmd())
+}
+
+type regionMatches struct {
+	region  string
+	matches []inventory.Match
+}
+
+// printMatches prints a table with the matches of all regions.
+func printMatches(out io.Writer, results []regionMatches) {
+	n := 0
+	for _, r := range results {
+		n += len(r.matches)
+	}
+	if n == 0 {
+		fmt.Fprintln(out, "no matches")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REGION\tPROJECT\tMATCH\tPATH")
+	for _, r := range results {
+		for _, m := range r.matches {
+			fmt.Fprintf(w, "%s\t%s\t%s=%s\t%s\n", r.region, m.ProjectID, m.Field, m.Value, formatPath(m.Path))
+		}
+	}
+	w.Flush()
+}
+
+// formatPath renders a path like "loadbalancer web (id) -> listener http (id)".
+func formatPath(path []inventory.Reference) string {
+	var parts []string
+	for _, r := range path {
+		if r.Name != "" {
+			parts = append(parts, fmt.Sprintf("%s %s (%s)", r.Kind, r.Name, r.ID))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %s", r.Kind, r.ID))
+		}
+	}
+	return strings.Join(parts, " -> ")
+}
@@ -0,0 +1,235 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/janitor"
+	"github.com/afritzler/oli/pkg/notify"
+)
+
+// janitorCmd represents the janitor command
+func janitorCmd() *cobra.Command {
+	var policyFile string
+	var once bool
+	c := &cobra.Command{
+		Use:   "janitor --config <policy file>",
+		Short: "Clean up LoadBalancers by policy on a cron schedule",
+		Long: `Run until interrupted, cleaning up LoadBalancers according to the policies
+in the policy file on a cron schedule. With --once, run a single time right away.
+
+A LoadBalancer matched by a policy is marked first. It is deleted together with
+everything attached on the first run after it stayed marked for grace_period.
+If it is no longer matched in between, the mark is removed. Marks are kept like
+those of "oli mark", which are left to "oli sweep". LoadBalancers whose
+description contains "` + client.ProtectionMarker + `" are never marked.
+
+  schedule: "0 * * * *"       # minute hour day-of-month month day-of-week
+  state: oli-janitor.state    # observations in between runs
+  grace_period: 24h
+  dry_run: true               # set to false to delete for real
+  backup_dir: backups         # back up before deleting, optional
+  policies:
+  - type: empty               # without listeners and pools
+    for: 12h
+  - type: error               # provisioning or operating status ERROR
+    for: 6h
+    name_pattern: ^test-      # only LoadBalancers with a matching name
+  - type: kubernetes-orphaned # the Kubernetes service is gone
+    cluster: prod
+    command: [kubectl, --context, prod, get, services, --all-namespaces, -o, json]
+  - type: expired             # description contains ` + janitor.ExpiresMarker + `<date or RFC 3339>
+  - type: idle                # no active and no new connections
+    for: 168h
+
+A condition has to hold for the time given by "for" on every run to match.
+Kubernetes-orphaned LoadBalancers are recognized by the names and descriptions
+the OpenStack cloud provider gives them. Real runs write a checkpoint journal
+next to the state file. Marked and deleted LoadBalancers are announced to the
+notifications configured in the oli config file.
+
+The --config flag of this command names the policy file, the oli config file
+is then read from $HOME/.oli.yaml.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if policyFile == "" {
+				return fmt.Errorf("no policy file, pass --config")
+			}
+			config, err := janitor.LoadConfig(policyFile)
+			if err != nil {
+				return err
+			}
+			notifier, err := newRunNotifier("janitor")
+			if err != nil {
+				return err
+			}
+			osClient, err := newOpenStackProvider(client.Config{DryRun: config.DryRun})
+			if err != nil {
+				return err
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			if once {
+				return janitorRun(ctx, os.Stdout, osClient, config, notifier)
+			}
+			for {
+				next := config.Next(time.Now())
+				if next.IsZero() {
+					return fmt.Errorf("schedule %q never runs", config.Schedule)
+				}
+				fmt.Printf("next run at %s\n", next.Format(time.RFC3339))
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil
+				case <-timer.C:
+				}
+				if err := janitorRun(ctx, os.Stdout, osClient, config, notifier); err != nil && ctx.Err() == nil {
+					fmt.Fprintf(os.Stderr, "%s janitor run failed: %s\n", time.Now().Format(time.RFC3339), err)
+				}
+			}
+		},
+	}
+	c.Flags().StringVar(&policyFile, "config", "", "Policy file of the janitor.")
+	c.Flags().BoolVar(&once, "once", false, "Run once right away instead of on the schedule.")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(janitorCmd())
+}
+
+// janitorRun evaluates the policies, marks new candidates and deletes the
+// ones which are due. New candidates are announced with notifier unless it
+// is nil.
+func janitorRun(ctx context.Context, out io.Writer, osClient client.OpenStackProvider, config *janitor.Config, notifier *runNotifier) error {
+	state, err := janitor.LoadState(config.State)
+	if err != nil {
+		return err
+	}
+	inv, err := inventory.Collect(ctx, osClient)
+	if err != nil {
+		return err
+	}
+	var stats map[string]*loadbalancers.Stats
+	if config.NeedsStats() {
+		stats = map[string]*loadbalancers.Stats{}
+		for _, lb := range inv.LoadBalancers {
+			s, err := osClient.GetLoadBalancerStats(ctx, lb.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to get statistics of loadbalancer %s: %s\n", lb.ID, err)
+				continue
+			}
+			stats[lb.ID] = s
+		}
+	}
+	result, err := janitor.New(config, state, osClient).Run(ctx, inv, stats, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := state.Save(config.State); err != nil {
+		return err
+	}
+	printJanitorResult(out, result, config)
+	if notifier != nil {
+		notifier.inv = inv
+		if len(result.Marked) > 0 {
+			notifier.send(markedNotice(result, config))
+		}
+	}
+	if len(result.Due) == 0 {
+		return nil
+	}
+
+	plan, err := inv.PlanDeletion(result.DueIDs())
+	if err != nil {
+		return err
+	}
+	run := deleteRun{dryRun: config.DryRun, continueOnError: true, notify: notifier}
+	if !config.DryRun {
+		run.backupDir = config.BackupDir
+		path := filepath.Join(filepath.Dir(config.State), fmt.Sprintf("oli-janitor-%s.journal", time.Now().Format("20060102-150405")))
+		if run.journal, err = createJournal(path, *plan); err != nil {
+			return err
+		}
+		defer run.journal.Close()
+	}
+	fmt.Fprintln(out)
+	return run.execute(ctx, out, osClient, plan, nil)
+}
+
+// printJanitorResult prints a table of all candidates of a run and the
+// LoadBalancers whose mark was removed.
+func printJanitorResult(out io.Writer, result *janitor.Result, config *janitor.Config) {
+	mode := ""
+	if config.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Fprintf(out, "%s janitor run%s: %d marked, %d in grace period, %d due, %d protected\n",
+		result.Time.Format(time.RFC3339), mode, len(result.Marked), len(result.Pending), len(result.Due), len(result.Protected))
+	if len(result.Marked)+len(result.Pending)+len(result.Due)+len(result.Protected) > 0 {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LOADBALANCER\tNAME\tPOLICIES\tSTATE")
+		row := func(c janitor.Candidate, state string) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.LoadBalancerID, c.Name, strings.Join(c.Policies, ","), state)
+		}
+		for _, c := range result.Marked {
+			row(c, "marked, due "+c.MarkedAt.Add(config.GracePeriod).Format(time.RFC3339))
+		}
+		for _, c := range result.Pending {
+			row(c, "due "+c.MarkedAt.Add(config.GracePeriod).Format(time.RFC3339))
+		}
+		for _, c := range result.Due {
+			row(c, "due now")
+		}
+		for _, c := range result.Protected {
+			row(c, "protected")
+		}
+		w.Flush()
+	}
+	for _, id := range result.Unmarked {
+		fmt.Fprintf(out, "loadbalancer %s is no longer matched, mark removed\n", id)
+	}
+}
+
+// markedNotice announces the LoadBalancers marked by a run and when they are
+// going to be deleted.
+func markedNotice(result *janitor.Result, config *janitor.Config) notify.Event {
+	e := notify.Event{Kind: notify.KindNotice, DryRun: config.DryRun}
+	for _, c := range result.Marked {
+		e.Candidates = append(e.Candidates, notify.Candidate{
+			LoadBalancerID: c.LoadBalancerID,
+			Name:           c.Name,
+			Owner:          notify.Owner(c.Name, c.Description),
+			Reasons:        c.Policies,
+			DueAt:          c.MarkedAt.Add(config.GracePeriod),
+		})
+	}
+	return e
+}
@@ -0,0 +1,117 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/spf13/viper"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/notify"
+)
+
+func TestJanitorDeletesAfterGracePeriod(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-janitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := fakecloud.NewServer()
+	defer s.Close()
+	// the command polls every 2 seconds
+	s.PendingTicks = 0
+	empty := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "unused"})
+	protected := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "keep", Description: "oli:protected"})
+	used := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+	s.AddListener(used.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+	setFakeEnv(s)
+
+	var events []notify.Event
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notify.Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("failed to decode notification: %s", err)
+		}
+		events = append(events, e)
+	}))
+	defer hook.Close()
+	viper.Set("notifications", map[string]interface{}{"webhooks": []interface{}{map[string]interface{}{"url": hook.URL}}})
+	defer viper.Set("notifications", nil)
+
+	policy := filepath.Join(dir, "policy.yaml")
+	config := "schedule: '@hourly'\nstate: " + filepath.Join(dir, "state") + "\ngrace_period: 0s\ndry_run: false\npolicies: [{type: empty}]\n"
+	if err := ioutil.WriteFile(policy, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run := func() {
+		c := janitorCmd()
+		c.SetArgs([]string{"--config", policy, "--once"})
+		if err := c.Execute(); err != nil {
+			t.Fatalf("janitor failed: %s", err)
+		}
+	}
+
+	run()
+	if !s.Exists(empty.ID) {
+		t.Fatalf("loadbalancer %s was deleted by the run which marked it", empty.ID)
+	}
+	mark, err := newFakeProvider(t, s).GetLoadBalancerMark(context.Background(), empty.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mark == nil || !reflect.DeepEqual(mark.Criteria, []string{"janitor", "empty"}) {
+		t.Fatalf("got mark %+v of loadbalancer %s, want one by the empty policy", mark, empty.ID)
+	}
+	run()
+	if s.Exists(empty.ID) {
+		t.Errorf("loadbalancer %s was not deleted", empty.ID)
+	}
+	if !s.Exists(protected.ID) {
+		t.Errorf("protected loadbalancer %s was deleted", protected.ID)
+	}
+	if !s.Exists(used.ID) {
+		t.Errorf("loadbalancer %s with a listener was deleted", used.ID)
+	}
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+		if e.Source != "janitor" {
+			t.Errorf("got %s from %q", e.Kind, e.Source)
+		}
+	}
+	if want := []string{notify.KindNotice, notify.KindNotice, notify.KindSummary}; !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("got notifications %v, want %v", kinds, want)
+	}
+	if c := events[0].Candidates; len(c) != 1 || c[0].LoadBalancerID != empty.ID || c[0].DueAt.IsZero() {
+		t.Errorf("got candidates %+v in the notice of the marking run", c)
+	}
+	if r := events[2].Results; len(r) != 1 || r[0].LoadBalancerID != empty.ID || r[0].Deleted != 1 {
+		t.Errorf("got results %+v in the summary", r)
+	}
+	if journals, _ := filepath.Glob(filepath.Join(dir, "oli-janitor-*.journal")); len(journals) != 1 {
+		t.Errorf("got journals %v, want one", journals)
+	}
+}
@@ -15,25 +15,58 @@
 package cmd
 
 import (
-	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
 	"github.com/spf13/cobra"
 
-	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
 	"github.com/afritzler/oli/pkg/renderer"
 )
 
 // listCmd represents the list command
 func listCmd() *cobra.Command {
 	var listEmpty bool
+	var fromSnapshot string
+	var output string
 	c := &cobra.Command{
 		Use:   "list",
 		Short: "List everything LBaaS specific in your tenant",
-		Long:  `List everything LBaaS specific in your tenant.`,
-		Run: func(cmd *cobra.Command, args []string) {
-			listEverything(listEmpty)
+		Long: `List everything LBaaS specific in your tenant.
+
+By default the objects are printed as a tree. With -o dot or -o mermaid the
+topology is printed as a Graphviz or Mermaid diagram instead, with edges for
+pools shared by several listeners and the redirect pools of L7 policies.
+Orphans are highlighted and LoadBalancers and members are grouped by subnet.
+
+-o json prints the inventory in the format of a snapshot. The templated output
+formats work on the same model, like kubectl:
+  -o go-template='{{range .loadbalancers}}{{.name}}{{"\n"}}{{end}}'
+  -o jsonpath='{range .loadbalancers[?(@.provisioning_status=="ERROR")]}{.id}{"\n"}{end}'
+  -o custom-columns=NAME:.name,VIP:.vip_address,STATUS:.provisioning_status
+go-template-file=<file> and jsonpath-file=<file> read the template from a file.
+Custom columns have a row per LoadBalancer.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := renderer.New(output, renderer.Options{Color: useColor(os.Stdout)})
+			if err != nil {
+				return err
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			inv, err := collectInventory(ctx, fromSnapshot)
+			if err != nil {
+				return err
+			}
+			if listEmpty {
+				inv = emptyLoadBalancers(inv)
+			}
+			return r.Render(os.Stdout, inv)
 		},
 	}
 	c.Flags().BoolVar(&listEmpty, "empty", false, "Show only LoadBalancers with no Listeners and Pool.")
+	c.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Work offline on a snapshot written by \"oli snapshot\".")
+	c.Flags().StringVarP(&output, "output", "o", "tree", "Output format, one of "+strings.Join(renderer.Formats(), ", ")+".")
 	return c
 }
 
@@ -41,52 +74,14 @@ func init() {
 	rootCmd.AddCommand(listCmd())
 }
 
-func listEverything(listEmpty bool) {
-	r := renderer.NewTreeRenderer()
-
-	osClient, err := client.NewDefaultOpenStackProvider()
-	if err != nil {
-		panic(fmt.Errorf("failed to create os client %s", err))
-	}
-	lbs, err := osClient.ListLBaaS()
-	if err != nil {
-		panic(fmt.Errorf("failed to list lb ids %s", err))
-	}
-	for _, lb := range lbs {
-		if listEmpty && len(lb.Listeners) == 0 {
-			r.AddLoadBalancer(lb)
-		}
-	}
-
-	if !listEmpty {
-		listeners, err := osClient.ListListenersForCurrentTenant()
-		if err != nil {
-			panic(fmt.Errorf("failed to list listener %s", err))
-		}
-		for _, listener := range listeners {
-			r.AddListener(listener)
-		}
-		pools, err := osClient.GetPoolsForCurrentTenant()
-		if err != nil {
-			panic(fmt.Errorf("failed to list pools %s", err))
-		}
-		for _, pool := range pools {
-			r.AddPool(pool)
-			members, err := osClient.GetMembersForPoolID(pool.ID)
-			if err != nil {
-				panic(fmt.Errorf("failed to list members for pool %s, %s", pool.ID, err))
-			}
-			for _, member := range members {
-				r.AddMember(pool.ID, member)
-			}
-		}
-		monitors, err := osClient.ListMonitorsForCurrentTenant()
-		if err != nil {
-			panic(fmt.Errorf("failed to list healthmonitor ids %s", err))
-		}
-		for _, monitor := range monitors {
-			r.AddMonitor(monitor)
+// emptyLoadBalancers returns an inventory of only the LoadBalancers without
+// Listeners.
+func emptyLoadBalancers(inv *inventory.Inventory) *inventory.Inventory {
+	result := &inventory.Inventory{CollectedAt: inv.CollectedAt, Metadata: inv.Metadata, Members: map[string][]pools.Member{}}
+	for _, lb := range inv.LoadBalancers {
+		if len(lb.Listeners) == 0 {
+			result.LoadBalancers = append(result.LoadBalancers, lb)
 		}
 	}
-	fmt.Println(r.GetTreeStringWithLegend())
+	return result
 }
@@ -0,0 +1,92 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/renderer"
+)
+
+func newFakeProvider(t *testing.T, s *fakecloud.Server) client.OpenStackProvider {
+	opts := s.AuthOptions()
+	p, err := client.NewOpenStackProvider(client.Config{AuthOptions: &opts})
+	if err != nil {
+		t.Fatalf("failed to create provider: %s", err)
+	}
+	return p
+}
+
+// list renders the inventory collected by osClient like the list command.
+func list(t *testing.T, osClient client.OpenStackProvider, listEmpty bool) string {
+	inv, err := inventory.Collect(context.Background(), osClient)
+	if err != nil {
+		t.Fatalf("list failed: %s", err)
+	}
+	if listEmpty {
+		inv = emptyLoadBalancers(inv)
+	}
+	return renderer.RenderTree(inv, false)
+}
+
+func seedListTree(s *fakecloud.Server) {
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+	listener := s.AddListener(lb.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+	pool := s.AddPool(lb.ID, listener.ID, pools.Pool{Name: "backends", Protocol: "HTTP"})
+	s.AddMember(pool.ID, pools.Member{Name: "node-1", Address: "10.1.0.5", ProtocolPort: 8080})
+	s.AddMonitor(pool.ID, monitors.Monitor{Name: "ping", Type: "HTTP"})
+	s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "unused"})
+}
+
+func TestListEverything(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	s.PageSize = 1
+	seedListTree(s)
+
+	out := list(t, newFakeProvider(t, s), false)
+	for _, want := range []string{"[LB] web", "[L] http", "[P] backends", "[M] node-1", "[HM] ping", "[LB] unused"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Orphan") {
+		t.Errorf("output contains orphans:\n%s", out)
+	}
+}
+
+func TestListEverythingEmpty(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	seedListTree(s)
+
+	out := list(t, newFakeProvider(t, s), true)
+	if !strings.Contains(out, "[LB] unused") {
+		t.Errorf("output does not contain the empty loadbalancer:\n%s", out)
+	}
+	if strings.Contains(out, "[LB] web") || strings.Contains(out, "[L] http") {
+		t.Errorf("output contains a loadbalancer in use:\n%s", out)
+	}
+}
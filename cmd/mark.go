@@ -0,0 +1,152 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// markCmd represents the mark command
+func markCmd() *cobra.Command {
+	var selected criteria
+	var unmark bool
+	var octavia bool
+	var noDryRun bool
+	c := &cobra.Command{
+		Use:   "mark [<LoadBalancer>...]",
+		Short: "Mark LoadBalancers for deletion by \"oli sweep\"",
+		Long: `Mark LoadBalancers for deletion by "oli sweep", giving their owners time to
+object by removing the mark.
+
+The LoadBalancers are given by ID, a unique ID prefix or their exact name, or
+selected by --empty, --status and --name-pattern. If both are given, only the
+named LoadBalancers matching the criteria are marked.
+
+The mark is the tag "` + client.DeletionMarker + `=<time>;<criteria>". The criteria are
+checked again by "oli sweep". Where the API does not support tags, or the tag
+is too long for Neutron, the mark is appended to the description instead. Tags on LoadBalancers
+are supported by Octavia since API version 2.5, use --octavia to talk to it.
+LoadBalancers which are already marked keep their mark. LoadBalancers whose
+description contains "` + client.ProtectionMarker + `" are never marked.
+
+With --unmark the marks are removed again. Nothing is changed unless
+--no-dry-run is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && !selected.isSet() {
+				return fmt.Errorf("nothing to mark, pass LoadBalancers or criteria like --empty")
+			}
+			if err := selected.validate(); err != nil {
+				return err
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			osClient, err := newOpenStackProvider(client.Config{DryRun: !noDryRun, Octavia: octavia})
+			if err != nil {
+				return err
+			}
+			inv, err := inventory.Collect(ctx, osClient)
+			if err != nil {
+				return err
+			}
+			lbs, err := markCandidates(inv, selected, args)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now().UTC().Truncate(time.Second)
+			var firstErr error
+			marked := 0
+			for _, lb := range lbs {
+				if ctx.Err() != nil {
+					break
+				}
+				if unmark {
+					err = osClient.UnmarkLoadBalancer(ctx, lb.ID)
+				} else if client.IsProtected(lb.Description) {
+					fmt.Printf("loadbalancer %s is protected by %q, skipping\n", lb.ID, client.ProtectionMarker)
+					continue
+				} else {
+					var mark *client.Mark
+					mark, err = osClient.MarkLoadBalancer(ctx, lb.ID, now, selected.encode())
+					if err == nil && !mark.At.Equal(now) {
+						fmt.Printf("loadbalancer %s (%s) is already marked since %s\n", lb.ID, lb.Name, mark.At.Local().Format(time.RFC3339))
+					}
+				}
+				if err != nil {
+					fmt.Printf("%s\n", err)
+					if firstErr == nil {
+						firstErr = err
+					}
+					continue
+				}
+				marked++
+			}
+			switch {
+			case !noDryRun && unmark:
+				fmt.Printf("\nDry run: %d of %d loadbalancers would be unmarked, pass --no-dry-run to unmark them\n", marked, len(lbs))
+			case !noDryRun:
+				fmt.Printf("\nDry run: %d of %d loadbalancers would be marked, pass --no-dry-run to mark them\n", marked, len(lbs))
+			case unmark:
+				fmt.Printf("\n%d of %d loadbalancers unmarked\n", marked, len(lbs))
+			default:
+				fmt.Printf("\n%d of %d loadbalancers marked, delete them later with: oli sweep --older-than <duration>\n", marked, len(lbs))
+			}
+			return firstErr
+		},
+	}
+	selected.addFlags(c.Flags())
+	c.Flags().BoolVar(&unmark, "unmark", false, "Remove the marks instead.")
+	c.Flags().BoolVar(&octavia, "octavia", false, "Use the Octavia endpoint instead of Neutron LBaaS.")
+	c.Flags().BoolVar(&noDryRun, "no-dry-run", false, "The real deal!")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(markCmd())
+}
+
+// markCandidates returns the LoadBalancers matching the criteria, restricted
+// to refs if any are given.
+func markCandidates(inv *inventory.Inventory, selected criteria, refs []string) ([]loadbalancers.LoadBalancer, error) {
+	lbs, err := selected.apply(inv)
+	if err != nil || len(refs) == 0 {
+		return lbs, err
+	}
+	matching := map[string]bool{}
+	for _, lb := range lbs {
+		matching[lb.ID] = true
+	}
+	var result []loadbalancers.LoadBalancer
+	for _, ref := range refs {
+		id, err := resolve(inv, ref, client.KindLoadBalancer)
+		if err != nil {
+			return nil, err
+		}
+		if !matching[id] {
+			fmt.Printf("loadbalancer %s does not match the criteria, skipping\n", id)
+			continue
+		}
+		lb, _ := inv.LoadBalancer(id)
+		result = append(result, lb)
+	}
+	return result, nil
+}
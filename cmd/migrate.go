@@ -0,0 +1,216 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/migrate"
+)
+
+type migrateOptions struct {
+	vipSubnet      string
+	deleteOriginal bool
+	noDryRun       bool
+	confirm        string
+	yes            bool
+	reportDir      string
+}
+
+// migrateCmd represents the migrate command
+func migrateCmd() *cobra.Command {
+	var opts migrateOptions
+	var continueOnError bool
+	c := &cobra.Command{
+		Use:   "migrate <LoadBalancer>...",
+		Short: "Migrate Neutron LBaaS LoadBalancers to Octavia",
+		Long: `Migrate one or more LoadBalancers from Neutron LBaaS v2 to Octavia.
+
+Each LoadBalancer is read from the Neutron LBaaS API and recreated with everything
+attached on the Octavia endpoint. Fields Octavia does not support are dropped,
+the new LoadBalancer gets a new VIP address. Both are then compared: topology,
+listeners, members, the provisioning status of the new LoadBalancer and the
+health of its members.
+
+With --delete-original the original is deleted like "oli delete" does, once all
+checks passed. This is a dry run unless --no-dry-run is given. Real runs ask for
+confirmation first, depending on --confirm, write a backup of the original and a
+checkpoint journal to --report-dir. Pass --yes to skip all questions. Without
+--yes, stdin must be a terminal.
+
+A migration report per LoadBalancer is written to --report-dir, listing the ID
+mapping, changed and dropped fields and the outcome of all checks.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if recordDir != "" || replayDir != "" {
+				return fmt.Errorf("--record and --replay are not supported by migrate")
+			}
+			if err := validateConfirmLevel(opts.confirm); err != nil {
+				return err
+			}
+			if opts.deleteOriginal && opts.noDryRun && !opts.yes && !isTerminal(os.Stdin) {
+				return fmt.Errorf("refusing to delete without confirmation, stdin is not a terminal: pass --yes to delete anyway")
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			neutron, err := newOpenStackProvider(client.Config{DryRun: !opts.noDryRun})
+			if err != nil {
+				return err
+			}
+			octavia, err := newOpenStackProvider(client.Config{Octavia: true})
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(opts.reportDir, 0755); err != nil {
+				return fmt.Errorf("failed to create report directory: %s", err)
+			}
+			ids, err := resolveLoadBalancers(ctx, neutron, args)
+			if err != nil {
+				return err
+			}
+			in := bufio.NewReader(os.Stdin)
+			var firstErr error
+			for _, id := range ids {
+				report, err := migrateLoadBalancer(ctx, in, os.Stdout, neutron, octavia, id, opts)
+				if err != nil {
+					report.Error = err.Error()
+					if firstErr == nil {
+						firstErr = err
+					}
+				}
+				path := filepath.Join(opts.reportDir, fmt.Sprintf("oli-migrate-%s-%s.json", id, time.Now().Format("20060102-150405")))
+				if werr := report.Write(path); werr != nil {
+					return werr
+				}
+				fmt.Printf("wrote migration report to %s\n", path)
+				if err != nil && (ctx.Err() != nil || !continueOnError) {
+					break
+				}
+			}
+			return firstErr
+		},
+	}
+	c.Flags().StringVar(&opts.vipSubnet, "vip-subnet", "", "Subnet for the VIP (default is the subnet of the original).")
+	c.Flags().BoolVar(&opts.deleteOriginal, "delete-original", false, "Delete the original once all checks passed.")
+	c.Flags().BoolVar(&opts.noDryRun, "no-dry-run", false, "Really delete the original with --delete-original.")
+	c.Flags().StringVar(&opts.confirm, "confirm", confirmPlan, "Ask for confirmation once per \"plan\", per \"loadbalancer\" or by typing the \"name\" of each.")
+	c.Flags().BoolVar(&opts.yes, "yes", false, "Do not ask before deleting the original.")
+	c.Flags().StringVar(&opts.reportDir, "report-dir", ".", "Directory for the migration reports and backups.")
+	c.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Continue with the next LoadBalancer if migrating one fails.")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd())
+}
+
+// migrateLoadBalancer migrates a single LoadBalancer. The returned report is
+// complete even if an error is returned.
+func migrateLoadBalancer(ctx context.Context, in *bufio.Reader, out io.Writer, neutron, octavia client.OpenStackProvider, id string, opts migrateOptions) (*migrate.Report, error) {
+	original, err := neutron.ExportLoadBalancer(ctx, id)
+	if err != nil {
+		return &migrate.Report{SourceID: id, MigratedAt: time.Now().UTC()}, err
+	}
+	translated, report := migrate.Translate(original)
+	restored, err := octavia.RestoreLoadBalancer(ctx, translated, client.RestoreOptions{VipSubnetID: opts.vipSubnet})
+	if restored != nil {
+		report.TargetID = restored.LoadBalancerID
+		report.Mapping = restored.Mapping
+	}
+	if err != nil {
+		return report, err
+	}
+
+	migrated, err := octavia.ExportLoadBalancer(ctx, report.TargetID)
+	if err != nil {
+		return report, err
+	}
+	sourceStatus, err := neutron.GetLoadBalancerStatuses(ctx, id)
+	if err != nil {
+		return report, err
+	}
+	targetStatus, err := octavia.GetLoadBalancerStatuses(ctx, report.TargetID)
+	if err != nil {
+		return report, err
+	}
+	report.Checks = migrate.Verify(translated, migrated, sourceStatus, targetStatus)
+	printMigrationReport(out, report)
+
+	if !opts.deleteOriginal {
+		return report, nil
+	}
+	if !migrate.Passed(report.Checks) {
+		return report, fmt.Errorf("not deleting loadbalancer %s, checks of the migrated loadbalancer %s failed", id, report.TargetID)
+	}
+	entry, err := neutron.PlanLoadBalancerDeletion(ctx, id)
+	if err != nil {
+		return report, err
+	}
+	plan := &client.Plan{Entries: []client.PlanEntry{entry}}
+	run := deleteRun{dryRun: !opts.noDryRun}
+	if opts.noDryRun {
+		path := filepath.Join(opts.reportDir, fmt.Sprintf("oli-migrate-delete-%s-%s.journal", id, time.Now().Format("20060102-150405")))
+		j, err := createJournal(path, *plan)
+		if err != nil {
+			return report, err
+		}
+		defer j.Close()
+		run.journal = j
+		run.backupDir = opts.reportDir
+		if !opts.yes {
+			run.confirm = &confirmer{level: opts.confirm, in: in, out: out}
+		}
+	}
+	reports, err := run.deletePlan(ctx, out, neutron, plan, nil)
+	if len(reports) > 0 {
+		report.Deleted = reports[len(reports)-1]
+	}
+	return report, err
+}
+
+// printMigrationReport prints the changed and dropped fields and the checks
+// of a migration.
+func printMigrationReport(out io.Writer, report *migrate.Report) {
+	fmt.Fprintf(out, "\nloadbalancer %s was migrated to %s\n", report.SourceID, report.TargetID)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if len(report.Fields)+len(report.Dropped) > 0 {
+		fmt.Fprintln(w, "\nKIND\tID\tFIELD\tOLD\tNEW\tNOTE")
+		for _, f := range report.Fields {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Kind, f.ID, f.Field, f.Old, f.New, f.Note)
+		}
+		for _, f := range report.Dropped {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t(dropped)\t%s\n", f.Kind, f.ID, f.Field, f.Old, f.Note)
+		}
+	}
+	fmt.Fprintln(w, "\nCHECK\tRESULT\tDETAIL")
+	for _, c := range report.Checks {
+		result := "ok"
+		if !c.OK {
+			result = "FAILED"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name, result, c.Detail)
+	}
+	w.Flush()
+}
@@ -0,0 +1,137 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/migrate"
+)
+
+func TestMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := fakecloud.NewServer()
+	defer s.Close()
+	// the command polls every 2 seconds
+	s.PendingTicks = 0
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web", VipSubnetID: "subnet-1", Provider: "haproxy"})
+	listener := s.AddListener(lb.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+	pool := s.AddPool(lb.ID, listener.ID, pools.Pool{Name: "backends", Protocol: "HTTP", LBMethod: "ROUND_ROBIN"})
+	s.AddMember(pool.ID, pools.Member{Address: "10.0.0.5", ProtocolPort: 8080})
+	setFakeEnv(s)
+
+	c := migrateCmd()
+	c.SetArgs([]string{lb.ID, "--delete-original", "--no-dry-run", "--yes", "--report-dir", dir})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("migrate failed: %s", err)
+	}
+	if s.Exists(lb.ID) {
+		t.Errorf("original loadbalancer %s was not deleted", lb.ID)
+	}
+
+	reports, _ := filepath.Glob(filepath.Join(dir, "oli-migrate-"+lb.ID+"-*.json"))
+	if len(reports) != 1 {
+		t.Fatalf("got reports %v, want one", reports)
+	}
+	data, err := ioutil.ReadFile(reports[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &migrate.Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Exists(report.TargetID) {
+		t.Errorf("migrated loadbalancer %s does not exist", report.TargetID)
+	}
+	if !migrate.Passed(report.Checks) {
+		t.Errorf("checks failed: %+v", report.Checks)
+	}
+	if len(report.Mapping) != 4 {
+		t.Errorf("got %d mapped objects, want 4", len(report.Mapping))
+	}
+	if report.Deleted == nil || len(report.Deleted.Deleted) != 3 {
+		t.Errorf("got deletion report %+v, want 3 deleted objects", report.Deleted)
+	}
+	if backups, _ := filepath.Glob(filepath.Join(dir, "oli-backup-"+lb.ID+"-*.yaml")); len(backups) != 1 {
+		t.Errorf("got backups %v, want one", backups)
+	}
+	if journals, _ := filepath.Glob(filepath.Join(dir, "oli-migrate-delete-"+lb.ID+"-*.journal")); len(journals) != 1 {
+		t.Errorf("got journals %v, want one", journals)
+	}
+}
+
+func TestMigrateDeleteOriginalDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := fakecloud.NewServer()
+	defer s.Close()
+	// the command polls every 2 seconds
+	s.PendingTicks = 0
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web", VipSubnetID: "subnet-1", Provider: "haproxy"})
+	s.AddListener(lb.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+	setFakeEnv(s)
+
+	// stdin of the tests is not a terminal
+	c := migrateCmd()
+	c.SetArgs([]string{lb.ID, "--delete-original", "--no-dry-run", "--report-dir", dir})
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "stdin is not a terminal") {
+		t.Errorf("got error %v, want a refusal", err)
+	}
+
+	c = migrateCmd()
+	c.SetArgs([]string{lb.ID, "--delete-original", "--report-dir", dir})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("migrate failed: %s", err)
+	}
+	if !s.Exists(lb.ID) {
+		t.Errorf("original loadbalancer %s was deleted by a dry run", lb.ID)
+	}
+	reports, _ := filepath.Glob(filepath.Join(dir, "oli-migrate-"+lb.ID+"-*.json"))
+	if len(reports) != 1 {
+		t.Fatalf("got reports %v, want one", reports)
+	}
+	data, err := ioutil.ReadFile(reports[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &migrate.Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Deleted == nil || !report.Deleted.DryRun || len(report.Deleted.Deleted) != 2 {
+		t.Errorf("got deletion report %+v, want a dry run of 2 objects", report.Deleted)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.journal")); len(files) != 0 {
+		t.Errorf("dry run wrote journals %v", files)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/notify"
+)
+
+// notifyTimeout limits the time spent sending a notification.
+const notifyTimeout = time.Minute
+
+// runNotifier sends the notifications of a deletion run as configured below
+// "notifications" in the oli config file.
+type runNotifier struct {
+	source    string
+	notifiers notify.Notifiers
+	// inv, if set, provides the descriptions the owners are taken from.
+	inv *inventory.Inventory
+}
+
+// newRunNotifier returns nil if no notifications are configured.
+func newRunNotifier(source string) (*runNotifier, error) {
+	var config notify.Config
+	if err := viper.UnmarshalKey("notifications", &config); err != nil {
+		return nil, fmt.Errorf("failed to read notifications config: %s", err)
+	}
+	notifiers, err := notify.New(config)
+	if err != nil || len(notifiers) == 0 {
+		return nil, err
+	}
+	return &runNotifier{source: source, notifiers: notifiers}, nil
+}
+
+func (n *runNotifier) owner(id, name string) string {
+	if n.inv != nil {
+		if lb, ok := n.inv.LoadBalancer(id); ok {
+			return notify.Owner(lb.Name, lb.Description)
+		}
+	}
+	return notify.Owner(name, "")
+}
+
+// send delivers an event. Failures are reported but never stop a run, and
+// events are sent even if the run was interrupted.
+func (n *runNotifier) send(e notify.Event) {
+	e.Source = n.source
+	e.Time = time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := n.notifiers.Notify(ctx, e); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to send %s notification: %s\n", e.Kind, err)
+	}
+}
+
+// notice announces the deletion of the unprotected LoadBalancers of a plan.
+func (n *runNotifier) notice(plan *client.Plan, dryRun bool) {
+	e := notify.Event{Kind: notify.KindNotice, DryRun: dryRun}
+	for _, entry := range plan.Entries {
+		if !entry.Protected {
+			e.Candidates = append(e.Candidates, notify.Candidate{
+				LoadBalancerID: entry.LoadBalancerID,
+				Name:           entry.Name,
+				Owner:          n.owner(entry.LoadBalancerID, entry.Name),
+			})
+		}
+	}
+	if len(e.Candidates) > 0 {
+		n.send(e)
+	}
+}
+
+// summary reports the outcome of a run.
+func (n *runNotifier) summary(plan *client.Plan, reports []*client.DeleteReport, dryRun bool) {
+	names := map[string]string{}
+	for _, entry := range plan.Entries {
+		names[entry.LoadBalancerID] = entry.Name
+	}
+	e := notify.Event{Kind: notify.KindSummary, DryRun: dryRun}
+	for _, report := range reports {
+		name := names[report.LoadBalancerID]
+		e.Results = append(e.Results, notify.NewResult(report, name, n.owner(report.LoadBalancerID, name)))
+	}
+	if len(e.Results) > 0 {
+		n.send(e)
+	}
+}
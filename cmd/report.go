@@ -0,0 +1,127 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/report"
+)
+
+// reportCmd represents the report command
+func reportCmd() *cobra.Command {
+	var htmlPath string
+	var regions []string
+	var allProjects bool
+	var fromSnapshot string
+	var noStats bool
+	c := &cobra.Command{
+		Use:   "report --html <file>",
+		Short: "Write an HTML report of all LoadBalancers for their owners",
+		Long: `Write a single static HTML file about all LoadBalancers, to be sent to the
+people owning them.
+
+The report has summary counts per region, project and status, a sortable table
+of all LoadBalancers, their topology as collapsible trees and sections for
+orphaned objects, idle LoadBalancers and policy violations, like pools without
+health monitor or LoadBalancers in ERROR.
+
+LoadBalancers without listeners and pools are idle. Unless --no-stats is given
+or the report is written from a snapshot, the traffic statistics of every
+LoadBalancer are collected as well and LoadBalancers which never had a
+connection are idle, too.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if htmlPath == "" {
+				return fmt.Errorf("--html is required")
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			var results []report.Region
+			if fromSnapshot != "" {
+				if len(regions) > 0 || allProjects {
+					return fmt.Errorf("--regions and --all-projects cannot be used with --from-snapshot")
+				}
+				inv, err := collectInventory(ctx, fromSnapshot)
+				if err != nil {
+					return err
+				}
+				results = append(results, report.Region{Name: inv.Metadata.Region, Inventory: inv})
+			} else {
+				if len(regions) == 0 {
+					regions = []string{os.Getenv("OS_REGION_NAME")}
+				}
+				if len(regions) > 1 && (recordDir != "" || replayDir != "") {
+					return fmt.Errorf("--record and --replay cannot be used with several regions")
+				}
+				for _, region := range regions {
+					osClient, err := newOpenStackProvider(client.Config{Region: region, AllProjects: allProjects})
+					if err != nil {
+						return fmt.Errorf("region %s: %w", region, err)
+					}
+					inv, err := inventory.Collect(ctx, osClient)
+					if err != nil {
+						return fmt.Errorf("region %s: %w", region, err)
+					}
+					r := report.Region{Name: region, Inventory: inv}
+					if !noStats {
+						r.Stats = map[string]*loadbalancers.Stats{}
+						for _, lb := range inv.LoadBalancers {
+							stats, err := osClient.GetLoadBalancerStats(ctx, lb.ID)
+							if err != nil {
+								// the report is still useful without them
+								fmt.Fprintf(os.Stderr, "failed to get statistics of loadbalancer %s: %s\n", lb.ID, err)
+								continue
+							}
+							r.Stats[lb.ID] = stats
+						}
+					}
+					results = append(results, r)
+				}
+			}
+
+			f, err := os.Create(htmlPath)
+			if err != nil {
+				return fmt.Errorf("failed to create report: %s", err)
+			}
+			if err := report.WriteHTML(f, time.Now(), results); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write report: %s", err)
+			}
+			fmt.Printf("wrote report to %s\n", htmlPath)
+			return nil
+		},
+	}
+	c.Flags().StringVar(&htmlPath, "html", "", "HTML file to write.")
+	c.Flags().StringSliceVar(&regions, "regions", nil, "Regions to report on (default is OS_REGION_NAME).")
+	c.Flags().BoolVar(&allProjects, "all-projects", false, "Report on the objects of all projects.")
+	c.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Work offline on a snapshot written by \"oli snapshot\".")
+	c.Flags().BoolVar(&noStats, "no-stats", false, "Do not collect the traffic statistics of the LoadBalancers.")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd())
+}
@@ -0,0 +1,61 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+)
+
+func TestReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := fakecloud.NewServer()
+	defer s.Close()
+	seedListTree(s)
+	busy := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "busy"})
+	s.SetStats(busy.ID, loadbalancers.Stats{ActiveConnections: 2, TotalConnections: 7})
+	broken := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "broken"})
+	s.InjectError(http.MethodGet, "/loadbalancers/"+broken.ID+"/stats", http.StatusInternalServerError, `{"faultcode": "Server", "faultstring": "stats unavailable"}`)
+	setFakeEnv(s)
+
+	path := filepath.Join(dir, "report.html")
+	c := reportCmd()
+	c.SetArgs([]string{"--html", path})
+	if err := c.Execute(); err != nil {
+		t.Fatalf("report failed: %s", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, want := range []string{"loadbalancer web", "2 / 7", "has neither listeners nor pools", "loadbalancer broken"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// resolve finds the object an argument refers to by ID, ID prefix or name.
+// If it was not given by its full ID, the resolved object is noted on stderr.
+func resolve(inv *inventory.Inventory, ref string, kinds ...string) (string, error) {
+	r, err := inv.Resolve(ref, kinds...)
+	if err != nil {
+		return "", err
+	}
+	if r.ID != ref {
+		fmt.Fprintf(os.Stderr, "resolved %q to %s %s (%s)\n", ref, r.Kind, r.ID, r.Name)
+	}
+	return r.ID, nil
+}
+
+// resolveLoadBalancers resolves LoadBalancer IDs, ID prefixes and names to
+// IDs. Only the LoadBalancers are listed, not the whole inventory.
+func resolveLoadBalancers(ctx context.Context, osClient client.OpenStackProvider, refs []string) ([]string, error) {
+	lbs, err := osClient.ListLBaaS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inv := &inventory.Inventory{LoadBalancers: lbs}
+	var ids []string
+	for _, ref := range refs {
+		id, err := resolve(inv, ref, client.KindLoadBalancer)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,112 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/spec"
+)
+
+// restoreCmd represents the restore command
+func restoreCmd() *cobra.Command {
+	var opts client.RestoreOptions
+	var keepVip bool
+	var mappingOut string
+	c := &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Recreate a LoadBalancer + everything attached from a backup",
+		Long: `Recreate a LoadBalancer + everything attached from a spec written by "oli backup".
+
+All objects get new IDs. A table mapping the IDs of the spec to the new ones
+is printed at the end, --mapping-out writes it as JSON as well. If the restore
+fails, the objects created so far are kept and listed.
+
+The cloud picks a new VIP address unless --vip-address or --keep-vip is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := spec.Read(args[0])
+			if err != nil {
+				return err
+			}
+			if keepVip {
+				if opts.VipAddress != "" {
+					return fmt.Errorf("--keep-vip and --vip-address cannot be used together")
+				}
+				opts.VipAddress = s.LoadBalancer.VipAddress
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			osClient, err := newOpenStackProvider(client.Config{})
+			if err != nil {
+				return err
+			}
+			report, err := osClient.RestoreLoadBalancer(ctx, s, opts)
+			if report != nil {
+				printRestoreReport(os.Stdout, report)
+				if mappingOut != "" {
+					if werr := writeRestoreReport(mappingOut, report); werr != nil && err == nil {
+						err = werr
+					}
+				}
+			}
+			return err
+		},
+	}
+	c.Flags().StringVar(&opts.VipSubnetID, "vip-subnet", "", "Subnet for the VIP (default is the subnet of the spec).")
+	c.Flags().StringVar(&opts.VipAddress, "vip-address", "", "VIP address to request for the new LoadBalancer.")
+	c.Flags().BoolVar(&keepVip, "keep-vip", false, "Request the VIP address of the spec.")
+	c.Flags().StringVar(&opts.MemberSubnetID, "member-subnet", "", "Subnet for all members (default is the subnet of each member in the spec).")
+	c.Flags().StringVar(&mappingOut, "mapping-out", "", "Write the ID mapping as JSON to the given file.")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd())
+}
+
+// printRestoreReport prints a table mapping the IDs of a spec to the restored
+// objects.
+func printRestoreReport(out io.Writer, report *client.RestoreReport) {
+	if len(report.Mapping) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tOLD ID\tNEW ID")
+	for _, m := range report.Mapping {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Kind, m.Name, m.OldID, m.NewID)
+	}
+	w.Flush()
+}
+
+func writeRestoreReport(path string, report *client.RestoreReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode id mapping: %s", err)
+	}
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write id mapping: %s", err)
+	}
+	return nil
+}
@@ -16,33 +16,51 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/transport"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var debugHTTP bool
+var debugHTTPFormat string
+var recordDir string
+var replayDir string
 
 var rootCmd = &cobra.Command{
 	Use:   "oli",
 	Short: "OpenStack LBaaS Imploader (oli)",
 	Long: `Oli removes an OpenStack LoadBalancer V2 instance together will all its
 dependant Listeners, Health Monitors, Pools and Members.`,
+	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// arguments are valid at this point, errors from here on are no usage errors
+		cmd.SilenceUsage = true
+	},
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.oli.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output, also disabled by setting NO_COLOR.")
+	rootCmd.PersistentFlags().BoolVar(&debugHTTP, "debug-http", false, "Trace all API requests and responses to stderr, credentials are redacted.")
+	rootCmd.PersistentFlags().StringVar(&debugHTTPFormat, "debug-http-format", transport.FormatText, "Format of the HTTP trace, one of text or json (JSON lines).")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Record all API requests and responses, credentials redacted, to a cassette in this directory.")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Replay a cassette recorded with --record instead of talking to the cloud.")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.SetUsageTemplate(rootCmd.UsageTemplate() + exitCodesHelp + "\n")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -67,6 +85,64 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
+
+// newOpenStackProvider creates the OpenStack client for a command and applies
+// the global flags to its config.
+func newOpenStackProvider(config client.Config) (client.OpenStackProvider, error) {
+	if recordDir != "" && replayDir != "" {
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	}
+	if debugHTTP {
+		// validate the format before a cassette is created
+		if _, err := transport.NewTracer(nil, os.Stderr, debugHTTPFormat); err != nil {
+			return nil, err
+		}
+	}
+	var wrappers []func(http.RoundTripper) (http.RoundTripper, error)
+	if replayDir != "" {
+		replayConfig, err := readSession(replayDir)
+		if err != nil {
+			return nil, err
+		}
+		config.AuthOptions = replayConfig.AuthOptions
+		config.Region = replayConfig.Region
+		config.PollInterval = replayConfig.PollInterval
+		replayer, err := transport.NewReplayer(replayDir)
+		if err != nil {
+			return nil, err
+		}
+		wrappers = append(wrappers, func(http.RoundTripper) (http.RoundTripper, error) {
+			return replayer, nil
+		})
+	}
+	if recordDir != "" {
+		wrappers = append(wrappers, func(next http.RoundTripper) (http.RoundTripper, error) {
+			recorder, err := transport.NewRecorder(next, recordDir)
+			if err != nil {
+				return nil, err
+			}
+			return recorder, writeSession(recordDir, config.AuthOptions)
+		})
+	}
+	if debugHTTP {
+		wrappers = append(wrappers, func(next http.RoundTripper) (http.RoundTripper, error) {
+			return transport.NewTracer(next, os.Stderr, debugHTTPFormat)
+		})
+	}
+	var rt http.RoundTripper = http.DefaultTransport
+	for _, wrap := range wrappers {
+		var err error
+		if rt, err = wrap(rt); err != nil {
+			return nil, err
+		}
+	}
+	if len(wrappers) > 0 {
+		config.WrapTransport = func(http.RoundTripper) http.RoundTripper {
+			return rt
+		}
+	}
+	return client.NewOpenStackProvider(config)
+}
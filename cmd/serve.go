@@ -0,0 +1,128 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/metrics"
+)
+
+// serveCmd represents the serve command
+func serveCmd() *cobra.Command {
+	var serveMetrics bool
+	var listen string
+	var interval time.Duration
+	var noStats bool
+	c := &cobra.Command{
+		Use:   "serve --metrics",
+		Short: "Run as a Prometheus exporter of the LBaaS objects",
+		Long: `Run until interrupted, collecting the inventory every --interval and exposing
+Prometheus metrics on http://<listen>/metrics:
+
+  oli_loadbalancers, oli_pools, oli_members, oli_l7_policies
+      by provisioning_status and operating_status
+  oli_listeners, oli_health_monitors
+      by provisioning_status
+  oli_empty_loadbalancers                 LoadBalancers without listeners and pools
+  oli_orphans                             by kind
+  oli_loadbalancer_active_connections, oli_loadbalancer_connections_total,
+  oli_loadbalancer_bytes_in_total, oli_loadbalancer_bytes_out_total,
+  oli_loadbalancer_request_errors_total   per LoadBalancer, unless --no-stats
+  oli_collections_total, oli_collection_errors_total (by stage),
+  oli_collection_duration_seconds, oli_last_successful_collection_timestamp_seconds
+
+If a collection fails, the metrics of the last successful one are served.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !serveMetrics {
+				return fmt.Errorf("nothing to serve, pass --metrics")
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			osClient, err := newOpenStackProvider(client.Config{})
+			if err != nil {
+				return err
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			return serveMetricsUntil(ctx, osClient, listen, interval, !noStats)
+		},
+	}
+	c.Flags().BoolVar(&serveMetrics, "metrics", false, "Serve Prometheus metrics.")
+	c.Flags().StringVar(&listen, "listen", ":9318", "Address to listen on.")
+	c.Flags().DurationVar(&interval, "interval", 5*time.Minute, "Time between two collections.")
+	c.Flags().BoolVar(&noStats, "no-stats", false, "Do not collect the traffic statistics of every LoadBalancer.")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd())
+}
+
+// serveMetricsUntil collects the metrics every interval and serves them until
+// ctx is done.
+func serveMetricsUntil(ctx context.Context, osClient client.OpenStackProvider, listen string, interval time.Duration, stats bool) error {
+	exporter := metrics.NewExporter(osClient, stats)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `<html><body><h1>oli</h1><a href="/metrics">Metrics</a></body></html>`)
+	})
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %s", listen, err)
+	}
+	server := &http.Server{Handler: mux}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(l)
+	}()
+	fmt.Fprintf(os.Stderr, "serving metrics on http://%s/metrics\n", l.Addr())
+
+	refresh := func() {
+		if err := exporter.Refresh(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "%s collection failed: %s\n", time.Now().Format(time.RFC3339), err)
+		}
+	}
+	refresh()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			refresh()
+		case err := <-served:
+			return fmt.Errorf("failed to serve metrics: %s", err)
+		case <-ctx.Done():
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdown)
+		}
+	}
+}
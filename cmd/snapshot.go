@@ -0,0 +1,116 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// snapshotCmd represents the snapshot command
+func snapshotCmd() *cobra.Command {
+	var output string
+	c := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save all LBaaS objects of your tenant to a file",
+		Long: `Save all LBaaS objects of your tenant to a file.
+
+The snapshot holds LoadBalancers, Listeners, Pools, Members, Health Monitors and
+L7 Policies together with the time and place they were collected. Commands which
+support --from-snapshot work on it offline, without credentials.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signalContext()
+			defer stop()
+			inv, err := collectInventory(ctx, "")
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				output = fmt.Sprintf("oli-snapshot-%s.json", inv.CollectedAt.Local().Format("20060102-150405"))
+			}
+			f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create snapshot: %s", err)
+			}
+			if err := inv.Write(f); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write snapshot: %s", err)
+			}
+			fmt.Printf("wrote %d loadbalancers to snapshot %s\n", len(inv.LoadBalancers), output)
+			return nil
+		},
+	}
+	c.Flags().StringVarP(&output, "output", "o", "", "Snapshot file to write (default is oli-snapshot-<timestamp>.json).")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd())
+}
+
+// collectInventory loads the inventory from a snapshot, or collects it from
+// the cloud if fromSnapshot is empty.
+func collectInventory(ctx context.Context, fromSnapshot string) (*inventory.Inventory, error) {
+	if fromSnapshot != "" {
+		inv, err := inventory.Load(fromSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "using snapshot %s collected at %s\n", fromSnapshot, inv.CollectedAt.Local().Format(time.RFC3339))
+		return inv, nil
+	}
+	osClient, err := newOpenStackProvider(client.Config{})
+	if err != nil {
+		return nil, err
+	}
+	inv, err := inventory.Collect(ctx, osClient)
+	if err != nil {
+		return nil, err
+	}
+	inv.Metadata = inventoryMetadata()
+	return inv, nil
+}
+
+// inventoryMetadata describes the cloud the current command talks to.
+func inventoryMetadata() inventory.Metadata {
+	config := client.Config{Region: os.Getenv("OS_REGION_NAME")}
+	if replayDir != "" {
+		config, _ = readSession(replayDir)
+	} else {
+		opts, _ := client.AuthOptionsFromEnv()
+		config.AuthOptions = &opts
+	}
+	if config.AuthOptions == nil {
+		return inventory.Metadata{}
+	}
+	return inventory.Metadata{
+		AuthURL:     config.AuthOptions.IdentityEndpoint,
+		Region:      config.Region,
+		ProjectID:   config.AuthOptions.TenantID,
+		ProjectName: config.AuthOptions.TenantName,
+		Username:    config.AuthOptions.Username,
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/renderer"
+)
+
+// writeSnapshot collects the inventory of s into a snapshot file.
+func writeSnapshot(t *testing.T, s *fakecloud.Server) string {
+	inv, err := inventory.Collect(context.Background(), newFakeProvider(t, s))
+	if err != nil {
+		t.Fatalf("failed to collect inventory: %s", err)
+	}
+	f, err := ioutil.TempFile("", "oli-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := inv.Write(f); err != nil {
+		t.Fatalf("failed to write snapshot: %s", err)
+	}
+	return f.Name()
+}
+
+func TestListFromSnapshot(t *testing.T) {
+	s := fakecloud.NewServer()
+	seedListTree(s)
+	live := list(t, newFakeProvider(t, s), false)
+	path := writeSnapshot(t, s)
+	defer os.Remove(path)
+	s.Close()
+
+	inv, err := inventory.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %s", err)
+	}
+	if offline := renderer.RenderTree(inv, false); offline != live {
+		t.Errorf("snapshot renders differently, got:\n%s\nwant:\n%s", offline, live)
+	}
+}
+
+func TestDeleteFromSnapshot(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	seedListTree(s)
+	protected := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "keep", Description: "oli:protected"})
+	path := writeSnapshot(t, s)
+	defer os.Remove(path)
+	inv, err := inventory.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %s", err)
+	}
+	web := inv.LoadBalancers[0]
+
+	var out bytes.Buffer
+	// by name and by ID prefix
+	if err := planFromSnapshot(&out, path, []string{web.Name, protected.ID[:8]}, false); err != nil {
+		t.Fatalf("dry run failed: %s", err)
+	}
+	for _, want := range []string{"delete pool with id", "delete listener with id", "Dry run: delete loadbalancer with id " + web.ID, protected.ID + " is protected"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := planFromSnapshot(&out, path, []string{"unknown", web.ID}, false); err == nil {
+		t.Errorf("dry run of an unknown loadbalancer succeeded")
+	}
+	if strings.Contains(out.String(), "Dry run: delete") {
+		t.Errorf("dry run continued after an error:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "failed to delete loadbalancer unknown ") {
+		t.Errorf("output does not name the unknown loadbalancer:\n%s", out.String())
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/journal"
+)
+
+// sweepCmd represents the sweep command
+func sweepCmd() *cobra.Command {
+	var selected criteria
+	var olderThan time.Duration
+	var octavia bool
+	var noDryRun bool
+	var continueOnError bool
+	var journalPath string
+	var noBackup bool
+	var backupDir string
+	var confirm string
+	var assumeYes bool
+	c := &cobra.Command{
+		Use:   "sweep --older-than <duration>",
+		Short: "Delete the LoadBalancers marked by \"oli mark\" a while ago",
+		Long: `Delete the LoadBalancers + everything attached which were marked by "oli mark"
+at least --older-than ago and still match the criteria they were marked by, as
+well as --empty, --status and --name-pattern if given. LoadBalancers marked by
+ID without criteria are deleted once they were marked long enough ago. Pass the
+same --octavia as to "oli mark".
+
+Deletion works like "oli delete": runs are dry unless --no-dry-run is given,
+real runs ask for confirmation, take backups, write a checkpoint journal and send
+notifications, and LoadBalancers whose description contains "` + client.ProtectionMarker + `" are never deleted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateConfirmLevel(confirm); err != nil {
+				return err
+			}
+			if err := selected.validate(); err != nil {
+				return err
+			}
+			if olderThan < 0 {
+				return fmt.Errorf("--older-than must not be negative")
+			}
+			if noDryRun && !assumeYes && !isTerminal(os.Stdin) {
+				return fmt.Errorf("refusing to delete without confirmation, stdin is not a terminal: pass --yes to delete anyway")
+			}
+			// dry runs are not announced
+			var notifier *runNotifier
+			if noDryRun {
+				var err error
+				if notifier, err = newRunNotifier("sweep"); err != nil {
+					return err
+				}
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			osClient, err := newOpenStackProvider(client.Config{DryRun: !noDryRun, Octavia: octavia})
+			if err != nil {
+				return err
+			}
+			inv, err := inventory.Collect(ctx, osClient)
+			if err != nil {
+				return err
+			}
+			lbs, err := selected.apply(inv)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			var ids []string
+			for _, lb := range lbs {
+				mark, err := osClient.GetLoadBalancerMark(ctx, lb.ID)
+				if err != nil {
+					return err
+				}
+				if mark == nil {
+					continue
+				}
+				// the LoadBalancer has to match the criteria it was marked by too
+				marked, err := decodeCriteria(mark.Criteria)
+				if err != nil {
+					fmt.Printf("loadbalancer %s (%s): %s, keeping it\n", lb.ID, lb.Name, err)
+					continue
+				}
+				if ok, err := marked.match(inv, lb); err != nil || !ok {
+					fmt.Printf("loadbalancer %s (%s) no longer matches the criteria it was marked by, keeping it\n", lb.ID, lb.Name)
+					continue
+				}
+				if due := mark.At.Add(olderThan); now.Before(due) {
+					fmt.Printf("loadbalancer %s (%s) is marked since %s, keeping it until %s\n", lb.ID, lb.Name, mark.At.Local().Format(time.RFC3339), due.Local().Format(time.RFC3339))
+					continue
+				}
+				ids = append(ids, lb.ID)
+			}
+			if len(ids) == 0 {
+				fmt.Println("nothing to sweep")
+				return nil
+			}
+			plan, err := inv.PlanDeletion(ids)
+			if err != nil {
+				return err
+			}
+			if notifier != nil {
+				notifier.inv = inv
+			}
+
+			run := deleteRun{dryRun: !noDryRun, continueOnError: continueOnError, notify: notifier}
+			if noDryRun {
+				var j *journal.Journal
+				if j, err = createJournal(journalPath, *plan); err != nil {
+					return err
+				}
+				defer j.Close()
+				run.journal = j
+				if !noBackup {
+					run.backupDir = backupDir
+				}
+				if !assumeYes {
+					run.confirm = &confirmer{level: confirm, in: bufio.NewReader(os.Stdin), out: os.Stdout}
+				}
+			}
+			return run.execute(ctx, os.Stdout, osClient, plan, nil)
+		},
+	}
+	selected.addFlags(c.Flags())
+	c.Flags().DurationVar(&olderThan, "older-than", 24*time.Hour, "Only delete LoadBalancers marked at least this long ago.")
+	c.Flags().BoolVar(&octavia, "octavia", false, "Use the Octavia endpoint instead of Neutron LBaaS.")
+	c.Flags().BoolVar(&noDryRun, "no-dry-run", false, "The real deal!")
+	c.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Continue with the next LoadBalancer if deleting one fails.")
+	c.Flags().StringVar(&journalPath, "journal", "", "Checkpoint journal to write (default is oli-delete-<timestamp>.journal).")
+	c.Flags().BoolVar(&noBackup, "no-backup", false, "Do not back up LoadBalancers before deleting them.")
+	c.Flags().StringVar(&backupDir, "backup-dir", ".", "Directory for the backups taken before deleting.")
+	c.Flags().StringVar(&confirm, "confirm", confirmPlan, "Ask for confirmation once per \"plan\", per \"loadbalancer\" or by typing the \"name\" of each.")
+	c.Flags().BoolVar(&assumeYes, "yes", false, "Delete without asking for confirmation.")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(sweepCmd())
+}
@@ -0,0 +1,87 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/fakecloud"
+)
+
+func TestMarkAndSweep(t *testing.T) {
+	for _, tags := range []bool{true, false} {
+		dir, err := ioutil.TempDir("", "oli-sweep")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		s := fakecloud.NewServer()
+		defer s.Close()
+		// the command polls every 2 seconds
+		s.PendingTicks = 0
+		s.Tags = tags
+		empty := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "unused"})
+		protected := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "keep", Description: "oli:protected"})
+		used := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+		s.AddListener(used.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+		unmarked := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "later"})
+		grown := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "grown"})
+		setFakeEnv(s)
+
+		execute := func(c *cobra.Command, args ...string) {
+			c.SetArgs(args)
+			if err := c.Execute(); err != nil {
+				t.Fatalf("%s %v failed: %s", c.Name(), args, err)
+			}
+		}
+		// dry runs change nothing
+		execute(markCmd(), "--empty")
+		execute(sweepCmd(), "--older-than", "0s", "--no-dry-run", "--yes", "--no-backup", "--journal", filepath.Join(dir, "0.journal"))
+		if !s.Exists(empty.ID) {
+			t.Fatalf("tags %v: loadbalancer %s was marked by a dry run", tags, empty.ID)
+		}
+		execute(markCmd(), "--empty", "--no-dry-run")
+		execute(markCmd(), "--unmark", "--no-dry-run", unmarked.ID)
+		// marked just now
+		execute(sweepCmd(), "--older-than", "1h", "--no-dry-run", "--yes", "--no-backup", "--journal", filepath.Join(dir, "1.journal"))
+		if !s.Exists(empty.ID) {
+			t.Fatalf("tags %v: loadbalancer %s was deleted before the grace period ended", tags, empty.ID)
+		}
+		// the grown LoadBalancer no longer matches the criteria it was marked by
+		s.AddListener(grown.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+		execute(sweepCmd(), "--older-than", "0s", "--no-dry-run", "--yes", "--no-backup", "--journal", filepath.Join(dir, "2.journal"))
+		if !s.Exists(grown.ID) {
+			t.Fatalf("tags %v: loadbalancer %s which does not match its mark criteria was deleted", tags, grown.ID)
+		}
+		if s.Exists(empty.ID) {
+			t.Fatalf("tags %v: loadbalancer %s was not deleted", tags, empty.ID)
+		}
+		// the used LoadBalancer is marked by ID, but does not match --empty of the sweep
+		execute(markCmd(), "--no-dry-run", used.ID)
+		execute(sweepCmd(), "--older-than", "0s", "--empty", "--no-dry-run", "--yes", "--no-backup", "--journal", filepath.Join(dir, "3.journal"))
+		for _, lb := range []loadbalancers.LoadBalancer{protected, used, unmarked, grown} {
+			if !s.Exists(lb.ID) {
+				t.Errorf("tags %v: loadbalancer %s (%s) was deleted", tags, lb.ID, lb.Name)
+			}
+		}
+	}
+}
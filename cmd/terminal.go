@@ -0,0 +1,89 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// isTerminal reports whether f is connected to a terminal.
+func isTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), ioctlGetTermios)
+	return err == nil
+}
+
+// resizeSignals are the signals sent when the terminal size changes.
+var resizeSignals = []os.Signal{unix.SIGWINCH}
+
+// terminalState is the saved mode of a terminal.
+type terminalState struct {
+	termios unix.Termios
+}
+
+// makeRaw puts the terminal f into raw mode and returns its previous state.
+// Output processing is kept, so "\n" still starts a new line.
+func makeRaw(f *os.File) (*terminalState, error) {
+	fd := int(f.Fd())
+	termios, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal mode: %s", err)
+	}
+	state := &terminalState{termios: *termios}
+	raw := *termios
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := setTermios(fd, &raw); err != nil {
+		return nil, fmt.Errorf("failed to switch terminal to raw mode: %s", err)
+	}
+	return state, nil
+}
+
+// restoreTerminal restores a state saved by makeRaw.
+func restoreTerminal(f *os.File, state *terminalState) error {
+	if err := setTermios(int(f.Fd()), &state.termios); err != nil {
+		return fmt.Errorf("failed to restore terminal mode: %s", err)
+	}
+	return nil
+}
+
+// setTermios is unix.IoctlSetTermios, which the vendored x/sys does not
+// export.
+func setTermios(fd int, termios *unix.Termios) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uintptr(ioctlSetTermios), uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// terminalSize returns the width and height of the terminal f.
+func terminalSize(f *os.File) (int, int, error) {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read terminal size: %s", err)
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
@@ -0,0 +1,45 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// isTerminal reports whether f is connected to a terminal. Terminals are not
+// detected on this platform.
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+var resizeSignals []os.Signal
+
+type terminalState struct{}
+
+func makeRaw(f *os.File) (*terminalState, error) {
+	return nil, fmt.Errorf("raw terminal mode is not supported on this platform")
+}
+
+func restoreTerminal(f *os.File, state *terminalState) error {
+	return nil
+}
+
+func terminalSize(f *os.File) (int, int, error) {
+	return 0, 0, fmt.Errorf("terminal size is not supported on this platform")
+}
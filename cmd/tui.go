@@ -0,0 +1,186 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+	"github.com/afritzler/oli/pkg/journal"
+	"github.com/afritzler/oli/pkg/tui"
+)
+
+// tuiCmd represents the tui command
+func tuiCmd() *cobra.Command {
+	var noDryRun bool
+	var fromSnapshot string
+	var continueOnError bool
+	var noBackup bool
+	var backupDir string
+	var journalPath string
+	c := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse the LoadBalancers in a full-screen terminal UI",
+		Long: `Browse the LoadBalancers in a full-screen terminal UI.
+
+The left pane shows a tree of the LoadBalancers, which expand into their
+Listeners, Pools, Members, Health Monitors and L7 Policies. The right pane shows
+the details of the selected object on terminals at least 100 columns wide.
+
+Keys:
+  up/down, j/k, pgup/pgdn, home/end   move
+  right, l, enter                     expand
+  left, h                             collapse or jump to the parent
+  space                               mark the LoadBalancer of the selected row
+  /                                   filter by name or ID
+  s                                   cycle the status filter
+  e                                   show only empty LoadBalancers
+  c                                   clear all filters
+  d                                   show the deletion plan of the marked LoadBalancers
+  q, ctrl-c                           quit
+
+Confirming the plan with "y" leaves the UI and runs it like "oli delete", as a
+dry run unless --no-dry-run is given. Real runs write a checkpoint journal and
+back up every LoadBalancer first.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fromSnapshot != "" && noDryRun {
+				return fmt.Errorf("--from-snapshot only supports dry runs")
+			}
+			if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+				return fmt.Errorf("oli tui needs a terminal")
+			}
+			ctx, stop := signalContext()
+			defer stop()
+			var inv *inventory.Inventory
+			var osClient client.OpenStackProvider
+			var err error
+			if fromSnapshot != "" {
+				if inv, err = collectInventory(ctx, fromSnapshot); err != nil {
+					return err
+				}
+			} else {
+				if osClient, err = newOpenStackProvider(client.Config{DryRun: !noDryRun}); err != nil {
+					return err
+				}
+				if inv, err = inventory.Collect(ctx, osClient); err != nil {
+					return err
+				}
+			}
+
+			m := tui.NewModel(inv, !noDryRun)
+			confirmed, err := browse(m)
+			if err != nil || !confirmed {
+				return err
+			}
+			if fromSnapshot != "" {
+				return planFromSnapshot(os.Stdout, fromSnapshot, m.Marked(), continueOnError)
+			}
+			plan := m.Plan()
+			var j *journal.Journal
+			if noDryRun {
+				if j, err = createJournal(journalPath, *plan); err != nil {
+					return err
+				}
+				defer j.Close()
+			}
+			run := deleteRun{dryRun: !noDryRun, continueOnError: continueOnError, journal: j}
+			if noDryRun && !noBackup {
+				run.backupDir = backupDir
+			}
+			return run.execute(ctx, os.Stdout, osClient, plan, nil)
+		},
+	}
+	c.Flags().BoolVar(&noDryRun, "no-dry-run", false, "The real deal!")
+	c.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Browse a snapshot written by \"oli snapshot\", deletions are dry runs.")
+	c.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Continue with the next LoadBalancer if deleting one fails.")
+	c.Flags().BoolVar(&noBackup, "no-backup", false, "Do not back up LoadBalancers before deleting them.")
+	c.Flags().StringVar(&backupDir, "backup-dir", ".", "Directory for the backups taken before deleting.")
+	c.Flags().StringVar(&journalPath, "journal", "", "Checkpoint journal to write (default is oli-delete-<timestamp>.journal).")
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd())
+}
+
+// browse runs the model on the terminal until the user quits or confirms a
+// deletion plan. The terminal is restored before it returns.
+func browse(m *tui.Model) (bool, error) {
+	state, err := makeRaw(os.Stdin)
+	if err != nil {
+		return false, err
+	}
+	// alternate screen, hidden cursor
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+		restoreTerminal(os.Stdin, state)
+	}()
+
+	keys := make(chan tui.Key)
+	errs := make(chan error, 1)
+	go func() {
+		r := bufio.NewReader(os.Stdin)
+		for {
+			k, err := tui.ReadKey(r)
+			if err != nil {
+				errs <- err
+				return
+			}
+			keys <- k
+		}
+	}()
+	resized := make(chan os.Signal, 1)
+	if len(resizeSignals) > 0 {
+		signal.Notify(resized, resizeSignals...)
+		defer signal.Stop(resized)
+	}
+
+	for {
+		if width, height, err := terminalSize(os.Stdout); err == nil {
+			m.Width, m.Height = width, height
+		}
+		draw(os.Stdout, m.Render())
+		select {
+		case k := <-keys:
+			switch m.HandleKey(k) {
+			case tui.ActionQuit:
+				return false, nil
+			case tui.ActionDelete:
+				return true, nil
+			}
+		case <-resized:
+		case err := <-errs:
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to read from terminal: %s", err)
+		}
+	}
+}
+
+// draw replaces the screen with the given lines.
+func draw(out io.Writer, lines []string) {
+	fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.Join(lines, "\r\n"))
+}
@@ -0,0 +1,159 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+)
+
+// cause carries the message and the underlying error shared by all error types
+// of this package.
+type cause struct {
+	msg string
+	err error
+}
+
+func (c cause) Error() string {
+	if c.err == nil {
+		return c.msg
+	}
+	return fmt.Sprintf("%s %s", c.msg, c.err)
+}
+
+// Unwrap returns the underlying error.
+func (c cause) Unwrap() error {
+	return c.err
+}
+
+// AuthError is returned when the credentials are missing or rejected by Keystone.
+type AuthError struct{ cause }
+
+// EndpointNotFoundError is returned when the service catalog has no matching endpoint.
+type EndpointNotFoundError struct{ cause }
+
+// NotFoundError is returned when a requested object does not exist.
+type NotFoundError struct{ cause }
+
+// ConflictError is returned when an object is in a conflicting or immutable
+// state, e.g. a LoadBalancer in PENDING_UPDATE.
+type ConflictError struct{ cause }
+
+// PermissionDeniedError is returned when the user is not allowed to perform an action.
+type PermissionDeniedError struct{ cause }
+
+// PartialDeleteError is returned when a delete failed after some of the
+// objects were already removed.
+type PartialDeleteError struct {
+	cause
+	// Deleted holds the IDs of the objects removed before the failure.
+	Deleted []string
+}
+
+func (e *PartialDeleteError) Error() string {
+	return fmt.Sprintf("%s (already deleted: %s)", e.cause.Error(), strings.Join(e.Deleted, ", "))
+}
+
+// NewNotFoundError returns a NotFoundError for objects which are missing
+// outside of an API call, e.g. in an inventory.
+func NewNotFoundError(msg string) error {
+	return &NotFoundError{cause{msg: msg}}
+}
+
+// InterruptedError is returned when an operation was stopped because its
+// context was cancelled, e.g. by SIGINT.
+type InterruptedError struct{ cause }
+
+// interrupted returns an InterruptedError once ctx is done.
+func interrupted(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &InterruptedError{cause{msg: "interrupted", err: err}}
+	}
+	return nil
+}
+
+// wrapf annotates err with a message and classifies it into one of the error
+// types of this package. Errors which are already classified keep their type.
+func wrapf(err error, format string, args ...interface{}) error {
+	c := cause{msg: fmt.Sprintf(format, args...), err: err}
+	switch e := err.(type) {
+	case *AuthError, gophercloud.ErrDefault401, *gophercloud.ErrUnableToReauthenticate,
+		gophercloud.ErrMissingInput, gophercloud.ErrMissingEnvironmentVariable,
+		gophercloud.ErrMissingAnyoneOfEnvironmentVariables:
+		return &AuthError{c}
+	case *EndpointNotFoundError, *gophercloud.ErrEndpointNotFound, *openstack.ErrEndpointNotFound:
+		return &EndpointNotFoundError{c}
+	case *NotFoundError, gophercloud.ErrDefault404, gophercloud.ErrResourceNotFound:
+		return &NotFoundError{c}
+	case *ConflictError:
+		return &ConflictError{c}
+	case *PermissionDeniedError, gophercloud.ErrDefault403:
+		return &PermissionDeniedError{c}
+	case *PartialDeleteError:
+		return &PartialDeleteError{cause: c, Deleted: e.Deleted}
+	case *InterruptedError:
+		return &InterruptedError{c}
+	case gophercloud.ErrUnexpectedResponseCode:
+		if e.Actual == http.StatusConflict {
+			return &ConflictError{c}
+		}
+	}
+	return c
+}
+
+// isNotFound reports whether err means that an object does not exist.
+func isNotFound(err error) bool {
+	_, ok := wrapf(err, "").(*NotFoundError)
+	return ok
+}
+
+// apiErrorBody returns the response body of a failed API call, if err or one
+// of the errors it wraps carries one.
+func apiErrorBody(err error) string {
+	for err != nil {
+		switch e := err.(type) {
+		case gophercloud.ErrUnexpectedResponseCode:
+			return string(e.Body)
+		case gophercloud.ErrDefault400:
+			return string(e.Body)
+		case gophercloud.ErrDefault401:
+			return string(e.Body)
+		case gophercloud.ErrDefault403:
+			return string(e.Body)
+		case gophercloud.ErrDefault404:
+			return string(e.Body)
+		case gophercloud.ErrDefault405:
+			return string(e.Body)
+		case gophercloud.ErrDefault408:
+			return string(e.Body)
+		case gophercloud.ErrDefault429:
+			return string(e.Body)
+		case gophercloud.ErrDefault500:
+			return string(e.Body)
+		case gophercloud.ErrDefault503:
+			return string(e.Body)
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,259 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+)
+
+// DeletionMarker marks a LoadBalancer for deletion. It is written as the tag
+// DeletionMarker=<RFC 3339 time>[;<criterion>...], or appended to the
+// description where the API does not support tags or the tag would be too
+// long.
+const DeletionMarker = "oli-marked-for-deletion"
+
+// maxDescription is the maximum length of a description accepted by the API.
+const maxDescription = 255
+
+// maxNeutronTag is the maximum length of a tag accepted by the Neutron tag
+// extension.
+const maxNeutronTag = 60
+
+// Mark tells when and why a LoadBalancer was marked for deletion.
+type Mark struct {
+	At time.Time
+	// Criteria are the criteria the LoadBalancer was selected by. They are
+	// opaque to this package, but must not contain blanks, ";", "," or "/".
+	Criteria []string
+	// InDescription is set if the mark is part of the description because
+	// the API does not support tags or the tag would be too long.
+	InDescription bool
+}
+
+// ParseMark returns the mark found in the tags or the description of a
+// LoadBalancer.
+func ParseMark(description string, tags []string) (*Mark, bool) {
+	for _, tag := range tags {
+		if mark, ok := parseMarkTag(tag); ok {
+			return mark, true
+		}
+	}
+	for _, word := range strings.Fields(description) {
+		if mark, ok := parseMarkTag(word); ok {
+			mark.InDescription = true
+			return mark, true
+		}
+	}
+	return nil, false
+}
+
+func parseMarkTag(s string) (*Mark, bool) {
+	if !strings.HasPrefix(s, DeletionMarker+"=") {
+		return nil, false
+	}
+	fields := strings.Split(strings.TrimPrefix(s, DeletionMarker+"="), ";")
+	at, err := time.Parse(time.RFC3339, fields[0])
+	if err != nil {
+		return nil, false
+	}
+	mark := &Mark{At: at}
+	for _, c := range fields[1:] {
+		if c != "" {
+			mark.Criteria = append(mark.Criteria, c)
+		}
+	}
+	return mark, true
+}
+
+func markTag(at time.Time, criteria []string) string {
+	tag := DeletionMarker + "=" + at.UTC().Format(time.RFC3339)
+	for _, c := range criteria {
+		tag += ";" + c
+	}
+	return tag
+}
+
+func validCriteria(criteria []string) error {
+	for _, c := range criteria {
+		if c == "" || strings.ContainsAny(c, " \t\n;,/") {
+			return fmt.Errorf("invalid mark criterion %q", c)
+		}
+	}
+	return nil
+}
+
+// withoutMarks returns the words or tags which are no DeletionMarker.
+func withoutMarks(words []string) []string {
+	result := []string{}
+	for _, w := range words {
+		if !strings.HasPrefix(w, DeletionMarker+"=") {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+// descriptionWithoutMarks removes every mark and the blank before it from a
+// description, the rest is kept as is.
+func descriptionWithoutMarks(description string) string {
+	for _, word := range strings.Fields(description) {
+		if _, ok := parseMarkTag(word); ok {
+			i := strings.Index(description, word)
+			start := i
+			if start > 0 && description[start-1] == ' ' {
+				start--
+			}
+			description = description[:start] + description[i+len(word):]
+		}
+	}
+	return description
+}
+
+// taggedLoadBalancer is a LoadBalancer as returned by APIs supporting tags.
+// Tags is nil if the API does not support them.
+type taggedLoadBalancer struct {
+	Description string    `json:"description"`
+	Tags        *[]string `json:"tags"`
+}
+
+func (o *openstackprovider) getTagged(id string) (*taggedLoadBalancer, error) {
+	var body struct {
+		LoadBalancer taggedLoadBalancer `json:"loadbalancer"`
+	}
+	if _, err := o.networkClient.Get(o.networkClient.ServiceURL("lbaas", "loadbalancers", id), &body, nil); err != nil {
+		return nil, wrapf(err, "failed to get loadbalancer %s", id)
+	}
+	return &body.LoadBalancer, nil
+}
+
+// setTags replaces the tags of a LoadBalancer, with the Octavia API as part
+// of the LoadBalancer, with the Neutron API by its tags resource.
+func (o *openstackprovider) setTags(id string, tags []string) error {
+	opts := &gophercloud.RequestOpts{OkCodes: []int{200, 201, 202}}
+	if o.octavia {
+		body := map[string]interface{}{"loadbalancer": map[string]interface{}{"tags": tags}}
+		_, err := o.networkClient.Put(o.networkClient.ServiceURL("lbaas", "loadbalancers", id), body, nil, opts)
+		return err
+	}
+	_, err := o.networkClient.Put(o.networkClient.ServiceURL("lbaas", "loadbalancers", id, "tags"), map[string]interface{}{"tags": tags}, nil, opts)
+	return err
+}
+
+func (o *openstackprovider) setDescription(id, description string) error {
+	return loadbalancers.Update(o.networkClient, id, loadbalancers.UpdateOpts{Description: &description}).Err
+}
+
+// GetLoadBalancerMark returns when a LoadBalancer was marked for deletion, or
+// nil if it is not marked.
+func (o *openstackprovider) GetLoadBalancerMark(ctx context.Context, id string) (*Mark, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	lb, err := o.getTagged(id)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	if lb.Tags != nil {
+		tags = *lb.Tags
+	}
+	mark, _ := ParseMark(lb.Description, tags)
+	return mark, nil
+}
+
+// MarkLoadBalancer marks a LoadBalancer for deletion at the given time with
+// a tag, or in its description if the API does not support tags or Neutron
+// would reject the tag as too long. The criteria are stored in the mark. A
+// LoadBalancer which is already marked keeps its mark, which is returned.
+func (o *openstackprovider) MarkLoadBalancer(ctx context.Context, id string, at time.Time, criteria []string) (*Mark, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	if err := validCriteria(criteria); err != nil {
+		return nil, err
+	}
+	tag := markTag(at, criteria)
+	lb, err := o.getTagged(id)
+	if err != nil {
+		return nil, err
+	}
+	if lb.Tags != nil {
+		if mark, ok := ParseMark(lb.Description, *lb.Tags); ok {
+			return mark, nil
+		}
+		if o.octavia || len(tag) <= maxNeutronTag {
+			tags := append(withoutMarks(*lb.Tags), tag)
+			err := o.stepf("tag loadbalancer %s with %s\n", id, tag)(func() error {
+				return o.setTags(id, tags)
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &Mark{At: at, Criteria: criteria}, nil
+		}
+	}
+
+	if mark, ok := ParseMark(lb.Description, nil); ok {
+		return mark, nil
+	}
+	description := tag
+	if lb.Description != "" {
+		description = lb.Description + " " + description
+	}
+	if len(description) > maxDescription {
+		return nil, fmt.Errorf("failed to mark loadbalancer %s: the mark fits neither in a tag nor in the description", id)
+	}
+	err = o.stepf("add %s to the description of loadbalancer %s\n", tag, id)(func() error {
+		return o.setDescription(id, description)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Mark{At: at, Criteria: criteria, InDescription: true}, nil
+}
+
+// UnmarkLoadBalancer removes the mark of a LoadBalancer from its tags and its
+// description.
+func (o *openstackprovider) UnmarkLoadBalancer(ctx context.Context, id string) error {
+	if err := interrupted(ctx); err != nil {
+		return err
+	}
+	lb, err := o.getTagged(id)
+	if err != nil {
+		return err
+	}
+	if lb.Tags != nil {
+		if tags := withoutMarks(*lb.Tags); len(tags) != len(*lb.Tags) {
+			err := o.stepf("remove %s tag of loadbalancer %s\n", DeletionMarker, id)(func() error {
+				return o.setTags(id, tags)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if description := descriptionWithoutMarks(lb.Description); description != lb.Description {
+		return o.stepf("remove %s from the description of loadbalancer %s\n", DeletionMarker, id)(func() error {
+			return o.setDescription(id, description)
+		})
+	}
+	return nil
+}
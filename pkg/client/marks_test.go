@@ -0,0 +1,111 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/fakecloud"
+)
+
+// longCriterion is a criterion like the base64 encoded --name-pattern.
+const longCriterion = "name=Xmtld2Jfc2VydmljZV9wcm9kXy4qJA"
+
+func TestMarkLoadBalancer(t *testing.T) {
+	at := time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name          string
+		tags          bool
+		octavia       bool
+		criteria      []string
+		inDescription bool
+	}{
+		{name: "octavia tags", tags: true, octavia: true, criteria: []string{"empty", "status=ERROR", longCriterion}},
+		{name: "neutron tags", tags: true, criteria: []string{"empty"}},
+		// Neutron limits tags to 60 characters
+		{name: "neutron tags with long criteria", tags: true, criteria: []string{"empty", "status=ERROR"}, inDescription: true},
+		{name: "neutron tags with a name criterion", tags: true, criteria: []string{longCriterion}, inDescription: true},
+		{name: "description", criteria: []string{"empty", "status=ERROR"}, inDescription: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := fakecloud.NewServer()
+			defer s.Close()
+			s.PendingTicks = 0
+			s.Tags = tc.tags
+			lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web", Description: "shop frontend"})
+			opts := s.AuthOptions()
+			p, err := client.NewOpenStackProvider(client.Config{AuthOptions: &opts, Octavia: tc.octavia})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+
+			criteria := tc.criteria
+			mark, err := p.MarkLoadBalancer(ctx, lb.ID, at, criteria)
+			if err != nil {
+				t.Fatalf("mark failed: %s", err)
+			}
+			if !mark.At.Equal(at) || mark.InDescription != tc.inDescription {
+				t.Errorf("got mark %+v", mark)
+			}
+			// a second mark keeps the first
+			if mark, err = p.MarkLoadBalancer(ctx, lb.ID, at.Add(time.Hour), nil); err != nil || !mark.At.Equal(at) {
+				t.Errorf("got mark %+v, %v after marking again", mark, err)
+			}
+			if mark, err = p.GetLoadBalancerMark(ctx, lb.ID); err != nil || mark == nil || !mark.At.Equal(at) || mark.InDescription != tc.inDescription || !reflect.DeepEqual(mark.Criteria, criteria) {
+				t.Errorf("got mark %+v, %v", mark, err)
+			}
+			if _, err := p.MarkLoadBalancer(ctx, lb.ID, at, []string{"name=a/b"}); err == nil {
+				t.Errorf("invalid criteria were accepted")
+			}
+
+			if err := p.UnmarkLoadBalancer(ctx, lb.ID); err != nil {
+				t.Fatalf("unmark failed: %s", err)
+			}
+			if mark, err = p.GetLoadBalancerMark(ctx, lb.ID); err != nil || mark != nil {
+				t.Errorf("got mark %+v, %v after unmarking", mark, err)
+			}
+			lbs, err := p.ListLBaaS(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lbs[0].Description != "shop frontend" {
+				t.Errorf("got description %q after unmarking", lbs[0].Description)
+			}
+		})
+	}
+}
+
+func TestParseMark(t *testing.T) {
+	at := time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC)
+	if mark, ok := client.ParseMark("", []string{"team=shop", "oli-marked-for-deletion=2018-10-15T10:00:00Z"}); !ok || !mark.At.Equal(at) || mark.InDescription {
+		t.Errorf("got mark %+v from tags", mark)
+	}
+	if mark, ok := client.ParseMark("shop oli-marked-for-deletion=2018-10-15T10:00:00Z", nil); !ok || !mark.At.Equal(at) || !mark.InDescription {
+		t.Errorf("got mark %+v from the description", mark)
+	}
+	if mark, ok := client.ParseMark("", []string{"oli-marked-for-deletion=2018-10-15T10:00:00Z;empty;status=ERROR"}); !ok || !reflect.DeepEqual(mark.Criteria, []string{"empty", "status=ERROR"}) {
+		t.Errorf("got mark %+v with criteria", mark)
+	}
+	if _, ok := client.ParseMark("oli-marked-for-deletion=yesterday", []string{"oli-marked-for-deletion"}); ok {
+		t.Errorf("invalid marks were parsed")
+	}
+}
@@ -15,9 +15,14 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
 
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
@@ -26,88 +31,212 @@ import (
 	"github.com/gophercloud/gophercloud/openstack"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+
+	"github.com/afritzler/oli/pkg/spec"
 )
 
 type OpenStackProvider interface {
-	ListLBaaSIDs() ([]string, error)
-	ListLBaaS() ([]loadbalancers.LoadBalancer, error)
-	ListListenersForCurrentTenant() ([]listeners.Listener, error)
-	ListMonitorsForCurrentTenant() ([]monitors.Monitor, error)
-	GetPoolsForCurrentTenant() ([]pools.Pool, error)
-	GetListenersForLoadbalancerID(loadbalancerid string) ([]listeners.Listener, error)
-	GetPoolsForListenerID(loadbalancerid string, listenerid string) ([]pools.Pool, error)
-	GetMonitorsForPoolID(poolid string) ([]monitors.Monitor, error)
-	GetPoolIDsForCurrentTenant() ([]string, error)
-	GetMembersForPoolID(poolid string) ([]pools.Member, error)
-	DeleteLoadBalancer(id string) error
+	ListLBaaSIDs(ctx context.Context) ([]string, error)
+	ListLBaaS(ctx context.Context) ([]loadbalancers.LoadBalancer, error)
+	ListListenersForCurrentTenant(ctx context.Context) ([]listeners.Listener, error)
+	ListMonitorsForCurrentTenant(ctx context.Context) ([]monitors.Monitor, error)
+	GetPoolsForCurrentTenant(ctx context.Context) ([]pools.Pool, error)
+	GetListenersForLoadbalancerID(ctx context.Context, loadbalancerid string) ([]listeners.Listener, error)
+	GetPoolsForListenerID(ctx context.Context, loadbalancerid string, listenerid string) ([]pools.Pool, error)
+	GetMonitorsForPoolID(ctx context.Context, poolid string) ([]monitors.Monitor, error)
+	GetPoolIDsForCurrentTenant(ctx context.Context) ([]string, error)
+	GetMembersForPoolID(ctx context.Context, poolid string) ([]pools.Member, error)
+	GetPoolsForLoadbalancerID(ctx context.Context, loadbalancerid string) ([]pools.Pool, error)
+	ListL7PoliciesForCurrentTenant(ctx context.Context) ([]l7policies.L7Policy, error)
+	GetL7PoliciesForListenerID(ctx context.Context, listenerid string) ([]l7policies.L7Policy, error)
+	PlanLoadBalancerDeletion(ctx context.Context, id string) (PlanEntry, error)
+	DeletePlanEntry(ctx context.Context, entry PlanEntry, opts DeleteOptions) (*DeleteReport, error)
+	DeleteLoadBalancer(ctx context.Context, id string) (*DeleteReport, error)
+	GetLoadBalancerStatuses(ctx context.Context, id string) (*loadbalancers.StatusTree, error)
+	GetLoadBalancerStats(ctx context.Context, id string) (*loadbalancers.Stats, error)
+	GetLoadBalancerMark(ctx context.Context, id string) (*Mark, error)
+	MarkLoadBalancer(ctx context.Context, id string, at time.Time, criteria []string) (*Mark, error)
+	UnmarkLoadBalancer(ctx context.Context, id string) error
+	ExportLoadBalancer(ctx context.Context, id string) (*spec.Spec, error)
+	RestoreLoadBalancer(ctx context.Context, s *spec.Spec, opts RestoreOptions) (*RestoreReport, error)
 }
 
 type openstackprovider struct {
 	opts          *gophercloud.AuthOptions
 	provider      *gophercloud.ProviderClient
 	networkClient *gophercloud.ServiceClient
+	region        string
+	allProjects   bool
+	octavia       bool
 	dryrun        bool
+	pollInterval  time.Duration
+	timeout       time.Duration
 }
 
 type Config struct {
 	DryRun bool
+	// PollInterval is the time between two status checks while waiting for
+	// an object. Defaults to 2 seconds.
+	PollInterval time.Duration
+	// Timeout is the maximum time to wait for an object to settle or
+	// disappear. Defaults to 5 minutes.
+	Timeout time.Duration
+	// WrapTransport, if set, wraps the HTTP transport of all API calls,
+	// including authentication.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+	// AuthOptions, if set, are used instead of the OS_* environment
+	// variables.
+	AuthOptions *gophercloud.AuthOptions
+	// Region, if set, is used instead of OS_REGION_NAME.
+	Region string
+	// Octavia selects the Octavia endpoint (service type load-balancer)
+	// instead of the Neutron LBaaS v2 API of the network endpoint.
+	Octavia bool
+	// AllProjects lists the objects of all projects instead of the current
+	// one. This usually needs admin rights.
+	AllProjects bool
 }
 
 func NewDefaultOpenStackProvider() (OpenStackProvider, error) {
 	return NewOpenStackProvider(Config{})
 }
 
-func NewOpenStackProvider(config Config) (OpenStackProvider, error) {
+// AuthOptionsFromEnv reads the credentials from the OS_* environment
+// variables.
+func AuthOptionsFromEnv() (gophercloud.AuthOptions, error) {
 	opts, err := openstack.AuthOptionsFromEnv()
 	opts.DomainName = os.Getenv("OS_USER_DOMAIN_NAME")
-	fmt.Println("============")
-	fmt.Printf("| OpenStack Client\n")
-	fmt.Printf("| auth_url: %s\n", opts.IdentityEndpoint)
-	fmt.Printf("| domain_name: %s\n", opts.DomainName)
-	fmt.Printf("| tenant_name: %s (id: %s)\n", opts.TenantName, opts.TenantID)
-	fmt.Printf("| user_name: %s\n", opts.Username)
-	fmt.Println("============")
+	return opts, err
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to get auth opts from environment %s", err)
+func NewOpenStackProvider(config Config) (OpenStackProvider, error) {
+	var opts gophercloud.AuthOptions
+	var err error
+	if config.AuthOptions != nil {
+		opts = *config.AuthOptions
+	} else {
+		opts, err = AuthOptionsFromEnv()
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("OS_REGION_NAME")
 	}
-	provider, err := openstack.AuthenticatedClient(opts)
+	// the banner goes to stderr, so that it never mixes with JSON output
+	fmt.Fprintln(os.Stderr, "============")
+	fmt.Fprintf(os.Stderr, "| OpenStack Client\n")
+	fmt.Fprintf(os.Stderr, "| auth_url: %s\n", opts.IdentityEndpoint)
+	fmt.Fprintf(os.Stderr, "| domain_name: %s\n", opts.DomainName)
+	fmt.Fprintf(os.Stderr, "| tenant_name: %s (id: %s)\n", opts.TenantName, opts.TenantID)
+	fmt.Fprintf(os.Stderr, "| user_name: %s\n", opts.Username)
+	fmt.Fprintln(os.Stderr, "============")
+
 	if err != nil {
-		return nil, fmt.Errorf("failed to get authenticated client %s", err)
+		return nil, wrapf(err, "failed to get auth opts from environment")
 	}
-	networkClient, err := openstack.NewNetworkV2(provider, gophercloud.EndpointOpts{
-		Region: os.Getenv("OS_REGION_NAME"),
-	})
+	// long running commands like serve and janitor outlive their token
+	opts.AllowReauth = true
+	provider, err := openstack.NewClient(opts.IdentityEndpoint)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get network client %s", err)
+		return nil, wrapf(err, "failed to create provider client")
+	}
+	if config.WrapTransport != nil {
+		provider.HTTPClient.Transport = config.WrapTransport(http.DefaultTransport)
+	}
+	if err := openstack.Authenticate(provider, opts); err != nil {
+		return nil, wrapf(err, "failed to get authenticated client")
+	}
+	var networkClient *gophercloud.ServiceClient
+	if config.Octavia {
+		networkClient, err = openstack.NewLoadBalancerV2(provider, gophercloud.EndpointOpts{
+			Region: config.Region,
+		})
+		if err != nil {
+			return nil, wrapf(err, "failed to get octavia client")
+		}
+	} else {
+		networkClient, err = openstack.NewNetworkV2(provider, gophercloud.EndpointOpts{
+			Region: config.Region,
+		})
+		if err != nil {
+			return nil, wrapf(err, "failed to get network client")
+		}
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = 2 * time.Second
 	}
-	return &openstackprovider{opts: &opts, provider: provider, networkClient: networkClient, dryrun: config.DryRun}, nil
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Minute
+	}
+	return &openstackprovider{
+		opts:          &opts,
+		provider:      provider,
+		networkClient: networkClient,
+		region:        config.Region,
+		allProjects:   config.AllProjects,
+		octavia:       config.Octavia,
+		dryrun:        config.DryRun,
+		pollInterval:  config.PollInterval,
+		timeout:       config.Timeout,
+	}, nil
 }
 
-func (o *openstackprovider) ListLBaaS() ([]loadbalancers.LoadBalancer, error) {
-	client, err := openstack.NewNetworkV2(o.provider, gophercloud.EndpointOpts{
-		Region: os.Getenv("OS_REGION_NAME"),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create neutron client %s", err)
+// tenantID returns the project to filter lists by, or nothing to list the
+// objects of all projects.
+func (o *openstackprovider) tenantID() string {
+	if o.allProjects {
+		return ""
+	}
+	return o.opts.TenantID
+}
+
+func (o *openstackprovider) ListLBaaS(ctx context.Context) ([]loadbalancers.LoadBalancer, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
 	}
-	allPages, err := loadbalancers.List(client, loadbalancers.ListOpts{
-		TenantID: o.opts.TenantID,
+	allPages, err := loadbalancers.List(o.networkClient, loadbalancers.ListOpts{
+		TenantID: o.tenantID(),
 	}).AllPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list all loadbalancers %s", err)
+		return nil, wrapf(err, "failed to list all loadbalancers")
 	}
 	actual, err := loadbalancers.ExtractLoadBalancers(allPages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list all external loadbalancers %s", err)
+		return nil, wrapf(err, "failed to list all external loadbalancers")
 	}
 	return actual, nil
 }
 
-func (o *openstackprovider) ListLBaaSIDs() ([]string, error) {
-	lbList, err := o.ListLBaaS()
+// GetLoadBalancerStatuses returns the provisioning and operating status of a
+// LoadBalancer and everything attached to it.
+func (o *openstackprovider) GetLoadBalancerStatuses(ctx context.Context, id string) (*loadbalancers.StatusTree, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	statuses, err := loadbalancers.GetStatuses(o.networkClient, id).Extract()
+	if err != nil {
+		return nil, wrapf(err, "failed to get statuses of loadbalancer %s", id)
+	}
+	return statuses, nil
+}
+
+// GetLoadBalancerStats returns the traffic statistics of a LoadBalancer.
+func (o *openstackprovider) GetLoadBalancerStats(ctx context.Context, id string) (*loadbalancers.Stats, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	stats, err := loadbalancers.GetStats(o.networkClient, id).Extract()
+	if err != nil {
+		return nil, wrapf(err, "failed to get stats of loadbalancer %s", id)
+	}
+	return stats, nil
+}
+
+func (o *openstackprovider) ListLBaaSIDs(ctx context.Context) ([]string, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	lbList, err := o.ListLBaaS(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list all loadbalancers %s", err)
+		return nil, wrapf(err, "failed to list all loadbalancers")
 	}
 	ids := make([]string, len(lbList))
 	for idx, lb := range lbList {
@@ -116,53 +245,65 @@ func (o *openstackprovider) ListLBaaSIDs() ([]string, error) {
 	return ids, nil
 }
 
-func (o *openstackprovider) GetListenersForLoadbalancerID(loadbalancerid string) ([]listeners.Listener, error) {
+func (o *openstackprovider) GetListenersForLoadbalancerID(ctx context.Context, loadbalancerid string) ([]listeners.Listener, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
 	allPages, err := listeners.List(o.networkClient, listeners.ListOpts{
 		LoadbalancerID: loadbalancerid,
-		TenantID:       o.opts.TenantID,
+		TenantID:       o.tenantID(),
 	}).AllPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list listeners for loadbalancer id %s, %s", loadbalancerid, err)
+		return nil, wrapf(err, "failed to list listeners for loadbalancer id %s", loadbalancerid)
 	}
 	listeners, err := listeners.ExtractListeners(allPages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract listeners %s", err)
+		return nil, wrapf(err, "failed to extract listeners")
 	}
 	return listeners, nil
 }
 
-func (o *openstackprovider) ListListenersForCurrentTenant() ([]listeners.Listener, error) {
+func (o *openstackprovider) ListListenersForCurrentTenant(ctx context.Context) ([]listeners.Listener, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
 	allPages, err := listeners.List(o.networkClient, listeners.ListOpts{
-		TenantID: o.opts.TenantID,
+		TenantID: o.tenantID(),
 	}).AllPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list all listener pages %s", err)
+		return nil, wrapf(err, "failed to list all listener pages")
 	}
 	listeners, err := listeners.ExtractListeners(allPages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract all listener object %s", err)
+		return nil, wrapf(err, "failed to extract all listener object")
 	}
 	return listeners, nil
 }
 
-func (o *openstackprovider) GetPoolsForCurrentTenant() ([]pools.Pool, error) {
+func (o *openstackprovider) GetPoolsForCurrentTenant(ctx context.Context) ([]pools.Pool, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
 	allPages, err := pools.List(o.networkClient, pools.ListOpts{
-		TenantID: o.opts.TenantID,
+		TenantID: o.tenantID(),
 	}).AllPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list all pool pages %s", err)
+		return nil, wrapf(err, "failed to list all pool pages")
 	}
 	pools, err := pools.ExtractPools(allPages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract all pools from pages %s", err)
+		return nil, wrapf(err, "failed to extract all pools from pages")
 	}
 	return pools, nil
 }
 
-func (o *openstackprovider) GetPoolIDsForCurrentTenant() ([]string, error) {
-	pools, err := o.GetPoolsForCurrentTenant()
+func (o *openstackprovider) GetPoolIDsForCurrentTenant(ctx context.Context) ([]string, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	pools, err := o.GetPoolsForCurrentTenant(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list all pools %s", err)
+		return nil, wrapf(err, "failed to list all pools")
 	}
 	var ids []string
 	for _, pool := range pools {
@@ -171,76 +312,137 @@ func (o *openstackprovider) GetPoolIDsForCurrentTenant() ([]string, error) {
 	return ids, nil
 }
 
-func (o *openstackprovider) GetPoolsForListenerID(loadbalancerid string, listenerid string) ([]pools.Pool, error) {
+func (o *openstackprovider) GetPoolsForListenerID(ctx context.Context, loadbalancerid string, listenerid string) ([]pools.Pool, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
 	allPages, err := pools.List(o.networkClient, pools.ListOpts{
 		ListenerID:     listenerid,
 		LoadbalancerID: loadbalancerid,
-		TenantID:       o.opts.TenantID,
+		TenantID:       o.tenantID(),
 	}).AllPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pool pages for pool id %s, %s", listenerid, err)
+		return nil, wrapf(err, "failed to get pool pages for pool id %s", listenerid)
 	}
 	pools, err := pools.ExtractPools(allPages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract pools from pages for pool id %s, %s", listenerid, err)
+		return nil, wrapf(err, "failed to extract pools from pages for pool id %s", listenerid)
 	}
 	return pools, nil
 }
 
-func (o *openstackprovider) ListMonitorsForCurrentTenant() ([]monitors.Monitor, error) {
+func (o *openstackprovider) ListMonitorsForCurrentTenant(ctx context.Context) ([]monitors.Monitor, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
 	allPages, err := monitors.List(o.networkClient, monitors.ListOpts{
-		TenantID: o.opts.TenantID,
+		TenantID: o.tenantID(),
 	}).AllPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list monitors %s", err)
+		return nil, wrapf(err, "failed to list monitors")
 	}
 	monitors, err := monitors.ExtractMonitors(allPages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract monitor pages %s", err)
+		return nil, wrapf(err, "failed to extract monitor pages")
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to list monitors %s", err)
+		return nil, wrapf(err, "failed to list monitors")
 	}
 	return monitors, nil
 }
 
-func (o *openstackprovider) GetMonitorsForPoolID(poolid string) ([]monitors.Monitor, error) {
+func (o *openstackprovider) GetMonitorsForPoolID(ctx context.Context, poolid string) ([]monitors.Monitor, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
 	allPages, err := monitors.List(o.networkClient, monitors.ListOpts{
-		TenantID: o.opts.TenantID,
+		TenantID: o.tenantID(),
 		PoolID:   poolid,
 	}).AllPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list monitors %s", err)
+		return nil, wrapf(err, "failed to list monitors")
 	}
 	monitors, err := monitors.ExtractMonitors(allPages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract monitor pages %s", err)
+		return nil, wrapf(err, "failed to extract monitor pages")
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to list monitors %s", err)
+		return nil, wrapf(err, "failed to list monitors")
 	}
 	return monitors, nil
 }
 
-func (o *openstackprovider) GetMembersForPoolID(poolid string) ([]pools.Member, error) {
+func (o *openstackprovider) GetMembersForPoolID(ctx context.Context, poolid string) ([]pools.Member, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	allPages, err := pools.ListMembers(o.networkClient, poolid, pools.ListMembersOpts{
+		TenantID: o.tenantID(),
+	}).AllPages()
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapf(err, "failed to list members of pool %s", poolid)
+	}
+	members, err := pools.ExtractMembers(allPages)
+	if err != nil {
+		return nil, wrapf(err, "failed to extract members of pool %s", poolid)
+	}
+	return members, nil
+}
+
+func (o *openstackprovider) GetPoolsForLoadbalancerID(ctx context.Context, loadbalancerid string) ([]pools.Pool, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
 	allPages, err := pools.List(o.networkClient, pools.ListOpts{
-		TenantID: o.opts.TenantID,
-		ID:       poolid,
+		LoadbalancerID: loadbalancerid,
+		TenantID:       o.tenantID(),
 	}).AllPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list pool pages %s", err)
+		return nil, wrapf(err, "failed to get pool pages for loadbalancer id %s", loadbalancerid)
 	}
 	pools, err := pools.ExtractPools(allPages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract pools from pages %s", err)
+		return nil, wrapf(err, "failed to extract pools from pages for loadbalancer id %s", loadbalancerid)
 	}
-	if len(pools) > 1 {
-		return nil, fmt.Errorf("got more than one pool for ID %s. this should never happen %s", poolid, err)
-	} else if len(pools) == 0 {
-		return nil, nil
-	} else {
-		return pools[0].Members, nil
+	return pools, nil
+}
+
+func (o *openstackprovider) ListL7PoliciesForCurrentTenant(ctx context.Context) ([]l7policies.L7Policy, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	allPages, err := l7policies.List(o.networkClient, l7policies.ListOpts{
+		TenantID: o.tenantID(),
+	}).AllPages()
+	if err != nil {
+		return nil, wrapf(err, "failed to list l7 policies")
+	}
+	policies, err := l7policies.ExtractL7Policies(allPages)
+	if err != nil {
+		return nil, wrapf(err, "failed to extract l7 policy pages")
 	}
+	return policies, nil
+}
+
+func (o *openstackprovider) GetL7PoliciesForListenerID(ctx context.Context, listenerid string) ([]l7policies.L7Policy, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	allPages, err := l7policies.List(o.networkClient, l7policies.ListOpts{
+		ListenerID: listenerid,
+		TenantID:   o.tenantID(),
+	}).AllPages()
+	if err != nil {
+		return nil, wrapf(err, "failed to list l7 policies for listener id %s", listenerid)
+	}
+	policies, err := l7policies.ExtractL7Policies(allPages)
+	if err != nil {
+		return nil, wrapf(err, "failed to extract l7 policy pages")
+	}
+	return policies, nil
 }
 
 func (o *openstackprovider) stepf(format string, args ...interface{}) func(func() error) error {
@@ -253,58 +455,227 @@ func (o *openstackprovider) stepf(format string, args ...interface{}) func(func(
 		fmt.Printf("%s%s", prefix, msg)
 
 		var err error
-		if o.dryrun {
+		if !o.dryrun {
 			err = f()
 		}
 
 		if err != nil {
-			fmt.Printf("failed to %s: %v", msg, err)
+			fmt.Printf("failed to %s: %v\n", strings.TrimSpace(msg), err)
+			return wrapf(err, "failed to %s", strings.TrimSpace(msg))
 		}
-		return err
+		return nil
 	}
 }
 
-func (o *openstackprovider) DeleteLoadBalancer(id string) error {
-	fmt.Printf("deleting loadbalancer with id %s\n", id)
-	listeners, err := o.GetListenersForLoadbalancerID(id)
+// PlanLoadBalancerDeletion queries everything attached to a LoadBalancer and
+// returns it in deletion order.
+func (o *openstackprovider) PlanLoadBalancerDeletion(ctx context.Context, id string) (PlanEntry, error) {
+	if err := interrupted(ctx); err != nil {
+		return PlanEntry{}, err
+	}
+	lb, err := loadbalancers.Get(o.networkClient, id).Extract()
 	if err != nil {
-		return fmt.Errorf("failed to get listener for loadbalancer ID %s, %s", id, err)
+		return PlanEntry{}, wrapf(err, "failed to get loadbalancer %s", id)
 	}
-	for _, listener := range listeners {
-		pools, err := o.GetPoolsForListenerID(id, listener.ID)
+	lbListeners, err := o.GetListenersForLoadbalancerID(ctx, id)
+	if err != nil {
+		return PlanEntry{}, wrapf(err, "failed to get listener for loadbalancer ID %s", id)
+	}
+	lbPools, err := o.GetPoolsForLoadbalancerID(ctx, id)
+	if err != nil {
+		return PlanEntry{}, err
+	}
+	var policies []l7policies.L7Policy
+	for _, listener := range lbListeners {
+		listenerPools, err := o.GetPoolsForListenerID(ctx, id, listener.ID)
 		if err != nil {
-			return fmt.Errorf("failed to get pool IDs for listener ID %s, %s", listener.ID, err)
+			return PlanEntry{}, wrapf(err, "failed to get pool IDs for listener ID %s", listener.ID)
 		}
-		for _, pool := range pools {
-			if pool.MonitorID != "" {
-				if err := o.stepf("delete health monitor with id %s\n", pool.MonitorID)(func() error {
-					//return monitors.Delete(o.networkClient, pool.MonitorID).Err
-					return nil
-				}); err != nil {
-					return err
-				}
-			} else {
-				fmt.Printf("no health monitor found for pool id %s\n", pool.ID)
-			}
-			if err := o.stepf("delete pool with id %s\n", pool.ID)(func() error {
-				// return pools.Delete(o.networkClient, poolID)
+		lbPools = append(lbPools, listenerPools...)
+		listenerPolicies, err := o.GetL7PoliciesForListenerID(ctx, listener.ID)
+		if err != nil {
+			return PlanEntry{}, err
+		}
+		policies = append(policies, listenerPolicies...)
+	}
+	return NewPlanEntry(*lb, lbListeners, lbPools, policies), nil
+}
+
+func (o *openstackprovider) deleteObject(obj Object) error {
+	switch obj.Kind {
+	case KindL7Policy:
+		return l7policies.Delete(o.networkClient, obj.ID).Err
+	case KindHealthMonitor:
+		return monitors.Delete(o.networkClient, obj.ID).Err
+	case KindPool:
+		return pools.Delete(o.networkClient, obj.ID).Err
+	case KindListener:
+		return listeners.Delete(o.networkClient, obj.ID).Err
+	case KindLoadBalancer:
+		return loadbalancers.Delete(o.networkClient, obj.ID).Err
+	}
+	return fmt.Errorf("unsupported object kind %s", obj.Kind)
+}
+
+func (o *openstackprovider) getObject(obj Object) error {
+	switch obj.Kind {
+	case KindL7Policy:
+		return l7policies.Get(o.networkClient, obj.ID).Err
+	case KindHealthMonitor:
+		return monitors.Get(o.networkClient, obj.ID).Err
+	case KindPool:
+		return pools.Get(o.networkClient, obj.ID).Err
+	case KindListener:
+		return listeners.Get(o.networkClient, obj.ID).Err
+	case KindLoadBalancer:
+		return loadbalancers.Get(o.networkClient, obj.ID).Err
+	}
+	return fmt.Errorf("unsupported object kind %s", obj.Kind)
+}
+
+// waitForLoadBalancer waits until the LoadBalancer left any PENDING_* state,
+// since the API refuses changes to its children until then.
+func (o *openstackprovider) waitForLoadBalancer(ctx context.Context, id string) (*loadbalancers.LoadBalancer, error) {
+	deadline := time.Now().Add(o.timeout)
+	for {
+		if err := interrupted(ctx); err != nil {
+			return nil, err
+		}
+		lb, err := loadbalancers.Get(o.networkClient, id).Extract()
+		if err != nil {
+			return nil, wrapf(err, "failed to get loadbalancer %s", id)
+		}
+		if !strings.HasPrefix(lb.ProvisioningStatus, "PENDING_") {
+			return lb, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, &ConflictError{cause{msg: fmt.Sprintf("loadbalancer %s is still %s after %s", id, lb.ProvisioningStatus, o.timeout)}}
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(o.pollInterval):
+		}
+	}
+}
+
+// waitForDeletion waits until the API no longer returns the object. Once ctx
+// is done it stops waiting, the object may then still be PENDING_DELETE.
+func (o *openstackprovider) waitForDeletion(ctx context.Context, obj Object) error {
+	deadline := time.Now().Add(o.timeout)
+	for {
+		if err := interrupted(ctx); err != nil {
+			return err
+		}
+		err := o.getObject(obj)
+		if err != nil {
+			if isNotFound(err) {
 				return nil
-			}); err != nil {
-				return err
 			}
+			return wrapf(err, "failed to get %s %s", obj.Kind, obj.ID)
 		}
-		if err := o.stepf("delete listener with id %s\n", listener.ID)(func() error {
-			// return listeners.Delete(o.networkClient, listenerID)
-			return nil
-		}); err != nil {
-			return err
+		if time.Now().After(deadline) {
+			return &ConflictError{cause{msg: fmt.Sprintf("%s %s still exists after %s", obj.Kind, obj.ID, o.timeout)}}
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(o.pollInterval):
 		}
 	}
-	if err := o.stepf("delete loadbalancer with id %s\n", id)(func() error {
-		// return loadbalancers.Delete(o.networkClient, id)
-		return nil
-	}); err != nil {
-		return err
+}
+
+// DeletePlanEntry deletes the objects of a PlanEntry in order. The state of
+// the LoadBalancer is re-checked before every step, objects which are already
+// gone are skipped. Once a step failed, the remaining objects are skipped.
+// The returned report is complete even if an error is returned.
+func (o *openstackprovider) DeletePlanEntry(ctx context.Context, entry PlanEntry, opts DeleteOptions) (*DeleteReport, error) {
+	id := entry.LoadBalancerID
+	fmt.Printf("deleting loadbalancer with id %s\n", id)
+	report := &DeleteReport{LoadBalancerID: id, DryRun: o.dryrun}
+	fail := func(obj Object, err error) {
+		report.Failed = append(report.Failed, Failure{Object: obj, Err: err, Body: apiErrorBody(err)})
+	}
+
+	lb, err := o.waitForLoadBalancer(ctx, id)
+	if _, ok := err.(*InterruptedError); ok {
+		report.Skipped = entry.Steps
+		report.Interrupted = true
+		return report, err
+	} else if _, ok := err.(*NotFoundError); ok {
+		fmt.Printf("loadbalancer with id %s is already gone\n", id)
+		report.Skipped = entry.Steps
+		return report, nil
+	} else if err != nil {
+		fail(Object{Kind: KindLoadBalancer, ID: id}, err)
+		report.Skipped = entry.Steps
+		return report, err
+	}
+	if entry.Protected || IsProtected(lb.Description) {
+		fmt.Printf("loadbalancer %s is protected by %q, skipping\n", id, ProtectionMarker)
+		report.Protected = entry.Steps
+		return report, nil
+	}
+
+	var firstErr error
+	for _, obj := range entry.Steps {
+		if firstErr == nil {
+			// a running API call is always finished, but no new step is started
+			if err := interrupted(ctx); err != nil {
+				report.Interrupted = true
+				firstErr = err
+			}
+		}
+		if firstErr != nil || opts.Done[obj.ID] {
+			report.Skipped = append(report.Skipped, obj)
+			continue
+		}
+		err := o.stepf("delete %s with id %s\n", obj.Kind, obj.ID)(func() error {
+			if _, err := o.waitForLoadBalancer(ctx, id); err != nil {
+				return err
+			}
+			if err := o.deleteObject(obj); err != nil {
+				return err
+			}
+			return o.waitForDeletion(ctx, obj)
+		})
+		switch err.(type) {
+		case nil:
+			report.Deleted = append(report.Deleted, obj)
+			if opts.OnDeleted != nil && !o.dryrun {
+				if err := opts.OnDeleted(id, obj); err != nil {
+					fail(obj, err)
+					firstErr = err
+				}
+			}
+		case *NotFoundError:
+			fmt.Printf("%s with id %s is already gone\n", obj.Kind, obj.ID)
+			report.Skipped = append(report.Skipped, obj)
+		case *InterruptedError:
+			report.Skipped = append(report.Skipped, obj)
+			report.Interrupted = true
+			firstErr = err
+		default:
+			fail(obj, err)
+			firstErr = err
+		}
+	}
+	if firstErr == nil || len(report.Deleted) == 0 || report.Interrupted {
+		return report, firstErr
+	}
+	deleted := make([]string, len(report.Deleted))
+	for i, obj := range report.Deleted {
+		deleted[i] = obj.ID
+	}
+	return report, &PartialDeleteError{cause: cause{msg: fmt.Sprintf("failed to delete loadbalancer %s", id), err: firstErr}, Deleted: deleted}
+}
+
+// DeleteLoadBalancer deletes a LoadBalancer and everything attached to it.
+func (o *openstackprovider) DeleteLoadBalancer(ctx context.Context, id string) (*DeleteReport, error) {
+	entry, err := o.PlanLoadBalancerDeletion(ctx, id)
+	if err != nil {
+		err = wrapf(err, "failed to plan deletion of loadbalancer %s", id)
+		report := &DeleteReport{LoadBalancerID: id, DryRun: o.dryrun}
+		report.Failed = append(report.Failed, Failure{Object: Object{Kind: KindLoadBalancer, ID: id}, Err: err, Body: apiErrorBody(err)})
+		return report, err
 	}
-	return nil
+	return o.DeletePlanEntry(ctx, entry, DeleteOptions{})
 }
@@ -0,0 +1,223 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/fakecloud"
+)
+
+func newProvider(t *testing.T, s *fakecloud.Server, dryRun bool) client.OpenStackProvider {
+	opts := s.AuthOptions()
+	p, err := client.NewOpenStackProvider(client.Config{
+		DryRun:       dryRun,
+		PollInterval: time.Millisecond,
+		Timeout:      5 * time.Second,
+		AuthOptions:  &opts,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %s", err)
+	}
+	return p
+}
+
+// seedLoadBalancer adds a LoadBalancer with a listener, a pool with a member
+// and a monitor, and an L7 policy. All IDs are derived from name.
+func seedLoadBalancer(s *fakecloud.Server, name string) loadbalancers.LoadBalancer {
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{ID: name, Name: name})
+	listener := s.AddListener(lb.ID, listeners.Listener{ID: name + "-listener", Protocol: "HTTP", ProtocolPort: 80})
+	pool := s.AddPool(lb.ID, listener.ID, pools.Pool{ID: name + "-pool", Protocol: "HTTP", LBMethod: "ROUND_ROBIN"})
+	s.AddMember(pool.ID, pools.Member{ID: name + "-member", Address: "10.1.0.5", ProtocolPort: 8080})
+	s.AddMonitor(pool.ID, monitors.Monitor{ID: name + "-monitor", Type: "HTTP", Delay: 5, Timeout: 3, MaxRetries: 3})
+	s.AddL7Policy(listener.ID, l7policies.L7Policy{ID: name + "-policy", Action: "REJECT"})
+	return lb
+}
+
+func TestListLBaaSFollowsPagination(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	s.PageSize = 2
+	var want []string
+	for i := 0; i < 5; i++ {
+		want = append(want, s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: fmt.Sprintf("lb-%d", i)}).ID)
+	}
+
+	lbs, err := newProvider(t, s, true).ListLBaaS(context.Background())
+	if err != nil {
+		t.Fatalf("ListLBaaS failed: %s", err)
+	}
+	var got []string
+	for _, lb := range lbs {
+		got = append(got, lb.ID)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got loadbalancers %v, want %v", got, want)
+	}
+	pages := 0
+	for _, req := range s.Requests() {
+		if strings.HasPrefix(req, "GET /v2.0/lbaas/loadbalancers") {
+			pages++
+		}
+	}
+	if pages != 3 {
+		t.Errorf("got %d page requests, want 3", pages)
+	}
+}
+
+func TestDeleteLoadBalancer(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	lb := seedLoadBalancer(s, "web")
+	other := seedLoadBalancer(s, "db")
+
+	report, err := newProvider(t, s, false).DeleteLoadBalancer(context.Background(), lb.ID)
+	if err != nil {
+		t.Fatalf("DeleteLoadBalancer failed: %s", err)
+	}
+	want := []client.Object{
+		{Kind: client.KindL7Policy, ID: "web-policy"},
+		{Kind: client.KindHealthMonitor, ID: "web-monitor"},
+		{Kind: client.KindPool, ID: "web-pool"},
+		{Kind: client.KindListener, ID: "web-listener"},
+		{Kind: client.KindLoadBalancer, ID: "web"},
+	}
+	if !reflect.DeepEqual(report.Deleted, want) {
+		t.Errorf("got deleted %v, want %v", report.Deleted, want)
+	}
+	for _, id := range []string{"web", "web-listener", "web-pool", "web-member", "web-monitor", "web-policy"} {
+		if s.Exists(id) {
+			t.Errorf("%s still exists", id)
+		}
+	}
+	if !s.Exists(other.ID) || s.Count(fakecloud.Members) != 1 {
+		t.Errorf("objects of another loadbalancer were deleted")
+	}
+}
+
+func TestDeleteLoadBalancerConflict(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	lb := seedLoadBalancer(s, "web")
+	s.InjectError(http.MethodDelete, "/listeners/", http.StatusConflict,
+		`{"faultcode": "Client", "faultstring": "Listener is in use"}`)
+
+	report, err := newProvider(t, s, false).DeleteLoadBalancer(context.Background(), lb.ID)
+	partial, ok := err.(*client.PartialDeleteError)
+	if !ok {
+		t.Fatalf("got error %#v, want a PartialDeleteError", err)
+	}
+	if want := []string{"web-policy", "web-monitor", "web-pool"}; !reflect.DeepEqual(partial.Deleted, want) {
+		t.Errorf("got deleted %v, want %v", partial.Deleted, want)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].ID != "web-listener" {
+		t.Fatalf("got failures %v, want the listener", report.Failed)
+	}
+	if !strings.Contains(report.Failed[0].Body, "Listener is in use") {
+		t.Errorf("got body %q, want the API response", report.Failed[0].Body)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].ID != lb.ID {
+		t.Errorf("got skipped %v, want the loadbalancer", report.Skipped)
+	}
+	if !s.Exists(lb.ID) || !s.Exists("web-listener") {
+		t.Errorf("loadbalancer or listener were deleted")
+	}
+}
+
+func TestDeleteProtectedLoadBalancer(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	s.AddLoadBalancer(loadbalancers.LoadBalancer{ID: "web", Name: "web", Description: "shop " + client.ProtectionMarker})
+	s.AddListener("web", listeners.Listener{ID: "web-listener", Protocol: "HTTP", ProtocolPort: 80})
+
+	report, err := newProvider(t, s, false).DeleteLoadBalancer(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("DeleteLoadBalancer failed: %s", err)
+	}
+	if len(report.Deleted) != 0 || len(report.Protected) != 2 {
+		t.Errorf("got deleted %v and protected %v, want both objects protected", report.Deleted, report.Protected)
+	}
+	if !s.Exists("web") || !s.Exists("web-listener") {
+		t.Errorf("protected objects were deleted")
+	}
+}
+
+func TestDeleteLoadBalancerDryRunParity(t *testing.T) {
+	dry := fakecloud.NewServer()
+	defer dry.Close()
+	real := fakecloud.NewServer()
+	defer real.Close()
+	seedLoadBalancer(dry, "web")
+	seedLoadBalancer(real, "web")
+
+	dryReport, err := newProvider(t, dry, true).DeleteLoadBalancer(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("dry run failed: %s", err)
+	}
+	realReport, err := newProvider(t, real, false).DeleteLoadBalancer(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("delete failed: %s", err)
+	}
+	if !reflect.DeepEqual(dryReport.Deleted, realReport.Deleted) {
+		t.Errorf("dry run would delete %v, but %v were deleted", dryReport.Deleted, realReport.Deleted)
+	}
+	for _, req := range dry.Requests() {
+		if !strings.HasPrefix(req, "GET ") && !strings.HasPrefix(req, "POST /v3/auth/tokens") {
+			t.Errorf("dry run sent %s", req)
+		}
+	}
+	if dry.Count(fakecloud.LoadBalancers) != 1 || dry.Count(fakecloud.Members) != 1 {
+		t.Errorf("dry run changed the cloud")
+	}
+}
+
+func TestReauthenticate(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	s.TokenLifetime = 50 * time.Millisecond
+	seedLoadBalancer(s, "web")
+
+	p := newProvider(t, s, true)
+	time.Sleep(2 * s.TokenLifetime)
+	lbs, err := p.ListLBaaS(context.Background())
+	if err != nil {
+		t.Fatalf("ListLBaaS with an expired token failed: %s", err)
+	}
+	if len(lbs) != 1 {
+		t.Errorf("got %d loadbalancers, want 1", len(lbs))
+	}
+	tokens := 0
+	for _, req := range s.Requests() {
+		if strings.HasPrefix(req, "POST /v3/auth/tokens") {
+			tokens++
+		}
+	}
+	if tokens != 2 {
+		t.Errorf("got %d token requests, want 2", tokens)
+	}
+}
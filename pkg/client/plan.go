@@ -0,0 +1,75 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+)
+
+// Plan is a list of LoadBalancers to delete.
+type Plan struct {
+	Entries []PlanEntry `json:"entries"`
+}
+
+// PlanEntry holds the objects of a single LoadBalancer in the order they have
+// to be deleted. The LoadBalancer itself is always the last step.
+type PlanEntry struct {
+	LoadBalancerID string   `json:"loadbalancer_id"`
+	Name           string   `json:"name"`
+	Protected      bool     `json:"protected"`
+	Steps          []Object `json:"steps"`
+}
+
+// NewPlanEntry orders the objects of a LoadBalancer for deletion: L7 policies
+// first, then health monitors and their pools, then listeners and finally the
+// LoadBalancer itself. Pools referenced more than once are deleted once.
+func NewPlanEntry(lb loadbalancers.LoadBalancer, lbListeners []listeners.Listener, lbPools []pools.Pool, policies []l7policies.L7Policy) PlanEntry {
+	entry := PlanEntry{
+		LoadBalancerID: lb.ID,
+		Name:           lb.Name,
+		Protected:      IsProtected(lb.Description),
+	}
+	for _, policy := range policies {
+		entry.Steps = append(entry.Steps, Object{Kind: KindL7Policy, ID: policy.ID})
+	}
+	seen := map[string]bool{}
+	for _, pool := range lbPools {
+		if seen[pool.ID] {
+			continue
+		}
+		seen[pool.ID] = true
+		if pool.MonitorID != "" {
+			entry.Steps = append(entry.Steps, Object{Kind: KindHealthMonitor, ID: pool.MonitorID})
+		}
+		entry.Steps = append(entry.Steps, Object{Kind: KindPool, ID: pool.ID})
+	}
+	for _, listener := range lbListeners {
+		entry.Steps = append(entry.Steps, Object{Kind: KindListener, ID: listener.ID})
+	}
+	entry.Steps = append(entry.Steps, Object{Kind: KindLoadBalancer, ID: lb.ID})
+	return entry
+}
+
+// DeleteOptions tune how a PlanEntry is executed.
+type DeleteOptions struct {
+	// Done holds the IDs of objects deleted by an earlier run. They are
+	// skipped without calling the API.
+	Done map[string]bool
+	// OnDeleted is called once an object is confirmed deleted.
+	OnDeleted func(loadbalancerID string, obj Object) error
+}
@@ -0,0 +1,67 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import "strings"
+
+// ProtectionMarker protects a LoadBalancer from being deleted by oli if it is
+// part of the LoadBalancer description.
+const ProtectionMarker = "oli:protected"
+
+// Kinds of LBaaS objects handled by oli.
+const (
+	KindLoadBalancer  = "loadbalancer"
+	KindListener      = "listener"
+	KindPool          = "pool"
+	KindMember        = "member"
+	KindHealthMonitor = "health monitor"
+	KindL7Policy      = "l7 policy"
+	KindL7Rule        = "l7 rule"
+)
+
+// Object identifies a single LBaaS object.
+type Object struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+}
+
+// Failure describes an object which could not be deleted.
+type Failure struct {
+	Object
+	Err error
+	// Body is the body of the API error response, if any.
+	Body string
+}
+
+// DeleteReport records what happened to the objects of a LoadBalancer during
+// a delete run.
+type DeleteReport struct {
+	LoadBalancerID string
+	DryRun         bool
+	Deleted        []Object
+	// Skipped holds objects which were already gone or not attempted
+	// because of an earlier failure.
+	Skipped   []Object
+	Failed    []Failure
+	Protected []Object
+	// Interrupted is set if the run was stopped before all steps were done.
+	Interrupted bool
+}
+
+// IsProtected reports whether a LoadBalancer description carries the
+// ProtectionMarker.
+func IsProtected(description string) bool {
+	return strings.Contains(description, ProtectionMarker)
+}
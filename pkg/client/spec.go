@@ -0,0 +1,504 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/spec"
+)
+
+// RestoreOptions change a spec while it is restored.
+type RestoreOptions struct {
+	// VipSubnetID, if set, replaces the VIP subnet of the spec.
+	VipSubnetID string
+	// VipAddress is requested for the new LoadBalancer. If empty, the cloud
+	// picks one.
+	VipAddress string
+	// MemberAddresses maps member addresses of the spec to new ones.
+	// Addresses which are not in the map are kept.
+	MemberAddresses map[string]string
+	// MemberSubnetID, if set, replaces the subnet of all members.
+	MemberSubnetID string
+}
+
+// IDMapping maps an object of a spec to the object restored from it.
+type IDMapping struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name,omitempty"`
+	OldID string `json:"old_id"`
+	NewID string `json:"new_id"`
+}
+
+// RestoreReport lists the objects created by a restore, in order.
+type RestoreReport struct {
+	LoadBalancerID string      `json:"loadbalancer_id"`
+	Mapping        []IDMapping `json:"mapping"`
+}
+
+// ExportLoadBalancer describes a LoadBalancer and everything attached to it
+// as a spec.
+func (o *openstackprovider) ExportLoadBalancer(ctx context.Context, id string) (*spec.Spec, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	lb, err := loadbalancers.Get(o.networkClient, id).Extract()
+	if err != nil {
+		return nil, wrapf(err, "failed to get loadbalancer %s", id)
+	}
+	s := &spec.Spec{
+		Version:    spec.Version,
+		BackedUpAt: time.Now().UTC(),
+		Source: spec.Source{
+			AuthURL:   o.opts.IdentityEndpoint,
+			Region:    o.region,
+			ProjectID: o.opts.TenantID,
+		},
+		LoadBalancer: spec.LoadBalancer{
+			ID:           lb.ID,
+			Name:         lb.Name,
+			Description:  lb.Description,
+			VipSubnetID:  lb.VipSubnetID,
+			VipAddress:   lb.VipAddress,
+			Provider:     lb.Provider,
+			Flavor:       lb.Flavor,
+			AdminStateUp: lb.AdminStateUp,
+		},
+	}
+
+	lbListeners, err := o.GetListenersForLoadbalancerID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	listenerIDs := map[string]bool{}
+	for _, l := range lbListeners {
+		listenerIDs[l.ID] = true
+		ls := spec.Listener{
+			ID:                     l.ID,
+			Name:                   l.Name,
+			Description:            l.Description,
+			Protocol:               l.Protocol,
+			ProtocolPort:           l.ProtocolPort,
+			ConnectionLimit:        l.ConnLimit,
+			DefaultTLSContainerRef: l.DefaultTlsContainerRef,
+			SNIContainerRefs:       l.SniContainerRefs,
+			DefaultPoolID:          l.DefaultPoolID,
+			AdminStateUp:           l.AdminStateUp,
+		}
+		policies, err := o.GetL7PoliciesForListenerID(ctx, l.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range policies {
+			ps := spec.L7Policy{
+				ID:             p.ID,
+				Name:           p.Name,
+				Description:    p.Description,
+				Action:         p.Action,
+				Position:       p.Position,
+				RedirectPoolID: p.RedirectPoolID,
+				RedirectURL:    p.RedirectURL,
+				AdminStateUp:   p.AdminStateUp,
+			}
+			allPages, err := l7policies.ListRules(o.networkClient, p.ID, l7policies.ListRulesOpts{}).AllPages()
+			if err != nil {
+				return nil, wrapf(err, "failed to list rules of l7 policy %s", p.ID)
+			}
+			rules, err := l7policies.ExtractRules(allPages)
+			if err != nil {
+				return nil, wrapf(err, "failed to extract rules of l7 policy %s", p.ID)
+			}
+			for _, r := range rules {
+				ps.Rules = append(ps.Rules, spec.Rule{
+					ID:           r.ID,
+					Type:         r.RuleType,
+					CompareType:  r.CompareType,
+					Key:          r.Key,
+					Value:        r.Value,
+					Invert:       r.Invert,
+					AdminStateUp: r.AdminStateUp,
+				})
+			}
+			ls.L7Policies = append(ls.L7Policies, ps)
+		}
+		s.LoadBalancer.Listeners = append(s.LoadBalancer.Listeners, ls)
+	}
+
+	lbPools, err := o.GetPoolsForLoadbalancerID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, listener := range lbListeners {
+		listenerPools, err := o.GetPoolsForListenerID(ctx, id, listener.ID)
+		if err != nil {
+			return nil, wrapf(err, "failed to get pool IDs for listener ID %s", listener.ID)
+		}
+		lbPools = append(lbPools, listenerPools...)
+	}
+	seen := map[string]bool{}
+	for _, p := range lbPools {
+		if seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		ps := spec.Pool{
+			ID:           p.ID,
+			Name:         p.Name,
+			Description:  p.Description,
+			Protocol:     p.Protocol,
+			LBMethod:     p.LBMethod,
+			AdminStateUp: p.AdminStateUp,
+		}
+		for _, l := range p.Listeners {
+			if listenerIDs[l.ID] {
+				ps.ListenerID = l.ID
+				break
+			}
+		}
+		if p.Persistence.Type != "" {
+			ps.Persistence = &spec.Persistence{Type: p.Persistence.Type, CookieName: p.Persistence.CookieName}
+		}
+		members, err := o.GetMembersForPoolID(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			ps.Members = append(ps.Members, spec.Member{
+				ID:           m.ID,
+				Name:         m.Name,
+				Address:      m.Address,
+				ProtocolPort: m.ProtocolPort,
+				Weight:       m.Weight,
+				SubnetID:     m.SubnetID,
+				AdminStateUp: m.AdminStateUp,
+			})
+		}
+		if p.MonitorID != "" {
+			m, err := monitors.Get(o.networkClient, p.MonitorID).Extract()
+			if err != nil {
+				return nil, wrapf(err, "failed to get health monitor %s", p.MonitorID)
+			}
+			ps.Monitor = &spec.Monitor{
+				ID:            m.ID,
+				Name:          m.Name,
+				Type:          m.Type,
+				Delay:         m.Delay,
+				Timeout:       m.Timeout,
+				MaxRetries:    m.MaxRetries,
+				HTTPMethod:    m.HTTPMethod,
+				URLPath:       m.URLPath,
+				ExpectedCodes: m.ExpectedCodes,
+				AdminStateUp:  m.AdminStateUp,
+			}
+		}
+		s.LoadBalancer.Pools = append(s.LoadBalancer.Pools, ps)
+	}
+	return s, nil
+}
+
+// RestoreLoadBalancer creates a new LoadBalancer with everything attached to
+// it from a spec. Every object is waited for until it is ACTIVE before the
+// next one is created. On error the report lists the objects created so far.
+func (o *openstackprovider) RestoreLoadBalancer(ctx context.Context, s *spec.Spec, opts RestoreOptions) (*RestoreReport, error) {
+	if o.dryrun {
+		return nil, fmt.Errorf("restoring a loadbalancer is not supported in dry run mode")
+	}
+	report := &RestoreReport{}
+	mapped := map[string]string{}
+	// created records an object and waits until it and its LoadBalancer
+	// are ACTIVE.
+	created := func(kind, name, oldID, newID string, status func() (string, error)) error {
+		mapped[oldID] = newID
+		report.Mapping = append(report.Mapping, IDMapping{Kind: kind, Name: name, OldID: oldID, NewID: newID})
+		fmt.Printf("created %s %s (was %s)\n", kind, newID, oldID)
+		if _, err := o.waitForLoadBalancer(ctx, report.LoadBalancerID); err != nil {
+			return err
+		}
+		current, err := status()
+		if err != nil {
+			return wrapf(err, "failed to get %s %s", kind, newID)
+		}
+		if current != "ACTIVE" {
+			return &ConflictError{cause{msg: fmt.Sprintf("%s %s is %s instead of ACTIVE", kind, newID, current)}}
+		}
+		return nil
+	}
+
+	l := s.LoadBalancer
+	if err := interrupted(ctx); err != nil {
+		return report, err
+	}
+	subnetID := l.VipSubnetID
+	if opts.VipSubnetID != "" {
+		subnetID = opts.VipSubnetID
+	}
+	lb, err := loadbalancers.Create(o.networkClient, loadbalancers.CreateOpts{
+		Name:         l.Name,
+		Description:  l.Description,
+		VipSubnetID:  subnetID,
+		VipAddress:   opts.VipAddress,
+		Provider:     l.Provider,
+		Flavor:       l.Flavor,
+		AdminStateUp: boolPtr(l.AdminStateUp),
+	}).Extract()
+	if err != nil {
+		return report, wrapf(err, "failed to create loadbalancer %s", l.Name)
+	}
+	report.LoadBalancerID = lb.ID
+	if err := created(KindLoadBalancer, l.Name, l.ID, lb.ID, func() (string, error) {
+		current, err := loadbalancers.Get(o.networkClient, lb.ID).Extract()
+		if err != nil {
+			return "", err
+		}
+		return current.ProvisioningStatus, nil
+	}); err != nil {
+		return report, err
+	}
+
+	for _, ls := range l.Listeners {
+		if err := interrupted(ctx); err != nil {
+			return report, err
+		}
+		listener, err := listeners.Create(o.networkClient, listeners.CreateOpts{
+			LoadbalancerID:         lb.ID,
+			Protocol:               listeners.Protocol(ls.Protocol),
+			ProtocolPort:           ls.ProtocolPort,
+			Name:                   ls.Name,
+			Description:            ls.Description,
+			ConnLimit:              connectionLimit(ls.ConnectionLimit),
+			DefaultTlsContainerRef: ls.DefaultTLSContainerRef,
+			SniContainerRefs:       ls.SNIContainerRefs,
+			AdminStateUp:           boolPtr(ls.AdminStateUp),
+		}).Extract()
+		if err != nil {
+			return report, wrapf(err, "failed to create listener %s", ls.ID)
+		}
+		if err := created(KindListener, ls.Name, ls.ID, listener.ID, func() (string, error) {
+			current, err := listeners.Get(o.networkClient, listener.ID).Extract()
+			if err != nil {
+				return "", err
+			}
+			return current.ProvisioningStatus, nil
+		}); err != nil {
+			return report, err
+		}
+	}
+
+	for _, ps := range l.Pools {
+		if err := interrupted(ctx); err != nil {
+			return report, err
+		}
+		createOpts := pools.CreateOpts{
+			LBMethod:     pools.LBMethod(ps.LBMethod),
+			Protocol:     pools.Protocol(ps.Protocol),
+			Name:         ps.Name,
+			Description:  ps.Description,
+			AdminStateUp: boolPtr(ps.AdminStateUp),
+		}
+		if ps.ListenerID != "" {
+			createOpts.ListenerID = mapped[ps.ListenerID]
+		} else {
+			createOpts.LoadbalancerID = lb.ID
+		}
+		if ps.Persistence != nil {
+			createOpts.Persistence = &pools.SessionPersistence{Type: ps.Persistence.Type, CookieName: ps.Persistence.CookieName}
+		}
+		pool, err := pools.Create(o.networkClient, createOpts).Extract()
+		if err != nil {
+			return report, wrapf(err, "failed to create pool %s", ps.ID)
+		}
+		if err := created(KindPool, ps.Name, ps.ID, pool.ID, func() (string, error) {
+			current, err := pools.Get(o.networkClient, pool.ID).Extract()
+			if err != nil {
+				return "", err
+			}
+			return current.ProvisioningStatus, nil
+		}); err != nil {
+			return report, err
+		}
+
+		for _, ms := range ps.Members {
+			if err := interrupted(ctx); err != nil {
+				return report, err
+			}
+			address := ms.Address
+			if mappedAddress, ok := opts.MemberAddresses[address]; ok {
+				address = mappedAddress
+			}
+			memberSubnetID := ms.SubnetID
+			if opts.MemberSubnetID != "" {
+				memberSubnetID = opts.MemberSubnetID
+			}
+			member, err := pools.CreateMember(o.networkClient, pool.ID, pools.CreateMemberOpts{
+				Address:      address,
+				ProtocolPort: ms.ProtocolPort,
+				Name:         ms.Name,
+				Weight:       intPtr(ms.Weight),
+				SubnetID:     memberSubnetID,
+				AdminStateUp: boolPtr(ms.AdminStateUp),
+			}).Extract()
+			if err != nil {
+				return report, wrapf(err, "failed to create member %s", ms.ID)
+			}
+			if err := created(KindMember, ms.Name, ms.ID, member.ID, func() (string, error) {
+				current, err := pools.GetMember(o.networkClient, pool.ID, member.ID).Extract()
+				if err != nil {
+					return "", err
+				}
+				return current.ProvisioningStatus, nil
+			}); err != nil {
+				return report, err
+			}
+		}
+
+		if ms := ps.Monitor; ms != nil {
+			if err := interrupted(ctx); err != nil {
+				return report, err
+			}
+			expectedCodes := ms.ExpectedCodes
+			if expectedCodes == "" && (ms.Type == "HTTP" || ms.Type == "HTTPS") {
+				// required by gophercloud, this is the default of the cloud
+				expectedCodes = "200"
+			}
+			monitor, err := monitors.Create(o.networkClient, monitors.CreateOpts{
+				PoolID:        pool.ID,
+				Type:          ms.Type,
+				Delay:         ms.Delay,
+				Timeout:       ms.Timeout,
+				MaxRetries:    ms.MaxRetries,
+				URLPath:       ms.URLPath,
+				HTTPMethod:    ms.HTTPMethod,
+				ExpectedCodes: expectedCodes,
+				Name:          ms.Name,
+				AdminStateUp:  boolPtr(ms.AdminStateUp),
+			}).Extract()
+			if err != nil {
+				return report, wrapf(err, "failed to create health monitor %s", ms.ID)
+			}
+			if err := created(KindHealthMonitor, ms.Name, ms.ID, monitor.ID, func() (string, error) {
+				current, err := monitors.Get(o.networkClient, monitor.ID).Extract()
+				if err != nil {
+					return "", err
+				}
+				return current.ProvisioningStatus, nil
+			}); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	for _, ls := range l.Listeners {
+		// a pool created with the listener is already its default pool
+		if ls.DefaultPoolID != "" && mapped[ls.DefaultPoolID] != "" && !isListenerPool(l.Pools, ls.DefaultPoolID, ls.ID) {
+			if err := interrupted(ctx); err != nil {
+				return report, err
+			}
+			poolID := mapped[ls.DefaultPoolID]
+			if _, err := listeners.Update(o.networkClient, mapped[ls.ID], listeners.UpdateOpts{DefaultPoolID: &poolID}).Extract(); err != nil {
+				return report, wrapf(err, "failed to set default pool of listener %s", mapped[ls.ID])
+			}
+			if _, err := o.waitForLoadBalancer(ctx, lb.ID); err != nil {
+				return report, err
+			}
+		}
+		for _, ps := range ls.L7Policies {
+			if err := interrupted(ctx); err != nil {
+				return report, err
+			}
+			policy, err := l7policies.Create(o.networkClient, l7policies.CreateOpts{
+				Name:           ps.Name,
+				ListenerID:     mapped[ls.ID],
+				Action:         l7policies.Action(ps.Action),
+				Position:       ps.Position,
+				Description:    ps.Description,
+				RedirectPoolID: mapped[ps.RedirectPoolID],
+				RedirectURL:    ps.RedirectURL,
+				AdminStateUp:   boolPtr(ps.AdminStateUp),
+			}).Extract()
+			if err != nil {
+				return report, wrapf(err, "failed to create l7 policy %s", ps.ID)
+			}
+			if err := created(KindL7Policy, ps.Name, ps.ID, policy.ID, func() (string, error) {
+				current, err := l7policies.Get(o.networkClient, policy.ID).Extract()
+				if err != nil {
+					return "", err
+				}
+				return current.ProvisioningStatus, nil
+			}); err != nil {
+				return report, err
+			}
+			for _, rs := range ps.Rules {
+				if err := interrupted(ctx); err != nil {
+					return report, err
+				}
+				rule, err := l7policies.CreateRule(o.networkClient, policy.ID, l7policies.CreateRuleOpts{
+					RuleType:     l7policies.RuleType(rs.Type),
+					CompareType:  l7policies.CompareType(rs.CompareType),
+					Value:        rs.Value,
+					Key:          rs.Key,
+					Invert:       rs.Invert,
+					AdminStateUp: boolPtr(rs.AdminStateUp),
+				}).Extract()
+				if err != nil {
+					return report, wrapf(err, "failed to create l7 rule %s", rs.ID)
+				}
+				if err := created(KindL7Rule, "", rs.ID, rule.ID, func() (string, error) {
+					current, err := l7policies.GetRule(o.networkClient, policy.ID, rule.ID).Extract()
+					if err != nil {
+						return "", err
+					}
+					return current.ProvisioningStatus, nil
+				}); err != nil {
+					return report, err
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// isListenerPool reports whether the pool was created with the listener.
+func isListenerPool(specPools []spec.Pool, poolID, listenerID string) bool {
+	for _, p := range specPools {
+		if p.ID == poolID {
+			return p.ListenerID == listenerID
+		}
+	}
+	return false
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// connectionLimit leaves an unset limit to the cloud's default.
+func connectionLimit(limit int) *int {
+	if limit == 0 {
+		return nil
+	}
+	return &limit
+}
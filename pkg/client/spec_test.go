@@ -0,0 +1,158 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/spec"
+)
+
+// clearIDs removes all IDs from a spec, so that a restored LoadBalancer can be
+// compared with the original.
+func clearIDs(s *spec.Spec) {
+	s.BackedUpAt = s.BackedUpAt.Truncate(0)
+	l := &s.LoadBalancer
+	l.ID, l.VipAddress = "", ""
+	for i := range l.Listeners {
+		ls := &l.Listeners[i]
+		ls.ID, ls.DefaultPoolID = "", ""
+		for j := range ls.L7Policies {
+			ls.L7Policies[j].ID, ls.L7Policies[j].RedirectPoolID = "", ""
+			for k := range ls.L7Policies[j].Rules {
+				ls.L7Policies[j].Rules[k].ID = ""
+			}
+		}
+	}
+	for i := range l.Pools {
+		p := &l.Pools[i]
+		p.ID, p.ListenerID = "", ""
+		for j := range p.Members {
+			p.Members[j].ID = ""
+		}
+		if p.Monitor != nil {
+			p.Monitor.ID = ""
+		}
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	s.PendingTicks = 2
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web", VipSubnetID: "subnet-1"})
+	listener := s.AddListener(lb.ID, listeners.Listener{Name: "https", Protocol: "TERMINATED_HTTPS", ProtocolPort: 443,
+		DefaultTlsContainerRef: "https://barbican/containers/1", SniContainerRefs: []string{"https://barbican/containers/2"}})
+	pool := s.AddPool(lb.ID, listener.ID, pools.Pool{Name: "backends", Protocol: "HTTP", LBMethod: "LEAST_CONNECTIONS",
+		Persistence: pools.SessionPersistence{Type: "APP_COOKIE", CookieName: "session"}})
+	s.AddMember(pool.ID, pools.Member{Name: "node-1", Address: "10.1.0.5", ProtocolPort: 8080, Weight: 3, SubnetID: "subnet-2"})
+	s.AddMonitor(pool.ID, monitors.Monitor{Name: "ping", Type: "HTTP", Delay: 5, Timeout: 3, MaxRetries: 2, URLPath: "/healthz"})
+	sorry := s.AddPool(lb.ID, "", pools.Pool{Name: "sorry", Protocol: "HTTP", LBMethod: "ROUND_ROBIN"})
+	policy := s.AddL7Policy(listener.ID, l7policies.L7Policy{Name: "maintenance", Action: "REDIRECT_TO_POOL", RedirectPoolID: sorry.ID, Position: 1})
+	s.AddL7Rule(policy.ID, l7policies.Rule{RuleType: "PATH", CompareType: "STARTS_WITH", Value: "/admin"})
+
+	osClient := newProvider(t, s, false)
+	ctx := context.Background()
+	exported, err := osClient.ExportLoadBalancer(ctx, lb.ID)
+	if err != nil {
+		t.Fatalf("ExportLoadBalancer failed: %s", err)
+	}
+	dir, err := ioutil.TempDir("", "oli-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "web.yaml")
+	if err := exported.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := spec.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := osClient.DeleteLoadBalancer(ctx, lb.ID); err != nil {
+		t.Fatalf("DeleteLoadBalancer failed: %s", err)
+	}
+
+	report, err := osClient.RestoreLoadBalancer(ctx, backup, client.RestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreLoadBalancer failed: %s", err)
+	}
+	if len(report.Mapping) != 8 {
+		t.Errorf("got %d mapped objects, want 8: %v", len(report.Mapping), report.Mapping)
+	}
+	for _, m := range report.Mapping {
+		if !s.Exists(m.NewID) {
+			t.Errorf("restored %s %s does not exist", m.Kind, m.NewID)
+		}
+	}
+
+	restored, err := osClient.ExportLoadBalancer(ctx, report.LoadBalancerID)
+	if err != nil {
+		t.Fatalf("ExportLoadBalancer failed: %s", err)
+	}
+	if restored.LoadBalancer.Listeners[0].L7Policies[0].RedirectPoolID != restored.LoadBalancer.Pools[1].ID {
+		t.Errorf("l7 policy does not redirect to the restored pool")
+	}
+	restored.BackedUpAt = backup.BackedUpAt
+	clearIDs(backup)
+	clearIDs(restored)
+	if !reflect.DeepEqual(restored, backup) {
+		t.Errorf("restored loadbalancer differs:\ngot  %+v\nwant %+v", restored.LoadBalancer, backup.LoadBalancer)
+	}
+}
+
+func TestExportListenerPools(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	s.ListenerPools = true
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web", VipSubnetID: "subnet-1"})
+	listener := s.AddListener(lb.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+	pool := s.AddPool(lb.ID, listener.ID, pools.Pool{Name: "backends", Protocol: "HTTP", LBMethod: "ROUND_ROBIN"})
+	s.AddMember(pool.ID, pools.Member{Name: "node-1", Address: "10.1.0.5", ProtocolPort: 8080})
+	s.AddPool(lb.ID, "", pools.Pool{Name: "sorry", Protocol: "HTTP", LBMethod: "ROUND_ROBIN"})
+
+	exported, err := newProvider(t, s, false).ExportLoadBalancer(context.Background(), lb.ID)
+	if err != nil {
+		t.Fatalf("ExportLoadBalancer failed: %s", err)
+	}
+	var names []string
+	for _, p := range exported.LoadBalancer.Pools {
+		names = append(names, p.Name)
+	}
+	if want := []string{"sorry", "backends"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got pools %v, want %v", names, want)
+	}
+	backends := exported.LoadBalancer.Pools[1]
+	if backends.ListenerID != listener.ID {
+		t.Errorf("got listener %q, want %q", backends.ListenerID, listener.ID)
+	}
+	if len(backends.Members) != 1 || backends.Members[0].Address != "10.1.0.5" {
+		t.Errorf("got members %+v, want node-1", backends.Members)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// a Monday
+	start := time.Date(2018, 10, 15, 10, 17, 30, 0, time.UTC)
+	for _, tc := range []struct {
+		spec string
+		want string
+	}{
+		{"* * * * *", "2018-10-15 10:18"},
+		{"*/15 * * * *", "2018-10-15 10:30"},
+		{"5 * * * *", "2018-10-15 11:05"},
+		{"0 */6 * * *", "2018-10-15 12:00"},
+		{"30 2 * * *", "2018-10-16 02:30"},
+		{"0 9-17/4 * * mon-fri", "2018-10-15 13:00"},
+		{"0 0 * * sun", "2018-10-21 00:00"},
+		{"0 0 * * 7", "2018-10-21 00:00"},
+		{"0 0 1 * *", "2018-11-01 00:00"},
+		{"0 0 31 * *", "2018-10-31 00:00"},
+		{"0 0 29 feb *", "2020-02-29 00:00"},
+		{"0 12 1,15 * *", "2018-10-15 12:00"},
+		{"0 8 1,15 * *", "2018-11-01 08:00"},
+		// either day field matches if both are restricted
+		{"0 0 1 * fri", "2018-10-19 00:00"},
+		{"10/20 * * * *", "2018-10-15 10:30"},
+		{"@daily", "2018-10-16 00:00"},
+		{"@hourly", "2018-10-15 11:00"},
+		{"@yearly", "2019-01-01 00:00"},
+	} {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Errorf("%s: %s", tc.spec, err)
+			continue
+		}
+		if got := s.Next(start).Format("2006-01-02 15:04"); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.spec, got, tc.want)
+		}
+	}
+
+	s, _ := Parse("0 0 30 feb *")
+	if next := s.Next(start); !next.IsZero() {
+		t.Errorf("got %s for February 30", next)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "x * * * *", "@often"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("%q was parsed", spec)
+		}
+	}
+}
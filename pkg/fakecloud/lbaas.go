@@ -0,0 +1,634 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakecloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const lbaasPrefix = "/v2.0/lbaas/"
+
+// queryKeys are query parameters which are no filters.
+var queryKeys = map[string]bool{"limit": true, "marker": true, "sort_key": true, "sort_dir": true, "fields": true, "page_reverse": true}
+
+func (s *Server) serveLBaaS(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, lbaasPrefix) {
+		writeFault(w, http.StatusNotFound, fmt.Sprintf("unknown path %s", r.URL.Path))
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, lbaasPrefix), "/"), "/")
+	collection := parts[0]
+	if _, ok := singular[collection]; !ok || collection == Members || collection == L7Rules {
+		writeFault(w, http.StatusNotFound, fmt.Sprintf("unknown collection %s", collection))
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.list(w, r, collection, nil)
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		s.create(w, r, collection, nil)
+	case len(parts) == 2:
+		o := s.find(collection, parts[1])
+		if o == nil {
+			writeFault(w, http.StatusNotFound, fmt.Sprintf("%s %s could not be found", singular[collection], parts[1]))
+			return
+		}
+		s.serveObject(w, r, o)
+	case len(parts) == 3 && collection == LoadBalancers && parts[2] == "tags" && r.Method == http.MethodPut:
+		lb := s.find(LoadBalancers, parts[1])
+		if lb == nil {
+			writeFault(w, http.StatusNotFound, fmt.Sprintf("loadbalancer %s could not be found", parts[1]))
+			return
+		}
+		s.updateTags(w, r, lb)
+	case len(parts) == 3 && collection == LoadBalancers && r.Method == http.MethodGet:
+		lb := s.find(LoadBalancers, parts[1])
+		if lb == nil {
+			writeFault(w, http.StatusNotFound, fmt.Sprintf("loadbalancer %s could not be found", parts[1]))
+			return
+		}
+		switch parts[2] {
+		case "status", "statuses":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"statuses": map[string]interface{}{"loadbalancer": s.statusTree(lb)}})
+		case "stats":
+			stats := s.stats[lb.id()]
+			if stats == nil {
+				stats = map[string]interface{}{"active_connections": 0, "bytes_in": 0, "bytes_out": 0, "request_errors": 0, "total_connections": 0}
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"stats": stats})
+		default:
+			writeFault(w, http.StatusNotFound, fmt.Sprintf("unknown path %s", r.URL.Path))
+		}
+	case len(parts) >= 3 && parts[2] == subCollections[collection].collection:
+		sub := subCollections[collection]
+		parent := s.find(collection, parts[1])
+		if parent == nil {
+			writeFault(w, http.StatusNotFound, fmt.Sprintf("%s %s could not be found", singular[collection], parts[1]))
+			return
+		}
+		if len(parts) == 3 && r.Method == http.MethodGet {
+			s.list(w, r, sub.collection, parent)
+		} else if len(parts) == 3 && r.Method == http.MethodPost {
+			s.create(w, r, sub.collection, parent)
+		} else if len(parts) == 4 {
+			child := s.find(sub.collection, parts[3])
+			if child == nil || str(child.fields[sub.parentKey]) != parent.id() {
+				writeFault(w, http.StatusNotFound, fmt.Sprintf("%s %s could not be found", singular[sub.collection], parts[3]))
+				return
+			}
+			s.serveObject(w, r, child)
+		} else {
+			writeFault(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	default:
+		writeFault(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, o *object) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{singular[o.collection]: s.render(o)})
+	case http.MethodPut:
+		s.update(w, r, o)
+	case http.MethodDelete:
+		s.delete(w, r, o)
+	default:
+		writeFault(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, collection string, parent *object) {
+	query := r.URL.Query()
+	var matches []map[string]interface{}
+	for _, o := range s.objects {
+		if o.collection != collection {
+			continue
+		}
+		if parent != nil && str(o.fields[subCollections[parent.collection].parentKey]) != parent.id() {
+			continue
+		}
+		if s.ListenerPools && collection == Pools && query.Get("listener_id") == "" {
+			if refs, ok := o.fields["listeners"].([]interface{}); ok && len(refs) > 0 {
+				continue
+			}
+		}
+		rendered := s.render(o)
+		if matchesQuery(rendered, query) {
+			matches = append(matches, rendered)
+		}
+	}
+
+	if marker := query.Get("marker"); marker != "" {
+		for i, m := range matches {
+			if str(m["id"]) == marker {
+				matches = matches[i+1:]
+				break
+			}
+		}
+	}
+	limit := s.PageSize
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	result := map[string]interface{}{}
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+		next := *r.URL
+		q := next.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("marker", str(matches[len(matches)-1]["id"]))
+		next.RawQuery = q.Encode()
+		result[collection+"_links"] = []map[string]interface{}{{"rel": "next", "href": s.URL + next.RequestURI()}}
+	}
+	if matches == nil {
+		matches = []map[string]interface{}{}
+	}
+	result[collection] = matches
+	writeJSON(w, http.StatusOK, result)
+}
+
+// matchesQuery applies the API filters. A filter "x_id" also matches an
+// object which references x in its list "xs", e.g. loadbalancer_id matches
+// listeners by their "loadbalancers".
+func matchesQuery(fields map[string]interface{}, query url.Values) bool {
+	for key, values := range query {
+		if queryKeys[key] || len(values) == 0 {
+			continue
+		}
+		want := values[0]
+		if key == "project_id" {
+			key = "tenant_id"
+		}
+		if v, ok := fields[key]; ok {
+			if str(v) != want {
+				return false
+			}
+			continue
+		}
+		if strings.HasSuffix(key, "_id") {
+			if refs, ok := fields[strings.TrimSuffix(key, "_id")+"s"].([]interface{}); ok {
+				if !containsRef(refs, want) {
+					return false
+				}
+				continue
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsRef(refs []interface{}, id string) bool {
+	for _, ref := range refs {
+		if m, ok := ref.(map[string]interface{}); ok && str(m["id"]) == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, collection string, parent *object) {
+	var body map[string]map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFault(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	fields, ok := body[singular[collection]]
+	if !ok {
+		writeFault(w, http.StatusBadRequest, fmt.Sprintf("missing %s in request body", singular[collection]))
+		return
+	}
+	if parent != nil {
+		fields[subCollections[parent.collection].parentKey] = parent.id()
+	}
+	o, err := s.insert(collection, fields)
+	if err != nil {
+		err.write(w)
+		return
+	}
+	s.markPending(o, "PENDING_CREATE")
+	writeJSON(w, http.StatusCreated, map[string]interface{}{singular[collection]: s.render(o)})
+}
+
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e *apiError) write(w http.ResponseWriter) {
+	writeFault(w, e.status, e.msg)
+}
+
+// insert validates a new object, links it to its parents and stores it.
+func (s *Server) insert(collection string, fields map[string]interface{}) (*object, *apiError) {
+	o := &object{collection: collection, fields: map[string]interface{}{
+		"id":                  newID(),
+		"name":                "",
+		"description":         "",
+		"admin_state_up":      true,
+		"tenant_id":           ProjectID,
+		"project_id":          ProjectID,
+		"provisioning_status": "ACTIVE",
+		"operating_status":    "ONLINE",
+	}}
+	for key, value := range fields {
+		o.fields[key] = value
+	}
+	ref := func(id string) []interface{} {
+		return []interface{}{map[string]interface{}{"id": id}}
+	}
+	take := func(key string) string {
+		v := str(o.fields[key])
+		delete(o.fields, key)
+		return v
+	}
+
+	var lb *object
+	switch collection {
+	case LoadBalancers:
+		if str(o.fields["vip_address"]) == "" {
+			s.nextIP++
+			o.fields["vip_address"] = fmt.Sprintf("10.0.%d.%d", s.nextIP/250, s.nextIP%250+2)
+		}
+		if str(o.fields["vip_port_id"]) == "" {
+			o.fields["vip_port_id"] = newID()
+		}
+		if str(o.fields["provider"]) == "" {
+			o.fields["provider"] = "octavia"
+		}
+		if _, ok := o.fields["tags"]; !ok && s.Tags {
+			o.fields["tags"] = []interface{}{}
+		}
+		delete(o.fields, "listeners")
+		delete(o.fields, "pools")
+	case Listeners:
+		lbID := take("loadbalancer_id")
+		if refs, ok := o.fields["loadbalancers"].([]interface{}); ok && lbID == "" && len(refs) > 0 {
+			lbID = str(refs[0].(map[string]interface{})["id"])
+		}
+		if lb = s.find(LoadBalancers, lbID); lb == nil {
+			return nil, &apiError{http.StatusNotFound, fmt.Sprintf("loadbalancer %s could not be found", lbID)}
+		}
+		o.fields["loadbalancers"] = ref(lbID)
+		delete(o.fields, "pools")
+		delete(o.fields, "l7policies")
+	case Pools:
+		listenerID, lbID := take("listener_id"), take("loadbalancer_id")
+		o.fields["listeners"] = []interface{}{}
+		if listenerID != "" {
+			listener := s.find(Listeners, listenerID)
+			if listener == nil {
+				return nil, &apiError{http.StatusNotFound, fmt.Sprintf("listener %s could not be found", listenerID)}
+			}
+			o.fields["listeners"] = ref(listenerID)
+			lbID = str(listener.fields["loadbalancers"].([]interface{})[0].(map[string]interface{})["id"])
+			if str(listener.fields["default_pool_id"]) == "" {
+				listener.fields["default_pool_id"] = o.id()
+			}
+		}
+		if lb = s.find(LoadBalancers, lbID); lb == nil {
+			return nil, &apiError{http.StatusNotFound, fmt.Sprintf("loadbalancer %s could not be found", lbID)}
+		}
+		o.fields["loadbalancers"] = ref(lbID)
+		o.fields["healthmonitor_id"] = ""
+		delete(o.fields, "members")
+		delete(o.fields, "healthmonitor")
+	case Members:
+		pool := s.find(Pools, str(o.fields["pool_id"]))
+		if pool == nil {
+			return nil, &apiError{http.StatusNotFound, fmt.Sprintf("pool %s could not be found", o.fields["pool_id"])}
+		}
+		lb = s.loadBalancerOf(pool)
+		if _, ok := o.fields["weight"]; !ok {
+			o.fields["weight"] = 1
+		}
+	case HealthMonitors:
+		poolID := take("pool_id")
+		if refs, ok := o.fields["pools"].([]interface{}); ok && poolID == "" && len(refs) > 0 {
+			poolID = str(refs[0].(map[string]interface{})["id"])
+		}
+		pool := s.find(Pools, poolID)
+		if pool == nil {
+			return nil, &apiError{http.StatusNotFound, fmt.Sprintf("pool %s could not be found", poolID)}
+		}
+		if str(pool.fields["healthmonitor_id"]) != "" {
+			return nil, &apiError{http.StatusConflict, fmt.Sprintf("pool %s already has a health monitor", poolID)}
+		}
+		if t := str(o.fields["type"]); (t == "HTTP" || t == "HTTPS") && str(o.fields["expected_codes"]) == "" {
+			o.fields["http_method"], o.fields["expected_codes"] = "GET", "200"
+		}
+		lb = s.loadBalancerOf(pool)
+		o.fields["pools"] = ref(poolID)
+		pool.fields["healthmonitor_id"] = o.id()
+	case L7Policies:
+		listener := s.find(Listeners, str(o.fields["listener_id"]))
+		if listener == nil {
+			return nil, &apiError{http.StatusNotFound, fmt.Sprintf("listener %s could not be found", o.fields["listener_id"])}
+		}
+		lb = s.loadBalancerOf(listener)
+		delete(o.fields, "rules")
+	case L7Rules:
+		policy := s.find(L7Policies, str(o.fields["l7policy_id"]))
+		if policy == nil {
+			return nil, &apiError{http.StatusNotFound, fmt.Sprintf("l7policy %s could not be found", o.fields["l7policy_id"])}
+		}
+		lb = s.loadBalancerOf(policy)
+		if _, ok := o.fields["invert"]; !ok {
+			o.fields["invert"] = false
+		}
+	}
+	if lb != nil && isPending(lb) {
+		return nil, immutable(lb)
+	}
+	s.objects = append(s.objects, o)
+	return o, nil
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, o *object) {
+	var body map[string]map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFault(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if lb := s.loadBalancerOf(o); lb != nil && isPending(lb) {
+		immutable(lb).write(w)
+		return
+	}
+	fields := body[singular[o.collection]]
+	if _, ok := fields["tags"]; ok && !s.Tags {
+		writeFault(w, http.StatusBadRequest, "Unrecognized attribute(s) 'tags'")
+		return
+	}
+	for key, value := range fields {
+		switch key {
+		case "id", "loadbalancers", "listeners", "pools", "members", "provisioning_status", "operating_status":
+			continue
+		}
+		o.fields[key] = value
+	}
+	s.markPending(o, "PENDING_UPDATE")
+	writeJSON(w, http.StatusOK, map[string]interface{}{singular[o.collection]: s.render(o)})
+}
+
+// updateTags replaces the tags of a LoadBalancer like the tags resource of
+// the Neutron API.
+func (s *Server) updateTags(w http.ResponseWriter, r *http.Request, lb *object) {
+	if !s.Tags {
+		writeFault(w, http.StatusNotFound, fmt.Sprintf("unknown path %s", r.URL.Path))
+		return
+	}
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFault(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tags := []interface{}{}
+	for _, tag := range body.Tags {
+		// the limit of the Neutron tag extension
+		if len(tag) > 60 {
+			writeFault(w, http.StatusBadRequest, fmt.Sprintf("Invalid input for tags. Reason: '%s' exceeds maximum length of 60.", tag))
+			return
+		}
+		tags = append(tags, tag)
+	}
+	lb.fields["tags"] = tags
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tags": body.Tags})
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, o *object) {
+	if lb := s.loadBalancerOf(o); lb != nil && isPending(lb) {
+		immutable(lb).write(w)
+		return
+	}
+	if o.collection == LoadBalancers && r.URL.Query().Get("cascade") != "true" {
+		for _, child := range s.objects {
+			if child != o && s.loadBalancerOf(child) == o {
+				writeFault(w, http.StatusConflict, fmt.Sprintf("Load Balancer %s has children and cannot be deleted.", o.id()))
+				return
+			}
+		}
+	}
+	s.markPending(o, "PENDING_DELETE")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// markPending puts an object and its LoadBalancer into a PENDING_* state.
+func (s *Server) markPending(o *object, status string) {
+	o.fields["provisioning_status"] = status
+	// the request which changed the object does not count
+	o.pending = s.PendingTicks + 1
+	if lb := s.loadBalancerOf(o); lb != nil && lb != o {
+		lb.fields["provisioning_status"] = "PENDING_UPDATE"
+	}
+	if s.PendingTicks <= 0 {
+		s.resolve(o)
+	}
+}
+
+// remove deletes an object together with everything that depends on it.
+func (s *Server) remove(o *object) {
+	var keep []*object
+	var removed []*object
+	for _, other := range s.objects {
+		if other == o || s.dependsOn(other, o) {
+			removed = append(removed, other)
+			continue
+		}
+		keep = append(keep, other)
+	}
+	s.objects = keep
+	for _, r := range removed {
+		if r.collection == HealthMonitors {
+			for _, pool := range s.objects {
+				if pool.collection == Pools && str(pool.fields["healthmonitor_id"]) == r.id() {
+					pool.fields["healthmonitor_id"] = ""
+				}
+			}
+		}
+		if r.collection == Pools {
+			for _, listener := range s.objects {
+				if listener.collection == Listeners && str(listener.fields["default_pool_id"]) == r.id() {
+					listener.fields["default_pool_id"] = ""
+				}
+			}
+		}
+		delete(s.stats, r.id())
+	}
+}
+
+// dependsOn reports whether child is removed together with parent.
+func (s *Server) dependsOn(child, parent *object) bool {
+	switch parent.collection {
+	case LoadBalancers:
+		return s.loadBalancerOf(child) == parent
+	case Listeners:
+		if child.collection == L7Rules {
+			child = s.find(L7Policies, str(child.fields["l7policy_id"]))
+		}
+		return child != nil && child.collection == L7Policies && str(child.fields["listener_id"]) == parent.id()
+	case L7Policies:
+		return child.collection == L7Rules && str(child.fields["l7policy_id"]) == parent.id()
+	case Pools:
+		return (child.collection == Members && str(child.fields["pool_id"]) == parent.id()) ||
+			(child.collection == HealthMonitors && str(parent.fields["healthmonitor_id"]) == child.id())
+	}
+	return false
+}
+
+// loadBalancerOf returns the LoadBalancer an object belongs to.
+func (s *Server) loadBalancerOf(o *object) *object {
+	firstRef := func(key string) string {
+		if refs, ok := o.fields[key].([]interface{}); ok && len(refs) > 0 {
+			return str(refs[0].(map[string]interface{})["id"])
+		}
+		return ""
+	}
+	switch o.collection {
+	case LoadBalancers:
+		return o
+	case Listeners, Pools:
+		return s.find(LoadBalancers, firstRef("loadbalancers"))
+	case Members:
+		if pool := s.find(Pools, str(o.fields["pool_id"])); pool != nil {
+			return s.loadBalancerOf(pool)
+		}
+	case HealthMonitors:
+		if pool := s.find(Pools, firstRef("pools")); pool != nil {
+			return s.loadBalancerOf(pool)
+		}
+	case L7Policies:
+		if listener := s.find(Listeners, str(o.fields["listener_id"])); listener != nil {
+			return s.loadBalancerOf(listener)
+		}
+	case L7Rules:
+		if policy := s.find(L7Policies, str(o.fields["l7policy_id"])); policy != nil {
+			return s.loadBalancerOf(policy)
+		}
+	}
+	return nil
+}
+
+func (s *Server) find(collection, id string) *object {
+	for _, o := range s.objects {
+		if (collection == "" || o.collection == collection) && o.id() == id {
+			return o
+		}
+	}
+	return nil
+}
+
+func (s *Server) children(collection, key, id string) []interface{} {
+	refs := []interface{}{}
+	for _, o := range s.objects {
+		if o.collection != collection {
+			continue
+		}
+		if str(o.fields[key]) == id {
+			refs = append(refs, map[string]interface{}{"id": o.id()})
+		} else if list, ok := o.fields[key].([]interface{}); ok && containsRef(list, id) {
+			refs = append(refs, map[string]interface{}{"id": o.id()})
+		}
+	}
+	return refs
+}
+
+// render returns the API representation of an object including the
+// references to its children.
+func (s *Server) render(o *object) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range o.fields {
+		result[key] = value
+	}
+	switch o.collection {
+	case LoadBalancers:
+		result["listeners"] = s.children(Listeners, "loadbalancers", o.id())
+		result["pools"] = s.children(Pools, "loadbalancers", o.id())
+	case Listeners:
+		result["l7policies"] = s.children(L7Policies, "listener_id", o.id())
+	case Pools:
+		result["members"] = s.children(Members, "pool_id", o.id())
+	case L7Policies:
+		result["rules"] = s.children(L7Rules, "l7policy_id", o.id())
+	}
+	return result
+}
+
+// statusTree renders the status tree of a LoadBalancer as returned by the
+// statuses call.
+func (s *Server) statusTree(lb *object) map[string]interface{} {
+	status := func(o *object) map[string]interface{} {
+		return map[string]interface{}{
+			"id":                  o.id(),
+			"name":                o.fields["name"],
+			"provisioning_status": o.fields["provisioning_status"],
+			"operating_status":    o.fields["operating_status"],
+		}
+	}
+	tree := status(lb)
+	var listenerStatuses []interface{}
+	for _, o := range s.objects {
+		if o.collection != Listeners || s.loadBalancerOf(o) != lb {
+			continue
+		}
+		listener := status(o)
+		var poolStatuses, policyStatuses []interface{}
+		for _, p := range s.objects {
+			if p.collection == Pools && containsRef(p.fields["listeners"].([]interface{}), o.id()) {
+				pool := status(p)
+				var memberStatuses []interface{}
+				for _, m := range s.objects {
+					if m.collection == Members && str(m.fields["pool_id"]) == p.id() {
+						member := status(m)
+						member["address"] = m.fields["address"]
+						member["protocol_port"] = m.fields["protocol_port"]
+						memberStatuses = append(memberStatuses, member)
+					}
+				}
+				pool["members"] = memberStatuses
+				if hm := s.find(HealthMonitors, str(p.fields["healthmonitor_id"])); hm != nil {
+					monitor := status(hm)
+					monitor["type"] = hm.fields["type"]
+					pool["healthmonitor"] = monitor
+				}
+				poolStatuses = append(poolStatuses, pool)
+			}
+			if p.collection == L7Policies && str(p.fields["listener_id"]) == o.id() {
+				policyStatuses = append(policyStatuses, status(p))
+			}
+		}
+		listener["pools"] = poolStatuses
+		listener["l7policies"] = policyStatuses
+		listenerStatuses = append(listenerStatuses, listener)
+	}
+	tree["listeners"] = listenerStatuses
+	return tree
+}
+
+func isPending(o *object) bool {
+	return strings.HasPrefix(str(o.fields["provisioning_status"]), "PENDING_")
+}
+
+func immutable(lb *object) *apiError {
+	return &apiError{http.StatusConflict, fmt.Sprintf("Load Balancer %s is immutable and cannot be updated.", lb.id())}
+}
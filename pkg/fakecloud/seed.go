@@ -0,0 +1,126 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakecloud
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+)
+
+// The Add* helpers seed the fake with ACTIVE objects without going through
+// the API. Empty fields are filled with defaults, so an object is always
+// admin up and gets a generated ID unless one is given. References to parents
+// are taken from the arguments, those in the passed struct are ignored. The
+// helpers panic if a parent does not exist.
+
+// AddLoadBalancer adds a LoadBalancer.
+func (s *Server) AddLoadBalancer(lb loadbalancers.LoadBalancer) loadbalancers.LoadBalancer {
+	var result loadbalancers.LoadBalancer
+	s.seed(LoadBalancers, lb, nil, &result)
+	return result
+}
+
+// AddListener adds a Listener to a LoadBalancer.
+func (s *Server) AddListener(loadbalancerID string, l listeners.Listener) listeners.Listener {
+	var result listeners.Listener
+	s.seed(Listeners, l, map[string]interface{}{"loadbalancer_id": loadbalancerID}, &result)
+	return result
+}
+
+// AddPool adds a Pool to a LoadBalancer and, if listenerID is not empty, to
+// a Listener of it.
+func (s *Server) AddPool(loadbalancerID, listenerID string, p pools.Pool) pools.Pool {
+	var result pools.Pool
+	s.seed(Pools, p, map[string]interface{}{"loadbalancer_id": loadbalancerID, "listener_id": listenerID}, &result)
+	return result
+}
+
+// AddMember adds a Member to a Pool.
+func (s *Server) AddMember(poolID string, m pools.Member) pools.Member {
+	var result pools.Member
+	s.seed(Members, m, map[string]interface{}{"pool_id": poolID}, &result)
+	return result
+}
+
+// AddMonitor adds the health monitor of a Pool.
+func (s *Server) AddMonitor(poolID string, m monitors.Monitor) monitors.Monitor {
+	var result monitors.Monitor
+	s.seed(HealthMonitors, m, map[string]interface{}{"pool_id": poolID}, &result)
+	return result
+}
+
+// AddL7Policy adds an L7 policy to a Listener.
+func (s *Server) AddL7Policy(listenerID string, p l7policies.L7Policy) l7policies.L7Policy {
+	var result l7policies.L7Policy
+	s.seed(L7Policies, p, map[string]interface{}{"listener_id": listenerID}, &result)
+	return result
+}
+
+// AddL7Rule adds a rule to an L7 policy.
+func (s *Server) AddL7Rule(policyID string, r l7policies.Rule) l7policies.Rule {
+	var result l7policies.Rule
+	s.seed(L7Rules, r, map[string]interface{}{"l7policy_id": policyID}, &result)
+	return result
+}
+
+// SetStats sets the statistics returned for a LoadBalancer.
+func (s *Server) SetStats(loadbalancerID string, stats loadbalancers.Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats[loadbalancerID] = toMap(stats)
+}
+
+func (s *Server) seed(collection string, v interface{}, parents map[string]interface{}, result interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fields := map[string]interface{}{}
+	for key, value := range toMap(v) {
+		switch value {
+		case nil, "", false:
+			continue
+		}
+		fields[key] = value
+	}
+	for _, key := range []string{"loadbalancers", "listeners", "pools", "members", "healthmonitor", "healthmonitor_id", "l7policies", "rules"} {
+		delete(fields, key)
+	}
+	for key, value := range parents {
+		fields[key] = value
+	}
+	o, err := s.insert(collection, fields)
+	if err != nil {
+		panic(fmt.Sprintf("fakecloud: cannot add %s: %s", singular[collection], err.msg))
+	}
+	data, _ := json.Marshal(s.render(o))
+	if err := json.Unmarshal(data, result); err != nil {
+		panic(fmt.Sprintf("fakecloud: cannot render %s: %s", singular[collection], err))
+	}
+}
+
+func toMap(v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("fakecloud: %s", err))
+	}
+	m := map[string]interface{}{}
+	json.Unmarshal(data, &m)
+	return m
+}
@@ -0,0 +1,343 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fakecloud implements an in-process OpenStack cloud with a Keystone
+// v3 token endpoint and the LBaaS v2 / Octavia API, good enough to run oli
+// against it. It models the provisioning state of every object including
+// PENDING_* transitions, rejects changes to busy LoadBalancers with 409 and
+// paginates collections with next links.
+//
+// A typical test looks like:
+//
+//	s := fakecloud.NewServer()
+//	defer s.Close()
+//	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+//	opts := s.AuthOptions()
+//	p, err := client.NewOpenStackProvider(client.Config{AuthOptions: &opts})
+package fakecloud
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+)
+
+// Collections served by the fake, as they appear in the URL.
+const (
+	LoadBalancers  = "loadbalancers"
+	Listeners      = "listeners"
+	Pools          = "pools"
+	Members        = "members"
+	HealthMonitors = "healthmonitors"
+	L7Policies     = "l7policies"
+	L7Rules        = "rules"
+)
+
+// Credentials accepted by the fake Keystone.
+const (
+	Username   = "oli"
+	Password   = "secret"
+	DomainName = "Default"
+	ProjectID  = "fake-project"
+	Region     = "RegionOne"
+)
+
+var singular = map[string]string{
+	LoadBalancers:  "loadbalancer",
+	Listeners:      "listener",
+	Pools:          "pool",
+	Members:        "member",
+	HealthMonitors: "healthmonitor",
+	L7Policies:     "l7policy",
+	L7Rules:        "rule",
+}
+
+// subCollections are only served below their parent, e.g. /pools/{id}/members.
+var subCollections = map[string]struct {
+	collection string
+	parentKey  string
+}{
+	Pools:      {Members, "pool_id"},
+	L7Policies: {L7Rules, "l7policy_id"},
+}
+
+// Server is a fake OpenStack cloud. Its exported fields may be changed
+// before the first request is sent.
+type Server struct {
+	// URL is the base URL of the server.
+	URL string
+	// PageSize limits the number of objects returned per page if the client
+	// does not ask for a limit. Zero disables pagination.
+	PageSize int
+	// PendingTicks is the number of API requests which still see an object
+	// in a PENDING_* state after it was changed. Defaults to 2, zero makes
+	// all changes take effect immediately.
+	PendingTicks int
+	// Tags makes LoadBalancers carry tags, as they do with Octavia since
+	// API version 2.5. Otherwise requests setting tags are rejected.
+	Tags bool
+	// TokenLifetime makes issued tokens expire, requests with an expired
+	// token fail with 401. Zero makes tokens valid forever.
+	TokenLifetime time.Duration
+	// ListenerPools makes listing Pools by LoadBalancer leave out the Pools
+	// of a Listener, as some Neutron releases do. They are only returned if
+	// the Listener is asked for as well.
+	ListenerPools bool
+
+	srv *httptest.Server
+	mu  sync.Mutex
+	// tokens maps the issued tokens to the time they expire
+	tokens   map[string]time.Time
+	objects  []*object
+	injected []injection
+	requests []string
+	stats    map[string]map[string]interface{}
+	nextIP   int
+}
+
+type object struct {
+	collection string
+	fields     map[string]interface{}
+	// pending counts down the requests until a PENDING_* state resolves.
+	pending int
+}
+
+func (o *object) id() string {
+	return str(o.fields["id"])
+}
+
+type injection struct {
+	method string
+	path   string
+	status int
+	body   string
+}
+
+// NewServer starts a fake cloud on a local port.
+func NewServer() *Server {
+	s := &Server{
+		PendingTicks: 2,
+		tokens:       map[string]time.Time{},
+		stats:        map[string]map[string]interface{}{},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// AuthOptions returns credentials for the fake Keystone.
+func (s *Server) AuthOptions() gophercloud.AuthOptions {
+	return gophercloud.AuthOptions{
+		IdentityEndpoint: s.URL + "/v3/",
+		Username:         Username,
+		Password:         Password,
+		DomainName:       DomainName,
+		TenantID:         ProjectID,
+	}
+}
+
+// InjectError makes the next request with the given method and a path
+// containing path fail with status and body.
+func (s *Server) InjectError(method, path string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injected = append(s.injected, injection{method: method, path: path, status: status, body: body})
+}
+
+// Requests returns all requests served so far as "METHOD /path?query".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Exists reports whether an object with the given ID exists.
+func (s *Server) Exists(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.find("", id) != nil
+}
+
+// Count returns the number of objects in a collection.
+func (s *Server) Count(collection string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, o := range s.objects {
+		if o.collection == collection {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+
+	for i, inj := range s.injected {
+		if inj.method == r.Method && strings.Contains(r.URL.Path, inj.path) {
+			s.injected = append(s.injected[:i], s.injected[i+1:]...)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(inj.status)
+			fmt.Fprint(w, inj.body)
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost && strings.TrimSuffix(r.URL.Path, "/") == "/v3/auth/tokens" {
+		s.serveToken(w, r)
+		return
+	}
+	if !s.valid(r.Header.Get("X-Auth-Token")) {
+		writeFault(w, http.StatusUnauthorized, "The request you have made requires authentication.")
+		return
+	}
+	s.tick()
+	s.serveLBaaS(w, r)
+}
+
+// tick advances all pending state transitions by one request.
+func (s *Server) tick() {
+	var resolved []*object
+	for _, o := range s.objects {
+		if o.pending > 0 {
+			o.pending--
+			if o.pending == 0 {
+				resolved = append(resolved, o)
+			}
+		}
+	}
+	for _, o := range resolved {
+		s.resolve(o)
+	}
+}
+
+// resolve ends the PENDING_* state of an object.
+func (s *Server) resolve(o *object) {
+	if o.fields["provisioning_status"] == "PENDING_DELETE" {
+		s.remove(o)
+	} else {
+		o.fields["provisioning_status"] = "ACTIVE"
+	}
+	if lb := s.loadBalancerOf(o); lb != nil && lb != o && lb.fields["provisioning_status"] == "PENDING_UPDATE" {
+		lb.fields["provisioning_status"] = "ACTIVE"
+	}
+}
+
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Auth struct {
+			Identity struct {
+				Password struct {
+					User struct {
+						Name     string `json:"name"`
+						Password string `json:"password"`
+					} `json:"user"`
+				} `json:"password"`
+			} `json:"identity"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFault(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	user := body.Auth.Identity.Password.User
+	if user.Name != Username || user.Password != Password {
+		writeFault(w, http.StatusUnauthorized, "The request you have made requires authentication.")
+		return
+	}
+	endpoint := func(serviceType string) map[string]interface{} {
+		return map[string]interface{}{
+			"type": serviceType,
+			"name": serviceType,
+			"endpoints": []map[string]interface{}{{
+				"interface": "public",
+				"region":    Region,
+				"region_id": Region,
+				"url":       s.URL + "/",
+			}},
+		}
+	}
+	token, expires := newID(), time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	if s.TokenLifetime > 0 {
+		expires = time.Now().Add(s.TokenLifetime).UTC()
+	}
+	s.tokens[token] = expires
+	w.Header().Set("X-Subject-Token", token)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"token": map[string]interface{}{
+			"expires_at": expires.Format("2006-01-02T15:04:05.000000Z"),
+			"project":    map[string]interface{}{"id": ProjectID, "name": ProjectID},
+			"user":       map[string]interface{}{"id": Username, "name": Username},
+			"catalog": []map[string]interface{}{
+				endpoint("identity"),
+				endpoint("network"),
+				endpoint("load-balancer"),
+			},
+		},
+	})
+}
+
+// valid reports whether token was issued and has not expired yet.
+func (s *Server) valid(token string) bool {
+	expires, ok := s.tokens[token]
+	return ok && time.Now().Before(expires)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeFault writes an error in the format used by Octavia.
+func writeFault(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]interface{}{
+		"faultcode":   "Client",
+		"faultstring": msg,
+		"debuginfo":   nil,
+	})
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func str(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
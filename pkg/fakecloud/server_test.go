@@ -0,0 +1,97 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakecloud
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+)
+
+func newLoadBalancerClient(t *testing.T, s *Server) *gophercloud.ServiceClient {
+	provider, err := openstack.AuthenticatedClient(s.AuthOptions())
+	if err != nil {
+		t.Fatalf("failed to authenticate: %s", err)
+	}
+	c, err := openstack.NewLoadBalancerV2(provider, gophercloud.EndpointOpts{Region: Region})
+	if err != nil {
+		t.Fatalf("failed to create load-balancer client: %s", err)
+	}
+	return c
+}
+
+func statusCode(err error) int {
+	switch e := err.(type) {
+	case nil:
+		return 0
+	case gophercloud.ErrUnexpectedResponseCode:
+		return e.Actual
+	case gophercloud.ErrDefault404:
+		return e.Actual
+	}
+	return -1
+}
+
+func TestPendingLoadBalancerIsImmutable(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.PendingTicks = 3
+	c := newLoadBalancerClient(t, s)
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+
+	if _, err := listeners.Create(c, listeners.CreateOpts{LoadbalancerID: lb.ID, Protocol: "HTTP", ProtocolPort: 80}).Extract(); err != nil {
+		t.Fatalf("failed to create listener: %s", err)
+	}
+	got, err := loadbalancers.Get(c, lb.ID).Extract()
+	if err != nil || got.ProvisioningStatus != "PENDING_UPDATE" {
+		t.Fatalf("got %v, %v, want a PENDING_UPDATE loadbalancer", got, err)
+	}
+	_, err = listeners.Create(c, listeners.CreateOpts{LoadbalancerID: lb.ID, Protocol: "HTTP", ProtocolPort: 81}).Extract()
+	if code := statusCode(err); code != http.StatusConflict {
+		t.Errorf("got status %d, want 409", code)
+	}
+	// the third request after the change still sees it pending
+	loadbalancers.Get(c, lb.ID)
+	got, err = loadbalancers.Get(c, lb.ID).Extract()
+	if err != nil || got.ProvisioningStatus != "ACTIVE" || len(got.Listeners) != 1 {
+		t.Errorf("got %v, %v, want an ACTIVE loadbalancer with one listener", got, err)
+	}
+}
+
+func TestDeleteLoadBalancerWithChildren(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.PendingTicks = 0
+	c := newLoadBalancerClient(t, s)
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+	s.AddListener(lb.ID, listeners.Listener{Protocol: "HTTP", ProtocolPort: 80})
+
+	if code := statusCode(loadbalancers.Delete(c, lb.ID).Err); code != http.StatusConflict {
+		t.Errorf("got status %d, want 409", code)
+	}
+	if err := loadbalancers.CascadingDelete(c, lb.ID).Err; err != nil {
+		t.Fatalf("cascading delete failed: %s", err)
+	}
+	if s.Count(LoadBalancers) != 0 || s.Count(Listeners) != 0 {
+		t.Errorf("cascading delete left objects behind")
+	}
+	if code := statusCode(loadbalancers.Get(c, lb.ID).Err); code != http.StatusNotFound {
+		t.Errorf("got status %d, want 404", code)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// Description is the detailed view of a single object.
+type Description struct {
+	client.Object
+	Name string
+	// Properties lists all fields of the object, in API order.
+	Properties []Property
+	Parents    []Reference
+	Children   []Reference
+	// LoadBalancerIDs are the LoadBalancers the object belongs to.
+	LoadBalancerIDs []string
+}
+
+// Property is a single field of an object.
+type Property struct {
+	Name  string
+	Value string
+}
+
+// Reference points to a related object.
+type Reference struct {
+	client.Object
+	Name string
+}
+
+// Describe returns the fields, parents and children of the object with the
+// given ID, whatever its kind.
+func (inv *Inventory) Describe(id string) (*Description, error) {
+	b := strconv.FormatBool
+	i := strconv.Itoa
+	d := &Description{}
+	ref := func(kind, id, name string) Reference {
+		return Reference{Object: client.Object{Kind: kind, ID: id}, Name: name}
+	}
+	set := func(kind, id, name string, properties ...Property) {
+		d.Object = client.Object{Kind: kind, ID: id}
+		d.Name = name
+		d.Properties = properties
+	}
+
+	for _, lb := range inv.LoadBalancers {
+		if lb.ID != id {
+			continue
+		}
+		set(client.KindLoadBalancer, lb.ID, lb.Name,
+			Property{"name", lb.Name},
+			Property{"description", lb.Description},
+			Property{"vip_address", lb.VipAddress},
+			Property{"vip_port_id", lb.VipPortID},
+			Property{"vip_subnet_id", lb.VipSubnetID},
+			Property{"provider", lb.Provider},
+			Property{"flavor", lb.Flavor},
+			Property{"tenant_id", lb.TenantID},
+			Property{"admin_state_up", b(lb.AdminStateUp)},
+			Property{"provisioning_status", lb.ProvisioningStatus},
+			Property{"operating_status", lb.OperatingStatus})
+		d.LoadBalancerIDs = []string{lb.ID}
+		for _, l := range inv.ListenersOf(lb.ID) {
+			d.Children = append(d.Children, ref(client.KindListener, l.ID, l.Name))
+		}
+		for _, p := range inv.PoolsOf(lb.ID) {
+			d.Children = append(d.Children, ref(client.KindPool, p.ID, p.Name))
+		}
+		return d, nil
+	}
+	for _, l := range inv.Listeners {
+		if l.ID != id {
+			continue
+		}
+		set(client.KindListener, l.ID, l.Name,
+			Property{"name", l.Name},
+			Property{"description", l.Description},
+			Property{"protocol", l.Protocol},
+			Property{"protocol_port", i(l.ProtocolPort)},
+			Property{"connection_limit", i(l.ConnLimit)},
+			Property{"default_pool_id", l.DefaultPoolID},
+			Property{"default_tls_container_ref", l.DefaultTlsContainerRef},
+			Property{"sni_container_refs", strings.Join(l.SniContainerRefs, ", ")},
+			Property{"tenant_id", l.TenantID},
+			Property{"admin_state_up", b(l.AdminStateUp)},
+			Property{"provisioning_status", l.ProvisioningStatus})
+		for _, lb := range l.Loadbalancers {
+			d.Parents = append(d.Parents, inv.loadBalancerRef(lb.ID))
+			d.LoadBalancerIDs = append(d.LoadBalancerIDs, lb.ID)
+		}
+		for _, p := range inv.PoolsOfListener(l.ID) {
+			d.Children = append(d.Children, ref(client.KindPool, p.ID, p.Name))
+		}
+		for _, p := range inv.L7PoliciesOf(l.ID) {
+			d.Children = append(d.Children, ref(client.KindL7Policy, p.ID, p.Name))
+		}
+		return d, nil
+	}
+	for _, p := range inv.Pools {
+		if p.ID != id {
+			continue
+		}
+		persistence := p.Persistence.Type
+		if p.Persistence.CookieName != "" {
+			persistence += fmt.Sprintf(" (cookie %s)", p.Persistence.CookieName)
+		}
+		set(client.KindPool, p.ID, p.Name,
+			Property{"name", p.Name},
+			Property{"description", p.Description},
+			Property{"protocol", p.Protocol},
+			Property{"lb_algorithm", p.LBMethod},
+			Property{"session_persistence", persistence},
+			Property{"healthmonitor_id", p.MonitorID},
+			Property{"subnet_id", p.SubnetID},
+			Property{"provider", p.Provider},
+			Property{"tenant_id", p.TenantID},
+			Property{"admin_state_up", b(p.AdminStateUp)},
+			Property{"provisioning_status", p.ProvisioningStatus},
+			Property{"operating_status", p.OperatingStatus})
+		d.Parents, d.LoadBalancerIDs = inv.poolParents(p)
+		for _, m := range inv.Members[p.ID] {
+			d.Children = append(d.Children, ref(client.KindMember, m.ID, m.Name))
+		}
+		if p.MonitorID != "" {
+			d.Children = append(d.Children, ref(client.KindHealthMonitor, p.MonitorID, inv.monitorName(p.MonitorID)))
+		}
+		return d, nil
+	}
+	for poolID, members := range inv.Members {
+		for _, m := range members {
+			if m.ID != id {
+				continue
+			}
+			set(client.KindMember, m.ID, m.Name,
+				Property{"name", m.Name},
+				Property{"address", m.Address},
+				Property{"protocol_port", i(m.ProtocolPort)},
+				Property{"weight", i(m.Weight)},
+				Property{"subnet_id", m.SubnetID},
+				Property{"tenant_id", m.TenantID},
+				Property{"admin_state_up", b(m.AdminStateUp)},
+				Property{"provisioning_status", m.ProvisioningStatus},
+				Property{"operating_status", m.OperatingStatus})
+			for _, p := range inv.Pools {
+				if p.ID == poolID {
+					d.Parents = append(d.Parents, ref(client.KindPool, p.ID, p.Name))
+					_, d.LoadBalancerIDs = inv.poolParents(p)
+				}
+			}
+			return d, nil
+		}
+	}
+	for _, m := range inv.Monitors {
+		if m.ID != id {
+			continue
+		}
+		set(client.KindHealthMonitor, m.ID, m.Name,
+			Property{"name", m.Name},
+			Property{"type", m.Type},
+			Property{"delay", i(m.Delay)},
+			Property{"timeout", i(m.Timeout)},
+			Property{"max_retries", i(m.MaxRetries)},
+			Property{"http_method", m.HTTPMethod},
+			Property{"url_path", m.URLPath},
+			Property{"expected_codes", m.ExpectedCodes},
+			Property{"tenant_id", m.TenantID},
+			Property{"admin_state_up", b(m.AdminStateUp)},
+			Property{"provisioning_status", m.ProvisioningStatus})
+		for _, pool := range m.Pools {
+			for _, p := range inv.Pools {
+				if p.ID == pool.ID {
+					d.Parents = append(d.Parents, ref(client.KindPool, p.ID, p.Name))
+					_, lbIDs := inv.poolParents(p)
+					d.LoadBalancerIDs = append(d.LoadBalancerIDs, lbIDs...)
+				}
+			}
+		}
+		return d, nil
+	}
+	for _, p := range inv.L7Policies {
+		if p.ID != id {
+			continue
+		}
+		set(client.KindL7Policy, p.ID, p.Name,
+			Property{"name", p.Name},
+			Property{"description", p.Description},
+			Property{"action", p.Action},
+			Property{"position", strconv.Itoa(int(p.Position))},
+			Property{"redirect_pool_id", p.RedirectPoolID},
+			Property{"redirect_url", p.RedirectURL},
+			Property{"tenant_id", p.TenantID},
+			Property{"admin_state_up", b(p.AdminStateUp)},
+			Property{"provisioning_status", p.ProvisioningStatus},
+			Property{"operating_status", p.OperatingStatus})
+		for _, l := range inv.Listeners {
+			if l.ID == p.ListenerID {
+				d.Parents = append(d.Parents, ref(client.KindListener, l.ID, l.Name))
+				for _, lb := range l.Loadbalancers {
+					d.LoadBalancerIDs = append(d.LoadBalancerIDs, lb.ID)
+				}
+			}
+		}
+		for _, pool := range inv.Pools {
+			if pool.ID == p.RedirectPoolID {
+				d.Children = append(d.Children, ref(client.KindPool, pool.ID, pool.Name))
+			}
+		}
+		return d, nil
+	}
+	return nil, client.NewNotFoundError(fmt.Sprintf("no object with id %s", id))
+}
+
+func (inv *Inventory) loadBalancerRef(id string) Reference {
+	lb, _ := inv.LoadBalancer(id)
+	return Reference{Object: client.Object{Kind: client.KindLoadBalancer, ID: id}, Name: lb.Name}
+}
+
+// poolParents returns the LoadBalancers and listeners of a pool, and the IDs
+// of all LoadBalancers it belongs to.
+func (inv *Inventory) poolParents(p pools.Pool) ([]Reference, []string) {
+	var parents []Reference
+	var lbIDs []string
+	seen := map[string]bool{}
+	addLoadBalancer := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			parents = append(parents, inv.loadBalancerRef(id))
+			lbIDs = append(lbIDs, id)
+		}
+	}
+	for _, lb := range p.Loadbalancers {
+		addLoadBalancer(lb.ID)
+	}
+	for _, listener := range p.Listeners {
+		for _, l := range inv.Listeners {
+			if l.ID != listener.ID {
+				continue
+			}
+			parents = append(parents, Reference{Object: client.Object{Kind: client.KindListener, ID: l.ID}, Name: l.Name})
+			for _, lb := range l.Loadbalancers {
+				addLoadBalancer(lb.ID)
+			}
+		}
+	}
+	return parents, lbIDs
+}
+
+func (inv *Inventory) monitorName(id string) string {
+	for _, m := range inv.Monitors {
+		if m.ID == id {
+			return m.Name
+		}
+	}
+	return ""
+}
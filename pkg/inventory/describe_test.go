@@ -0,0 +1,89 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+func TestDescribe(t *testing.T) {
+	inv := &Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{{ID: "lb", Name: "web", VipAddress: "10.0.0.10"}},
+		Listeners: []listeners.Listener{{ID: "listener", Name: "http", Protocol: "HTTP", ProtocolPort: 80,
+			Loadbalancers: []listeners.LoadBalancerID{{ID: "lb"}}}},
+		Pools: []pools.Pool{{ID: "pool", Name: "backends", MonitorID: "monitor",
+			Persistence: pools.SessionPersistence{Type: "APP_COOKIE", CookieName: "session"},
+			Listeners:   []pools.ListenerID{{ID: "listener"}}}},
+		Members:  map[string][]pools.Member{"pool": {{ID: "member", Address: "10.1.0.5", ProtocolPort: 8080}}},
+		Monitors: []monitors.Monitor{{ID: "monitor", Name: "ping", Pools: []monitors.PoolID{{ID: "pool"}}}},
+	}
+	ref := func(kind, id, name string) Reference {
+		return Reference{Object: client.Object{Kind: kind, ID: id}, Name: name}
+	}
+
+	d, err := inv.Describe("pool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Kind != client.KindPool || d.Name != "backends" {
+		t.Errorf("got %s %s, want pool backends", d.Kind, d.Name)
+	}
+	if want := []Reference{ref(client.KindListener, "listener", "http"), ref(client.KindLoadBalancer, "lb", "web")}; !reflect.DeepEqual(d.Parents, want) {
+		t.Errorf("got parents %v, want %v", d.Parents, want)
+	}
+	if want := []Reference{ref(client.KindMember, "member", ""), ref(client.KindHealthMonitor, "monitor", "ping")}; !reflect.DeepEqual(d.Children, want) {
+		t.Errorf("got children %v, want %v", d.Children, want)
+	}
+	if !reflect.DeepEqual(d.LoadBalancerIDs, []string{"lb"}) {
+		t.Errorf("got loadbalancers %v, want [lb]", d.LoadBalancerIDs)
+	}
+	if !containsProperty(d.Properties, Property{"session_persistence", "APP_COOKIE (cookie session)"}) {
+		t.Errorf("session persistence missing in %v", d.Properties)
+	}
+
+	d, err = inv.Describe("member")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Kind != client.KindMember || !reflect.DeepEqual(d.LoadBalancerIDs, []string{"lb"}) {
+		t.Errorf("got %s of loadbalancers %v, want member of [lb]", d.Kind, d.LoadBalancerIDs)
+	}
+
+	if _, err := inv.Describe("unknown"); !isNotFoundError(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
+
+func containsProperty(properties []Property, p Property) bool {
+	for _, other := range properties {
+		if other == p {
+			return true
+		}
+	}
+	return false
+}
+
+func isNotFoundError(err error) bool {
+	_, ok := err.(*client.NotFoundError)
+	return ok
+}
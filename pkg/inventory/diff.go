@@ -0,0 +1,233 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// Types of changes between two inventories.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Diff lists the changes between two inventories.
+type Diff struct {
+	OldCollectedAt time.Time `json:"old_collected_at"`
+	NewCollectedAt time.Time `json:"new_collected_at"`
+	Changes        []Change  `json:"changes"`
+}
+
+// Change is an added, removed or changed object.
+type Change struct {
+	Type string `json:"type"`
+	client.Object
+	Name string `json:"name,omitempty"`
+	// Fields lists the changed fields of a changed object.
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a single changed field.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Count returns the number of changes of the given type.
+func (d *Diff) Count(changeType string) int {
+	n := 0
+	for _, c := range d.Changes {
+		if c.Type == changeType {
+			n++
+		}
+	}
+	return n
+}
+
+// field is a compared field of an object. Fields are kept in order, so that
+// changes are always listed the same way.
+type field struct {
+	name  string
+	value string
+}
+
+type record struct {
+	name   string
+	fields []field
+}
+
+// kindOrder is the order in which changes are listed.
+var kindOrder = []string{client.KindLoadBalancer, client.KindListener, client.KindL7Policy, client.KindPool, client.KindMember, client.KindHealthMonitor}
+
+// Compare returns the changes from old to new. Objects are matched by ID.
+// Status flips, admin state, member weights and pool membership are all
+// reported as changed fields.
+func Compare(old, new *Inventory) *Diff {
+	d := &Diff{OldCollectedAt: old.CollectedAt, NewCollectedAt: new.CollectedAt, Changes: []Change{}}
+	oldRecords, newRecords := old.records(), new.records()
+	for _, kind := range kindOrder {
+		var ids []string
+		for id := range oldRecords[kind] {
+			ids = append(ids, id)
+		}
+		for id := range newRecords[kind] {
+			if _, ok := oldRecords[kind][id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			o, inOld := oldRecords[kind][id]
+			n, inNew := newRecords[kind][id]
+			obj := client.Object{Kind: kind, ID: id}
+			switch {
+			case !inOld:
+				d.Changes = append(d.Changes, Change{Type: Added, Object: obj, Name: n.name})
+			case !inNew:
+				d.Changes = append(d.Changes, Change{Type: Removed, Object: obj, Name: o.name})
+			default:
+				if fields := compareFields(o.fields, n.fields); len(fields) > 0 {
+					d.Changes = append(d.Changes, Change{Type: Changed, Object: obj, Name: n.name, Fields: fields})
+				}
+			}
+		}
+	}
+	return d
+}
+
+func compareFields(old, new []field) []FieldChange {
+	oldValues := map[string]string{}
+	for _, f := range old {
+		oldValues[f.name] = f.value
+	}
+	var changes []FieldChange
+	for _, f := range new {
+		if oldValues[f.name] != f.value {
+			changes = append(changes, FieldChange{Field: f.name, Old: oldValues[f.name], New: f.value})
+		}
+	}
+	return changes
+}
+
+// records returns the compared fields of all objects by kind and ID.
+func (inv *Inventory) records() map[string]map[string]record {
+	result := map[string]map[string]record{}
+	add := func(kind, id, name string, fields ...field) {
+		if result[kind] == nil {
+			result[kind] = map[string]record{}
+		}
+		result[kind][id] = record{name: name, fields: fields}
+	}
+	b := strconv.FormatBool
+	i := strconv.Itoa
+
+	for _, lb := range inv.LoadBalancers {
+		add(client.KindLoadBalancer, lb.ID, lb.Name,
+			field{"name", lb.Name},
+			field{"description", lb.Description},
+			field{"admin_state_up", b(lb.AdminStateUp)},
+			field{"provisioning_status", lb.ProvisioningStatus},
+			field{"operating_status", lb.OperatingStatus},
+			field{"vip_address", lb.VipAddress},
+			field{"provider", lb.Provider})
+	}
+	for _, l := range inv.Listeners {
+		var lbs []string
+		for _, lb := range l.Loadbalancers {
+			lbs = append(lbs, lb.ID)
+		}
+		add(client.KindListener, l.ID, l.Name,
+			field{"name", l.Name},
+			field{"loadbalancers", join(lbs)},
+			field{"protocol", l.Protocol},
+			field{"protocol_port", i(l.ProtocolPort)},
+			field{"default_pool_id", l.DefaultPoolID},
+			field{"connection_limit", i(l.ConnLimit)},
+			field{"admin_state_up", b(l.AdminStateUp)},
+			field{"provisioning_status", l.ProvisioningStatus})
+	}
+	for _, p := range inv.L7Policies {
+		add(client.KindL7Policy, p.ID, p.Name,
+			field{"name", p.Name},
+			field{"listener_id", p.ListenerID},
+			field{"action", p.Action},
+			field{"position", strconv.Itoa(int(p.Position))},
+			field{"redirect_pool_id", p.RedirectPoolID},
+			field{"redirect_url", p.RedirectURL},
+			field{"admin_state_up", b(p.AdminStateUp)},
+			field{"provisioning_status", p.ProvisioningStatus},
+			field{"operating_status", p.OperatingStatus})
+	}
+	for _, p := range inv.Pools {
+		var listenerIDs, memberIDs []string
+		for _, l := range p.Listeners {
+			listenerIDs = append(listenerIDs, l.ID)
+		}
+		for _, m := range inv.Members[p.ID] {
+			memberIDs = append(memberIDs, m.ID)
+		}
+		add(client.KindPool, p.ID, p.Name,
+			field{"name", p.Name},
+			field{"listeners", join(listenerIDs)},
+			field{"members", join(memberIDs)},
+			field{"healthmonitor_id", p.MonitorID},
+			field{"protocol", p.Protocol},
+			field{"lb_algorithm", p.LBMethod},
+			field{"admin_state_up", b(p.AdminStateUp)},
+			field{"provisioning_status", p.ProvisioningStatus},
+			field{"operating_status", p.OperatingStatus})
+	}
+	for poolID, members := range inv.Members {
+		for _, m := range members {
+			add(client.KindMember, m.ID, m.Name,
+				field{"name", m.Name},
+				field{"pool_id", poolID},
+				field{"address", m.Address},
+				field{"protocol_port", i(m.ProtocolPort)},
+				field{"weight", i(m.Weight)},
+				field{"admin_state_up", b(m.AdminStateUp)},
+				field{"provisioning_status", m.ProvisioningStatus},
+				field{"operating_status", m.OperatingStatus})
+		}
+	}
+	for _, m := range inv.Monitors {
+		add(client.KindHealthMonitor, m.ID, m.Name,
+			field{"name", m.Name},
+			field{"type", m.Type},
+			field{"delay", i(m.Delay)},
+			field{"timeout", i(m.Timeout)},
+			field{"max_retries", i(m.MaxRetries)},
+			field{"url_path", m.URLPath},
+			field{"expected_codes", m.ExpectedCodes},
+			field{"admin_state_up", b(m.AdminStateUp)},
+			field{"provisioning_status", m.ProvisioningStatus})
+	}
+	return result
+}
+
+// join returns the sorted IDs as a comma separated list.
+func join(ids []string) string {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,75 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+func TestCompare(t *testing.T) {
+	old := &Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{
+			{ID: "web", Name: "web", AdminStateUp: true, ProvisioningStatus: "ACTIVE"},
+			{ID: "gone", Name: "gone"},
+		},
+		Pools: []pools.Pool{{ID: "pool", Name: "backends"}},
+		Members: map[string][]pools.Member{
+			"pool": {{ID: "m1", Weight: 1}, {ID: "m2", Weight: 1}},
+		},
+	}
+	new := &Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{
+			{ID: "web", Name: "web", AdminStateUp: false, ProvisioningStatus: "ERROR"},
+			{ID: "new", Name: "new"},
+		},
+		Pools: []pools.Pool{{ID: "pool", Name: "backends"}},
+		Members: map[string][]pools.Member{
+			"pool": {{ID: "m1", Weight: 5}, {ID: "m3", Weight: 1}},
+		},
+	}
+
+	want := []Change{
+		{Type: Removed, Object: client.Object{Kind: client.KindLoadBalancer, ID: "gone"}, Name: "gone"},
+		{Type: Added, Object: client.Object{Kind: client.KindLoadBalancer, ID: "new"}, Name: "new"},
+		{Type: Changed, Object: client.Object{Kind: client.KindLoadBalancer, ID: "web"}, Name: "web", Fields: []FieldChange{
+			{Field: "admin_state_up", Old: "true", New: "false"},
+			{Field: "provisioning_status", Old: "ACTIVE", New: "ERROR"},
+		}},
+		{Type: Changed, Object: client.Object{Kind: client.KindPool, ID: "pool"}, Name: "backends", Fields: []FieldChange{
+			{Field: "members", Old: "m1,m2", New: "m1,m3"},
+		}},
+		{Type: Changed, Object: client.Object{Kind: client.KindMember, ID: "m1"}, Fields: []FieldChange{
+			{Field: "weight", Old: "1", New: "5"},
+		}},
+		{Type: Removed, Object: client.Object{Kind: client.KindMember, ID: "m2"}},
+		{Type: Added, Object: client.Object{Kind: client.KindMember, ID: "m3"}},
+	}
+	d := Compare(old, new)
+	if !reflect.DeepEqual(d.Changes, want) {
+		t.Errorf("got changes\n%+v\nwant\n%+v", d.Changes, want)
+	}
+	if d.Count(Added) != 2 || d.Count(Removed) != 2 || d.Count(Changed) != 3 {
+		t.Errorf("got counts %d/%d/%d, want 2/2/3", d.Count(Added), d.Count(Removed), d.Count(Changed))
+	}
+	if len(Compare(new, new).Changes) != 0 {
+		t.Errorf("an inventory differs from itself")
+	}
+}
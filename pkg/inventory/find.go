@@ -0,0 +1,124 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// Match is an object found by Find.
+type Match struct {
+	// Path leads from the LoadBalancer down to the matching object.
+	Path []Reference
+	// ProjectID is the project of the LoadBalancer.
+	ProjectID string
+	// Field is the field which matched, Value its value.
+	Field string
+	Value string
+}
+
+// Find searches VIP addresses, member addresses, listener and member ports
+// and subnet IDs. The query is an IP address, a CIDR, a port number or a
+// subnet ID. Objects reachable on several paths are listed once per path.
+func (inv *Inventory) Find(query string) []Match {
+	matchAddress := func(address string) bool {
+		return address == query
+	}
+	if _, cidr, err := net.ParseCIDR(query); err == nil {
+		matchAddress = func(address string) bool {
+			ip := net.ParseIP(address)
+			return ip != nil && cidr.Contains(ip)
+		}
+	} else if ip := net.ParseIP(query); ip != nil {
+		matchAddress = func(address string) bool {
+			return ip.Equal(net.ParseIP(address))
+		}
+	}
+	port, err := strconv.Atoi(query)
+	if err != nil {
+		port = -1
+	}
+
+	var matches []Match
+	found := func(path []Reference, projectID, field, value string) {
+		matches = append(matches, Match{Path: append([]Reference(nil), path...), ProjectID: projectID, Field: field, Value: value})
+	}
+	ref := func(kind, id, name string) Reference {
+		return Reference{Object: client.Object{Kind: kind, ID: id}, Name: name}
+	}
+	findMembers := func(path []Reference, projectID string, pool pools.Pool) {
+		for _, m := range inv.Members[pool.ID] {
+			memberPath := append(path, ref(client.KindMember, m.ID, m.Name))
+			if matchAddress(m.Address) {
+				found(memberPath, projectID, "address", m.Address)
+			}
+			if m.ProtocolPort == port {
+				found(memberPath, projectID, "protocol_port", query)
+			}
+			if m.SubnetID != "" && m.SubnetID == query {
+				found(memberPath, projectID, "subnet_id", m.SubnetID)
+			}
+		}
+	}
+	findPool := func(path []Reference, projectID string, pool pools.Pool) {
+		poolPath := append(path, ref(client.KindPool, pool.ID, pool.Name))
+		if pool.SubnetID != "" && pool.SubnetID == query {
+			found(poolPath, projectID, "subnet_id", pool.SubnetID)
+		}
+		findMembers(poolPath, projectID, pool)
+	}
+
+	for _, lb := range inv.LoadBalancers {
+		path := []Reference{ref(client.KindLoadBalancer, lb.ID, lb.Name)}
+		if matchAddress(lb.VipAddress) {
+			found(path, lb.TenantID, "vip_address", lb.VipAddress)
+		}
+		if lb.VipSubnetID == query {
+			found(path, lb.TenantID, "vip_subnet_id", lb.VipSubnetID)
+		}
+		listenerIDs := map[string]bool{}
+		for _, l := range inv.ListenersOf(lb.ID) {
+			listenerIDs[l.ID] = true
+			listenerPath := append(path, ref(client.KindListener, l.ID, l.Name))
+			if l.ProtocolPort == port {
+				found(listenerPath, lb.TenantID, "protocol_port", query)
+			}
+			for _, pool := range inv.PoolsOfListener(l.ID) {
+				findPool(listenerPath, lb.TenantID, pool)
+			}
+		}
+		// pools which are only attached to the LoadBalancer
+		for _, pool := range inv.PoolsOf(lb.ID) {
+			if !attachedToAny(pool, listenerIDs) {
+				findPool(path, lb.TenantID, pool)
+			}
+		}
+	}
+	return matches
+}
+
+func attachedToAny(pool pools.Pool, listenerIDs map[string]bool) bool {
+	for _, l := range pool.Listeners {
+		if listenerIDs[l.ID] {
+			return true
+		}
+	}
+	return false
+}
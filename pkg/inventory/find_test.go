@@ -0,0 +1,78 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+)
+
+func TestFind(t *testing.T) {
+	inv := &Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{{ID: "lb", Name: "web", VipAddress: "10.0.0.10", VipSubnetID: "vip-subnet", TenantID: "project"}},
+		Listeners: []listeners.Listener{{ID: "listener", Name: "http", ProtocolPort: 80,
+			Loadbalancers: []listeners.LoadBalancerID{{ID: "lb"}}}},
+		Pools: []pools.Pool{
+			{ID: "pool", Name: "backends", Listeners: []pools.ListenerID{{ID: "listener"}}},
+			{ID: "spare", Loadbalancers: []pools.LoadBalancerID{{ID: "lb"}}},
+		},
+		Members: map[string][]pools.Member{
+			"pool":  {{ID: "m1", Address: "10.1.0.5", ProtocolPort: 8080, SubnetID: "member-subnet"}},
+			"spare": {{ID: "m2", Address: "10.2.0.5", ProtocolPort: 80}},
+		},
+	}
+	paths := func(matches []Match) []string {
+		var result []string
+		for _, m := range matches {
+			result = append(result, m.Field+": "+formatIDs(m.Path))
+		}
+		return result
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"10.0.0.10", []string{"vip_address: lb"}},
+		{"10.1.0.5", []string{"address: lb/listener/pool/m1"}},
+		{"10.0.0.0/8", []string{"vip_address: lb", "address: lb/listener/pool/m1", "address: lb/spare/m2"}},
+		{"80", []string{"protocol_port: lb/listener", "protocol_port: lb/spare/m2"}},
+		{"member-subnet", []string{"subnet_id: lb/listener/pool/m1"}},
+		{"vip-subnet", []string{"vip_subnet_id: lb"}},
+		{"192.168.0.1", nil},
+	} {
+		if got := paths(inv.Find(tc.query)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Find(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+	if m := inv.Find("10.0.0.10"); len(m) != 1 || m[0].ProjectID != "project" {
+		t.Errorf("got matches %+v, want one of project", m)
+	}
+}
+
+func formatIDs(path []Reference) string {
+	s := ""
+	for i, r := range path {
+		if i > 0 {
+			s += "/"
+		}
+		s += r.ID
+	}
+	return s
+}
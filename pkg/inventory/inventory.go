@@ -0,0 +1,171 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// Inventory holds all LBaaS objects of a tenant and answers questions about
+// how they are connected.
+type Inventory struct {
+	CollectedAt   time.Time                    `json:"collected_at"`
+	Metadata      Metadata                     `json:"metadata"`
+	LoadBalancers []loadbalancers.LoadBalancer `json:"loadbalancers"`
+	Listeners     []listeners.Listener         `json:"listeners"`
+	Pools         []pools.Pool                 `json:"pools"`
+	// Members are keyed by pool ID, since the PoolID field of a member is
+	// not always set by the API.
+	Members    map[string][]pools.Member `json:"members"`
+	Monitors   []monitors.Monitor        `json:"monitors"`
+	L7Policies []l7policies.L7Policy     `json:"l7policies"`
+}
+
+// Collect queries all LBaaS objects of the current tenant.
+func Collect(ctx context.Context, p client.OpenStackProvider) (*Inventory, error) {
+	inv := &Inventory{CollectedAt: time.Now().UTC(), Members: map[string][]pools.Member{}}
+	var err error
+	if inv.LoadBalancers, err = p.ListLBaaS(ctx); err != nil {
+		return nil, err
+	}
+	if inv.Listeners, err = p.ListListenersForCurrentTenant(ctx); err != nil {
+		return nil, err
+	}
+	if inv.Pools, err = p.GetPoolsForCurrentTenant(ctx); err != nil {
+		return nil, err
+	}
+	for _, pool := range inv.Pools {
+		members, err := p.GetMembersForPoolID(ctx, pool.ID)
+		if err != nil {
+			return nil, err
+		}
+		inv.Members[pool.ID] = members
+	}
+	if inv.Monitors, err = p.ListMonitorsForCurrentTenant(ctx); err != nil {
+		return nil, err
+	}
+	if inv.L7Policies, err = p.ListL7PoliciesForCurrentTenant(ctx); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// LoadBalancer returns the LoadBalancer with the given ID.
+func (inv *Inventory) LoadBalancer(id string) (loadbalancers.LoadBalancer, bool) {
+	for _, lb := range inv.LoadBalancers {
+		if lb.ID == id {
+			return lb, true
+		}
+	}
+	return loadbalancers.LoadBalancer{}, false
+}
+
+// ListenersOf returns the listeners attached to a LoadBalancer.
+func (inv *Inventory) ListenersOf(lbID string) []listeners.Listener {
+	var result []listeners.Listener
+	for _, listener := range inv.Listeners {
+		for _, lb := range listener.Loadbalancers {
+			if lb.ID == lbID {
+				result = append(result, listener)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// PoolsOf returns the pools of a LoadBalancer, whether they are attached to
+// one of its listeners or directly to the LoadBalancer.
+func (inv *Inventory) PoolsOf(lbID string) []pools.Pool {
+	listenerIDs := map[string]bool{}
+	for _, listener := range inv.ListenersOf(lbID) {
+		listenerIDs[listener.ID] = true
+	}
+	var result []pools.Pool
+	for _, pool := range inv.Pools {
+		if poolBelongsTo(pool, lbID, listenerIDs) {
+			result = append(result, pool)
+		}
+	}
+	return result
+}
+
+func poolBelongsTo(pool pools.Pool, lbID string, listenerIDs map[string]bool) bool {
+	for _, lb := range pool.Loadbalancers {
+		if lb.ID == lbID {
+			return true
+		}
+	}
+	for _, listener := range pool.Listeners {
+		if listenerIDs[listener.ID] {
+			return true
+		}
+	}
+	return false
+}
+
+// PoolsOfListener returns the pools attached to a listener.
+func (inv *Inventory) PoolsOfListener(listenerID string) []pools.Pool {
+	var result []pools.Pool
+	for _, pool := range inv.Pools {
+		for _, listener := range pool.Listeners {
+			if listener.ID == listenerID {
+				result = append(result, pool)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// L7PoliciesOf returns the L7 policies of a listener.
+func (inv *Inventory) L7PoliciesOf(listenerID string) []l7policies.L7Policy {
+	var result []l7policies.L7Policy
+	for _, policy := range inv.L7Policies {
+		if policy.ListenerID == listenerID {
+			result = append(result, policy)
+		}
+	}
+	return result
+}
+
+// PlanDeletion builds a deletion plan for the given LoadBalancers from the
+// inventory without querying the API again.
+func (inv *Inventory) PlanDeletion(ids []string) (*client.Plan, error) {
+	plan := &client.Plan{}
+	for _, id := range ids {
+		lb, ok := inv.LoadBalancer(id)
+		if !ok {
+			return nil, client.NewNotFoundError(fmt.Sprintf("loadbalancer %s not found", id))
+		}
+		lbListeners := inv.ListenersOf(id)
+		var policies []l7policies.L7Policy
+		for _, listener := range lbListeners {
+			policies = append(policies, inv.L7PoliciesOf(listener.ID)...)
+		}
+		plan.Entries = append(plan.Entries, client.NewPlanEntry(lb, lbListeners, inv.PoolsOf(id), policies))
+	}
+	return plan, nil
+}
@@ -0,0 +1,72 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import "github.com/afritzler/oli/pkg/client"
+
+// Orphans returns the objects none of whose parents are in the inventory:
+// listeners without LoadBalancer, pools without LoadBalancer and listener,
+// health monitors without pool and L7 policies without listener. Members are
+// always listed under their pool and are never orphans.
+func (inv *Inventory) Orphans() []client.Object {
+	exists := map[string]bool{}
+	for _, lb := range inv.LoadBalancers {
+		exists[lb.ID] = true
+	}
+	for _, l := range inv.Listeners {
+		exists[l.ID] = true
+	}
+	for _, p := range inv.Pools {
+		exists[p.ID] = true
+	}
+	var orphans []client.Object
+	add := func(kind, id string, parents []string) {
+		for _, parent := range parents {
+			if exists[parent] {
+				return
+			}
+		}
+		orphans = append(orphans, client.Object{Kind: kind, ID: id})
+	}
+
+	for _, l := range inv.Listeners {
+		var parents []string
+		for _, lb := range l.Loadbalancers {
+			parents = append(parents, lb.ID)
+		}
+		add(client.KindListener, l.ID, parents)
+	}
+	for _, p := range inv.L7Policies {
+		add(client.KindL7Policy, p.ID, []string{p.ListenerID})
+	}
+	for _, p := range inv.Pools {
+		var parents []string
+		for _, lb := range p.Loadbalancers {
+			parents = append(parents, lb.ID)
+		}
+		for _, l := range p.Listeners {
+			parents = append(parents, l.ID)
+		}
+		add(client.KindPool, p.ID, parents)
+	}
+	for _, m := range inv.Monitors {
+		var parents []string
+		for _, p := range m.Pools {
+			parents = append(parents, p.ID)
+		}
+		add(client.KindHealthMonitor, m.ID, parents)
+	}
+	return orphans
+}
@@ -0,0 +1,53 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+func TestOrphans(t *testing.T) {
+	inv := &Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{{ID: "lb"}},
+		Listeners: []listeners.Listener{
+			{ID: "listener", Loadbalancers: []listeners.LoadBalancerID{{ID: "lb"}}},
+			{ID: "lost-listener", Loadbalancers: []listeners.LoadBalancerID{{ID: "gone"}}},
+		},
+		Pools: []pools.Pool{
+			// one of two listeners is enough
+			{ID: "pool", Listeners: []pools.ListenerID{{ID: "gone"}, {ID: "listener"}}},
+			{ID: "lost-pool"},
+		},
+		Monitors:   []monitors.Monitor{{ID: "lost-monitor", Pools: []monitors.PoolID{{ID: "gone"}}}},
+		L7Policies: []l7policies.L7Policy{{ID: "policy", ListenerID: "lost-listener"}},
+	}
+	want := []client.Object{
+		{Kind: client.KindListener, ID: "lost-listener"},
+		{Kind: client.KindPool, ID: "lost-pool"},
+		{Kind: client.KindHealthMonitor, ID: "lost-monitor"},
+	}
+	if got := inv.Orphans(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got orphans %v, want %v", got, want)
+	}
+}
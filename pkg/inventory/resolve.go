@@ -0,0 +1,111 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// References returns all objects of the given kinds, or of all kinds if none
+// are given, sorted by kind and ID.
+func (inv *Inventory) References(kinds ...string) []Reference {
+	wanted := func(kind string) bool {
+		if len(kinds) == 0 {
+			return true
+		}
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	}
+	var refs []Reference
+	add := func(kind, id, name string) {
+		if wanted(kind) {
+			refs = append(refs, Reference{Object: client.Object{Kind: kind, ID: id}, Name: name})
+		}
+	}
+	for _, lb := range inv.LoadBalancers {
+		add(client.KindLoadBalancer, lb.ID, lb.Name)
+	}
+	for _, l := range inv.Listeners {
+		add(client.KindListener, l.ID, l.Name)
+	}
+	for _, p := range inv.Pools {
+		add(client.KindPool, p.ID, p.Name)
+	}
+	for _, members := range inv.Members {
+		for _, m := range members {
+			add(client.KindMember, m.ID, m.Name)
+		}
+	}
+	for _, m := range inv.Monitors {
+		add(client.KindHealthMonitor, m.ID, m.Name)
+	}
+	for _, p := range inv.L7Policies {
+		add(client.KindL7Policy, p.ID, p.Name)
+	}
+	kindIndex := map[string]int{}
+	for i, kind := range kindOrder {
+		kindIndex[kind] = i
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return kindIndex[refs[i].Kind] < kindIndex[refs[j].Kind]
+		}
+		return refs[i].ID < refs[j].ID
+	})
+	return refs
+}
+
+// Resolve finds the object of the given kinds, or of any kind if none are
+// given, referred to by a full ID, a unique ID prefix or an exact name.
+// Names are not unique, if several objects match the error lists them.
+func (inv *Inventory) Resolve(ref string, kinds ...string) (Reference, error) {
+	refs := inv.References(kinds...)
+	for _, r := range refs {
+		if r.ID == ref {
+			return r, nil
+		}
+	}
+	var candidates []Reference
+	if ref != "" {
+		for _, r := range refs {
+			if strings.HasPrefix(r.ID, ref) || r.Name == ref {
+				candidates = append(candidates, r)
+			}
+		}
+	}
+	what := "object"
+	if len(kinds) == 1 {
+		what = kinds[0]
+	}
+	switch len(candidates) {
+	case 0:
+		return Reference{}, client.NewNotFoundError(fmt.Sprintf("no %s with id, id prefix or name %q", what, ref))
+	case 1:
+		return candidates[0], nil
+	}
+	var lines []string
+	for _, c := range candidates {
+		lines = append(lines, fmt.Sprintf("  %s %s %s", c.Kind, c.ID, c.Name))
+	}
+	return Reference{}, fmt.Errorf("%q matches %d objects, use the full id:\n%s", ref, len(candidates), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,69 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+func TestResolve(t *testing.T) {
+	inv := &Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{
+			{ID: "3f2a0c1e-0000-4000-8000-000000000001", Name: "web"},
+			{ID: "3f2b7d9a-0000-4000-8000-000000000002", Name: "api"},
+			{ID: "8c1d5e3b-0000-4000-8000-000000000003", Name: "api"},
+		},
+		Listeners: []listeners.Listener{{ID: "3f2a9999-0000-4000-8000-000000000004", Name: "web"}},
+	}
+
+	for _, tc := range []struct {
+		ref   string
+		kinds []string
+		want  string
+	}{
+		{"3f2b7d9a-0000-4000-8000-000000000002", nil, "3f2b7d9a-0000-4000-8000-000000000002"},
+		{"3f2b", nil, "3f2b7d9a-0000-4000-8000-000000000002"},
+		{"8c", []string{client.KindLoadBalancer}, "8c1d5e3b-0000-4000-8000-000000000003"},
+		{"web", []string{client.KindLoadBalancer}, "3f2a0c1e-0000-4000-8000-000000000001"},
+		{"3f2a", []string{client.KindListener}, "3f2a9999-0000-4000-8000-000000000004"},
+	} {
+		r, err := inv.Resolve(tc.ref, tc.kinds...)
+		if err != nil {
+			t.Errorf("Resolve(%q, %v) failed: %s", tc.ref, tc.kinds, err)
+			continue
+		}
+		if r.ID != tc.want {
+			t.Errorf("Resolve(%q, %v) = %s, want %s", tc.ref, tc.kinds, r.ID, tc.want)
+		}
+	}
+
+	// names are not unique
+	_, err := inv.Resolve("api", client.KindLoadBalancer)
+	if err == nil || !strings.Contains(err.Error(), "3f2b7d9a") || !strings.Contains(err.Error(), "8c1d5e3b") {
+		t.Errorf("got error %v, want both candidates", err)
+	}
+	if _, err := inv.Resolve("3f2a"); err == nil {
+		t.Errorf("ambiguous prefix was resolved")
+	}
+	if _, err := inv.Resolve("unknown"); !isNotFoundError(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
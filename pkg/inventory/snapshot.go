@@ -0,0 +1,72 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Write.
+const SnapshotVersion = 1
+
+// Metadata describes where an inventory was collected.
+type Metadata struct {
+	AuthURL     string `json:"auth_url,omitempty"`
+	Region      string `json:"region,omitempty"`
+	ProjectID   string `json:"project_id,omitempty"`
+	ProjectName string `json:"project_name,omitempty"`
+	Username    string `json:"username,omitempty"`
+}
+
+type snapshot struct {
+	Version int `json:"version"`
+	*Inventory
+}
+
+// Write writes the inventory as a snapshot to w.
+func (inv *Inventory) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(snapshot{Version: SnapshotVersion, Inventory: inv}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %s", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write snapshot: %s", err)
+	}
+	return nil
+}
+
+// Load reads an inventory from a snapshot file.
+func Load(path string) (*Inventory, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %s", err)
+	}
+	s := snapshot{Inventory: &Inventory{}}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %s", path, err)
+	}
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d in %s, expected %d", s.Version, path, SnapshotVersion)
+	}
+	if s.Members == nil {
+		s.Members = map[string][]pools.Member{}
+	}
+	return s.Inventory, nil
+}
@@ -0,0 +1,80 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import "github.com/afritzler/oli/pkg/client"
+
+// Names of the rules checked by Violations.
+const (
+	RuleLoadBalancerError     = "loadbalancer-error"
+	RuleLoadBalancerAdminDown = "loadbalancer-admin-down"
+	RuleLoadBalancerUnnamed   = "loadbalancer-unnamed"
+	RuleListenerWithoutPool   = "listener-without-pool"
+	RulePoolWithoutMembers    = "pool-without-members"
+	RulePoolWithoutMonitor    = "pool-without-monitor"
+	RuleMemberError           = "member-error"
+)
+
+// Violation is an object which breaks one of the rules of good LBaaS
+// hygiene.
+type Violation struct {
+	Rule string `json:"rule"`
+	client.Object
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// Violations checks all objects against the rules:
+//   - LoadBalancers must not be in ERROR, admin down or without a name, which
+//     is how owners recognize them
+//   - listeners need a default pool or an L7 policy to send traffic to
+//   - pools need members and a health monitor
+//   - members must not be in ERROR
+func (inv *Inventory) Violations() []Violation {
+	var result []Violation
+	add := func(rule, kind, id, name, message string) {
+		result = append(result, Violation{Rule: rule, Object: client.Object{Kind: kind, ID: id}, Name: name, Message: message})
+	}
+	for _, lb := range inv.LoadBalancers {
+		if lb.ProvisioningStatus == "ERROR" {
+			add(RuleLoadBalancerError, client.KindLoadBalancer, lb.ID, lb.Name, "provisioning status is ERROR")
+		}
+		if !lb.AdminStateUp {
+			add(RuleLoadBalancerAdminDown, client.KindLoadBalancer, lb.ID, lb.Name, "admin state is down")
+		}
+		if lb.Name == "" {
+			add(RuleLoadBalancerUnnamed, client.KindLoadBalancer, lb.ID, lb.Name, "has no name")
+		}
+	}
+	for _, l := range inv.Listeners {
+		if l.DefaultPoolID == "" && len(inv.L7PoliciesOf(l.ID)) == 0 {
+			add(RuleListenerWithoutPool, client.KindListener, l.ID, l.Name, "has neither a default pool nor L7 policies")
+		}
+	}
+	for _, p := range inv.Pools {
+		if len(inv.Members[p.ID]) == 0 {
+			add(RulePoolWithoutMembers, client.KindPool, p.ID, p.Name, "has no members")
+		}
+		if p.MonitorID == "" {
+			add(RulePoolWithoutMonitor, client.KindPool, p.ID, p.Name, "has no health monitor")
+		}
+		for _, m := range inv.Members[p.ID] {
+			if m.ProvisioningStatus == "ERROR" || m.OperatingStatus == "ERROR" {
+				add(RuleMemberError, client.KindMember, m.ID, m.Name, "member is in ERROR")
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+)
+
+func TestViolations(t *testing.T) {
+	inv := &Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{
+			{ID: "lb-ok", Name: "web", ProvisioningStatus: "ACTIVE", AdminStateUp: true},
+			{ID: "lb-bad", ProvisioningStatus: "ERROR"},
+		},
+		Listeners: []listeners.Listener{
+			{ID: "listener-ok", DefaultPoolID: "pool-ok"},
+			// routes by L7 policies only
+			{ID: "listener-l7"},
+			{ID: "listener-bad"},
+		},
+		L7Policies: []l7policies.L7Policy{{ID: "policy", ListenerID: "listener-l7"}},
+		Pools: []pools.Pool{
+			{ID: "pool-ok", MonitorID: "monitor"},
+			{ID: "pool-bad"},
+		},
+		Members: map[string][]pools.Member{
+			"pool-ok": {{ID: "member-ok", OperatingStatus: "ONLINE"}, {ID: "member-bad", OperatingStatus: "ERROR"}},
+		},
+	}
+	var got []string
+	for _, v := range inv.Violations() {
+		got = append(got, v.Rule+" "+v.ID)
+	}
+	want := []string{
+		"loadbalancer-error lb-bad",
+		"loadbalancer-admin-down lb-bad",
+		"loadbalancer-unnamed lb-bad",
+		"listener-without-pool listener-bad",
+		"member-error member-bad",
+		"pool-without-members pool-bad",
+		"pool-without-monitor pool-bad",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got violations %v, want %v", got, want)
+	}
+}
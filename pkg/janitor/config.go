@@ -0,0 +1,180 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package janitor decides which LoadBalancers to clean up according to a set
+// of policies. Candidates are marked first and only become due for deletion
+// once they have stayed candidates for a grace period.
+package janitor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/afritzler/oli/pkg/cron"
+)
+
+// Policy types
+const (
+	// PolicyEmpty matches LoadBalancers without listeners and pools.
+	PolicyEmpty = "empty"
+	// PolicyError matches LoadBalancers in provisioning or operating
+	// status ERROR.
+	PolicyError = "error"
+	// PolicyKubernetesOrphaned matches LoadBalancers created for a
+	// Kubernetes service of type LoadBalancer which no longer exists.
+	PolicyKubernetesOrphaned = "kubernetes-orphaned"
+	// PolicyExpired matches LoadBalancers whose description holds an
+	// ExpiresMarker with a time in the past.
+	PolicyExpired = "expired"
+	// PolicyIdle matches LoadBalancers whose total connections did not
+	// change and which have no active connections.
+	PolicyIdle = "idle"
+)
+
+// ExpiresMarker in the description of a LoadBalancer is followed by the time
+// it expires, as date 2006-01-02 or in RFC 3339.
+const ExpiresMarker = "oli:expires="
+
+// Config is the policy file of the janitor.
+type Config struct {
+	// Schedule is a cron schedule of the runs.
+	Schedule string `yaml:"schedule"`
+	// State is the file the janitor remembers its observations in between
+	// runs. The marks are kept with the LoadBalancers.
+	State string `yaml:"state"`
+	// GracePeriod is the time a LoadBalancer stays marked before it is
+	// deleted.
+	GracePeriod time.Duration `yaml:"grace_period"`
+	// DryRun only reports what would be deleted. It has to be disabled
+	// explicitly.
+	DryRun bool `yaml:"dry_run"`
+	// BackupDir is where LoadBalancers are backed up before they are
+	// deleted. No backups are taken if it is empty.
+	BackupDir string   `yaml:"backup_dir"`
+	Policies  []Policy `yaml:"policies"`
+
+	schedule *cron.Schedule
+}
+
+// Policy is a single cleanup rule.
+type Policy struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// For is the time the condition has to hold before a LoadBalancer
+	// matches. It is ignored by expired policies.
+	For time.Duration `yaml:"for"`
+	// NamePattern restricts the policy to LoadBalancers whose name matches
+	// the regular expression.
+	NamePattern string `yaml:"name_pattern"`
+	// Cluster is the name of the Kubernetes cluster of
+	// kubernetes-orphaned policies.
+	Cluster string `yaml:"cluster"`
+	// Command lists the services of the cluster as JSON, by default
+	// "kubectl get services --all-namespaces -o json".
+	Command []string `yaml:"command"`
+
+	namePattern *regexp.Regexp
+}
+
+// DefaultServicesCommand lists the services of a Kubernetes cluster.
+var DefaultServicesCommand = []string{"kubectl", "get", "services", "--all-namespaces", "-o", "json"}
+
+// LoadConfig reads and validates a policy file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %s", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates a policy file. Unless given, runs are dry,
+// the grace period is 24 hours and the state is kept in oli-janitor.state.
+func ParseConfig(data []byte) (*Config, error) {
+	c := &Config{
+		State:       "oli-janitor.state",
+		GracePeriod: 24 * time.Hour,
+		DryRun:      true,
+	}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %s", err)
+	}
+	var err error
+	if c.schedule, err = cron.Parse(c.Schedule); err != nil {
+		return nil, err
+	}
+	if c.GracePeriod < 0 {
+		return nil, fmt.Errorf("grace_period must not be negative")
+	}
+	if len(c.Policies) == 0 {
+		return nil, fmt.Errorf("no policies defined")
+	}
+	names := map[string]bool{}
+	for i := range c.Policies {
+		p := &c.Policies[i]
+		if p.Name == "" {
+			p.Name = p.Type
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("policy %q is defined twice", p.Name)
+		}
+		names[p.Name] = true
+		// the names are part of the marks
+		if strings.ContainsAny(p.Name, " \t\n;,/") {
+			return nil, fmt.Errorf("policy %q: name must not contain blanks, \";\", \",\" or \"/\"", p.Name)
+		}
+		switch p.Type {
+		case PolicyEmpty, PolicyError, PolicyExpired, PolicyIdle:
+		case PolicyKubernetesOrphaned:
+			if p.Cluster == "" {
+				return nil, fmt.Errorf("policy %q: cluster is required", p.Name)
+			}
+			if len(p.Command) == 0 {
+				p.Command = DefaultServicesCommand
+			}
+		default:
+			return nil, fmt.Errorf("policy %q: unknown type %q", p.Name, p.Type)
+		}
+		if p.For < 0 {
+			return nil, fmt.Errorf("policy %q: for must not be negative", p.Name)
+		}
+		if p.NamePattern != "" {
+			if p.namePattern, err = regexp.Compile(p.NamePattern); err != nil {
+				return nil, fmt.Errorf("policy %q: invalid name_pattern: %s", p.Name, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// Next returns the time of the first run after t.
+func (c *Config) Next(t time.Time) time.Time {
+	return c.schedule.Next(t)
+}
+
+// NeedsStats tells whether a policy needs the traffic statistics of the
+// LoadBalancers.
+func (c *Config) NeedsStats() bool {
+	for _, p := range c.Policies {
+		if p.Type == PolicyIdle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,332 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package janitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// Candidate is a LoadBalancer matched by at least one policy.
+type Candidate struct {
+	LoadBalancerID string    `json:"loadbalancer_id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Policies       []string  `json:"policies"`
+	MarkedAt       time.Time `json:"marked_at,omitempty"`
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	Time time.Time `json:"time"`
+	// Protected candidates are never marked.
+	Protected []Candidate `json:"protected"`
+	// Marked candidates were marked by this run.
+	Marked []Candidate `json:"marked"`
+	// Pending candidates are marked and still in their grace period.
+	Pending []Candidate `json:"pending"`
+	// Due candidates were marked at least a grace period ago and are to
+	// be deleted.
+	Due []Candidate `json:"due"`
+	// Unmarked holds the IDs of LoadBalancers which are no longer matched
+	// or protected now and whose mark was removed.
+	Unmarked []string `json:"unmarked"`
+}
+
+// DueIDs returns the IDs of the due candidates.
+func (r *Result) DueIDs() []string {
+	var ids []string
+	for _, c := range r.Due {
+		ids = append(ids, c.LoadBalancerID)
+	}
+	return ids
+}
+
+// Service is a Kubernetes service of type LoadBalancer.
+type Service struct {
+	Namespace string
+	Name      string
+}
+
+// MarkCriterion is the first criterion of the marks set by the janitor. It
+// tells them apart from the marks set by "oli mark", which are left alone.
+const MarkCriterion = "janitor"
+
+// Marker reads and writes the marks of LoadBalancers. The
+// client.OpenStackProvider keeps them in the cloud.
+type Marker interface {
+	GetLoadBalancerMark(ctx context.Context, id string) (*client.Mark, error)
+	MarkLoadBalancer(ctx context.Context, id string, at time.Time, criteria []string) (*client.Mark, error)
+	UnmarkLoadBalancer(ctx context.Context, id string) error
+}
+
+// Janitor evaluates the policies of a config and keeps its state.
+type Janitor struct {
+	Config *Config
+	State  *State
+	Marks  Marker
+	// Services lists the Kubernetes services of type LoadBalancer with the
+	// command of a kubernetes-orphaned policy.
+	Services func(ctx context.Context, command []string) ([]Service, error)
+}
+
+// New returns a janitor which keeps its marks with m and lists Kubernetes
+// services with kubectl.
+func New(c *Config, s *State, m Marker) *Janitor {
+	return &Janitor{Config: c, State: s, Marks: m, Services: listServices}
+}
+
+// Run evaluates all policies against the inventory at time now, marks new
+// candidates, removes the marks of LoadBalancers which are no longer matched
+// and returns which candidates are due. stats holds the traffic
+// statistics per LoadBalancer ID, it is only used by idle policies.
+// LoadBalancers missing from stats are not considered idle.
+func (j *Janitor) Run(ctx context.Context, inv *inventory.Inventory, stats map[string]*loadbalancers.Stats, now time.Time) (*Result, error) {
+	j.observeTraffic(inv, stats, now)
+	matched := map[string][]string{}
+	for _, p := range j.Config.Policies {
+		since, err := j.evaluate(ctx, p, inv, stats, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate policy %q: %s", p.Name, err)
+		}
+		for id, t := range since {
+			if p.Type == PolicyExpired || now.Sub(t) >= p.For {
+				matched[id] = append(matched[id], p.Name)
+			}
+		}
+	}
+
+	result := &Result{Time: now}
+	for _, lb := range inv.LoadBalancers {
+		mark, err := j.Marks.GetLoadBalancerMark(ctx, lb.ID)
+		if err != nil {
+			return nil, err
+		}
+		if mark != nil && !isJanitorMark(mark) {
+			continue
+		}
+		policies, ok := matched[lb.ID]
+		protected := client.IsProtected(lb.Description)
+		if (!ok || protected) && mark != nil {
+			if err := j.Marks.UnmarkLoadBalancer(ctx, lb.ID); err != nil {
+				return nil, err
+			}
+			result.Unmarked = append(result.Unmarked, lb.ID)
+		}
+		if !ok {
+			continue
+		}
+		c := Candidate{LoadBalancerID: lb.ID, Name: lb.Name, Description: lb.Description, Policies: policies}
+		switch {
+		case protected:
+			result.Protected = append(result.Protected, c)
+		case mark == nil:
+			if mark, err = j.Marks.MarkLoadBalancer(ctx, lb.ID, now, append([]string{MarkCriterion}, policies...)); err != nil {
+				return nil, err
+			}
+			c.MarkedAt = mark.At
+			// never due in the run which marks it
+			result.Marked = append(result.Marked, c)
+		case now.Sub(mark.At) >= j.Config.GracePeriod:
+			c.MarkedAt = mark.At
+			result.Due = append(result.Due, c)
+		default:
+			c.MarkedAt = mark.At
+			result.Pending = append(result.Pending, c)
+		}
+	}
+	sort.Strings(result.Unmarked)
+	return result, nil
+}
+
+func isJanitorMark(mark *client.Mark) bool {
+	return len(mark.Criteria) > 0 && mark.Criteria[0] == MarkCriterion
+}
+
+// evaluate returns since when the condition of a policy holds for every
+// LoadBalancer it holds for.
+func (j *Janitor) evaluate(ctx context.Context, p Policy, inv *inventory.Inventory, stats map[string]*loadbalancers.Stats, now time.Time) (map[string]time.Time, error) {
+	var holds func(lb loadbalancers.LoadBalancer) bool
+	switch p.Type {
+	case PolicyEmpty:
+		holds = func(lb loadbalancers.LoadBalancer) bool {
+			return len(inv.ListenersOf(lb.ID)) == 0 && len(inv.PoolsOf(lb.ID)) == 0
+		}
+	case PolicyError:
+		holds = func(lb loadbalancers.LoadBalancer) bool {
+			return lb.ProvisioningStatus == "ERROR" || lb.OperatingStatus == "ERROR"
+		}
+	case PolicyKubernetesOrphaned:
+		services, err := j.Services(ctx, p.Command)
+		if err != nil {
+			return nil, err
+		}
+		existing := map[string]bool{}
+		for _, s := range services {
+			existing[s.Namespace+"/"+s.Name] = true
+		}
+		holds = func(lb loadbalancers.LoadBalancer) bool {
+			s, ok := kubernetesService(lb, p.Cluster)
+			return ok && !existing[s.Namespace+"/"+s.Name]
+		}
+	case PolicyExpired:
+		since := map[string]time.Time{}
+		for _, lb := range inv.LoadBalancers {
+			if expires, ok := Expires(lb.Description); ok && p.matchesName(lb.Name) && !expires.After(now) {
+				since[lb.ID] = expires
+			}
+		}
+		return since, nil
+	case PolicyIdle:
+		since := map[string]time.Time{}
+		for _, lb := range inv.LoadBalancers {
+			if s, ok := stats[lb.ID]; ok && s.ActiveConnections == 0 && p.matchesName(lb.Name) {
+				since[lb.ID] = j.State.Traffic[lb.ID].Since
+			}
+		}
+		return since, nil
+	}
+
+	old := j.State.Since[p.Name]
+	since := map[string]time.Time{}
+	for _, lb := range inv.LoadBalancers {
+		if !p.matchesName(lb.Name) || !holds(lb) {
+			continue
+		}
+		if t, ok := old[lb.ID]; ok {
+			since[lb.ID] = t
+		} else {
+			since[lb.ID] = now
+		}
+	}
+	j.State.Since[p.Name] = since
+	return since, nil
+}
+
+// observeTraffic records since when every LoadBalancer had no new and no
+// active connections.
+func (j *Janitor) observeTraffic(inv *inventory.Inventory, stats map[string]*loadbalancers.Stats, now time.Time) {
+	traffic := map[string]Traffic{}
+	for _, lb := range inv.LoadBalancers {
+		last, seen := j.State.Traffic[lb.ID]
+		s, ok := stats[lb.ID]
+		switch {
+		case !ok:
+			// keep what we know until the statistics are back
+			if seen {
+				traffic[lb.ID] = last
+			}
+		case seen && last.TotalConnections == s.TotalConnections && s.ActiveConnections == 0:
+			traffic[lb.ID] = Traffic{TotalConnections: s.TotalConnections, Since: last.Since}
+		default:
+			// open connections keep a LoadBalancer busy even without new ones
+			traffic[lb.ID] = Traffic{TotalConnections: s.TotalConnections, ActiveConnections: s.ActiveConnections, Since: now}
+		}
+	}
+	j.State.Traffic = traffic
+}
+
+func (p Policy) matchesName(name string) bool {
+	return p.namePattern == nil || p.namePattern.MatchString(name)
+}
+
+// Expires returns the time after the ExpiresMarker in a description.
+func Expires(description string) (time.Time, bool) {
+	i := strings.Index(description, ExpiresMarker)
+	if i < 0 {
+		return time.Time{}, false
+	}
+	value := description[i+len(ExpiresMarker):]
+	if end := strings.IndexAny(value, " \t\n,;"); end >= 0 {
+		value = value[:end]
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+var kubernetesDescription = regexp.MustCompile(`^Kubernetes external service (\S+)/(\S+) from cluster (\S+)`)
+
+// kubernetesService returns the service a LoadBalancer was created for by the
+// OpenStack cloud provider of the given cluster. The provider names them
+// kube_service_<cluster>_<namespace>_<service> and describes them as
+// "Kubernetes external service <namespace>/<service> from cluster <cluster>".
+func kubernetesService(lb loadbalancers.LoadBalancer, cluster string) (Service, bool) {
+	if m := kubernetesDescription.FindStringSubmatch(lb.Description); m != nil {
+		return Service{Namespace: m[1], Name: m[2]}, m[3] == cluster
+	}
+	prefix := "kube_service_" + cluster + "_"
+	if !strings.HasPrefix(lb.Name, prefix) {
+		return Service{}, false
+	}
+	// namespaces are DNS labels and never contain an underscore
+	parts := strings.SplitN(strings.TrimPrefix(lb.Name, prefix), "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Service{}, false
+	}
+	return Service{Namespace: parts[0], Name: parts[1]}, true
+}
+
+// listServices runs command and returns the services of type LoadBalancer of
+// the service list it prints.
+func listServices(ctx context.Context, command []string) ([]Service, error) {
+	out, err := exec.CommandContext(ctx, command[0], command[1:]...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("failed to list kubernetes services: %s: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to list kubernetes services: %s", err)
+	}
+	return parseServices(out)
+}
+
+func parseServices(data []byte) ([]Service, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Spec struct {
+				Type string `json:"type"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse kubernetes services: %s", err)
+	}
+	var services []Service
+	for _, item := range list.Items {
+		if item.Spec.Type == "LoadBalancer" {
+			services = append(services, Service{Namespace: item.Metadata.Namespace, Name: item.Metadata.Name})
+		}
+	}
+	return services, nil
+}
@@ -0,0 +1,248 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package janitor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+const testConfig = `
+schedule: "*/30 * * * *"
+grace_period: 2h
+policies:
+- type: empty
+  for: 1h
+- name: broken
+  type: error
+  for: 3h
+  name_pattern: ^test-
+- type: kubernetes-orphaned
+  cluster: prod
+- type: expired
+- type: idle
+  for: 1h
+`
+
+// marks keeps the marks of LoadBalancers in memory.
+type marks map[string]*client.Mark
+
+func (m marks) GetLoadBalancerMark(ctx context.Context, id string) (*client.Mark, error) {
+	return m[id], nil
+}
+
+func (m marks) MarkLoadBalancer(ctx context.Context, id string, at time.Time, criteria []string) (*client.Mark, error) {
+	if mark, ok := m[id]; ok {
+		return mark, nil
+	}
+	m[id] = &client.Mark{At: at, Criteria: criteria}
+	return m[id], nil
+}
+
+func (m marks) UnmarkLoadBalancer(ctx context.Context, id string) error {
+	delete(m, id)
+	return nil
+}
+
+func ids(candidates []Candidate) []string {
+	var result []string
+	for _, c := range candidates {
+		result = append(result, c.LoadBalancerID)
+	}
+	return result
+}
+
+func TestRun(t *testing.T) {
+	c, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.DryRun {
+		t.Errorf("runs are not dry by default")
+	}
+	inv := &inventory.Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{
+			{ID: "lb-empty", Name: "spare"},
+			{ID: "lb-protected", Name: "keep", Description: "oli:protected"},
+			{ID: "lb-error", Name: "test-1", ProvisioningStatus: "ERROR"},
+			{ID: "lb-error-other", Name: "prod-1", ProvisioningStatus: "ERROR"},
+			{ID: "lb-k8s-gone", Name: "kube_service_prod_shop_web"},
+			{ID: "lb-k8s-live", Name: "kube_service_prod_shop_api"},
+			{ID: "lb-k8s-other", Name: "kube_service_dev_shop_web"},
+			{ID: "lb-k8s-desc", Description: "Kubernetes external service shop/cart from cluster prod"},
+			{ID: "lb-expired", Description: "demo oli:expires=2018-10-01"},
+			{ID: "lb-idle", Name: "idle"},
+			{ID: "lb-busy", Name: "busy"},
+			{ID: "lb-streaming", Name: "streaming"},
+			{ID: "lb-swept", Name: "swept"},
+		},
+	}
+	// every LoadBalancer but the empty and the protected one has a listener
+	for _, lb := range inv.LoadBalancers[2:] {
+		inv.Listeners = append(inv.Listeners, listeners.Listener{ID: "listener-" + lb.ID, Loadbalancers: []listeners.LoadBalancerID{{ID: lb.ID}}})
+	}
+	// a mark of "oli mark" is left alone
+	swept := &client.Mark{At: time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC), Criteria: []string{"empty"}}
+	m := marks{"lb-swept": swept}
+	j := New(c, NewState(), m)
+	j.Services = func(ctx context.Context, command []string) ([]Service, error) {
+		if !reflect.DeepEqual(command, DefaultServicesCommand) {
+			t.Errorf("got command %v", command)
+		}
+		return []Service{{Namespace: "shop", Name: "api"}}, nil
+	}
+	connections := 0
+	run := func(now time.Time) *Result {
+		connections += 10
+		stats := map[string]*loadbalancers.Stats{
+			"lb-idle": {TotalConnections: 5},
+			"lb-busy": {TotalConnections: connections},
+			// a single long lived connection
+			"lb-streaming": {TotalConnections: 1, ActiveConnections: 1},
+		}
+		result, err := j.Run(context.Background(), inv, stats, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	start := time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC)
+	result := run(start)
+	// only conditions without a duration match on the first run
+	if got, want := ids(result.Marked), []string{"lb-k8s-gone", "lb-k8s-desc", "lb-expired"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first run marked %v, want %v", got, want)
+	}
+
+	result = run(start.Add(90 * time.Minute))
+	if got, want := ids(result.Marked), []string{"lb-empty", "lb-idle"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second run marked %v, want %v", got, want)
+	}
+	if got, want := ids(result.Protected), []string{"lb-protected"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got protected %v, want %v", got, want)
+	}
+	if got, want := ids(result.Pending), []string{"lb-k8s-gone", "lb-k8s-desc", "lb-expired"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got pending %v, want %v", got, want)
+	}
+	if len(result.Due) != 0 {
+		t.Errorf("got due %v in the grace period", ids(result.Due))
+	}
+
+	// the service is back
+	j.Services = func(ctx context.Context, command []string) ([]Service, error) {
+		return []Service{{Namespace: "shop", Name: "api"}, {Namespace: "shop", Name: "cart"}}, nil
+	}
+	result = run(start.Add(3 * time.Hour))
+	if got, want := ids(result.Due), []string{"lb-k8s-gone", "lb-expired"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got due %v, want %v", got, want)
+	}
+	if got, want := result.Unmarked, []string{"lb-k8s-desc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got unmarked %v, want %v", got, want)
+	}
+	if got, want := ids(result.Marked), []string{"lb-error"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("third run marked %v, want %v", got, want)
+	}
+	if got, want := m["lb-empty"].Criteria, []string{MarkCriterion, "empty"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lb-empty was marked with %v, want %v", got, want)
+	}
+	if _, ok := m["lb-k8s-desc"]; ok {
+		t.Errorf("the mark of lb-k8s-desc was not removed")
+	}
+	if m["lb-swept"] != swept {
+		t.Errorf("the mark of lb-swept was changed to %+v", m["lb-swept"])
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for _, config := range []string{
+		"schedule: '* * *'\npolicies: [{type: empty}]",
+		"schedule: '@daily'",
+		"schedule: '@daily'\npolicies: [{type: unused}]",
+		"schedule: '@daily'\npolicies: [{type: empty}, {type: empty}]",
+		"schedule: '@daily'\npolicies: [{type: kubernetes-orphaned}]",
+		"schedule: '@daily'\npolicies: [{type: empty, name_pattern: '('}]",
+		"schedule: '@daily'\npolicies: [{type: empty, for: -1h}]",
+		"schedule: '@daily'\npolicies: [{type: empty, since: 1h}]",
+		"schedule: '@daily'\npolicies: [{name: 'no spaces', type: empty}]",
+	} {
+		if _, err := ParseConfig([]byte(config)); err == nil {
+			t.Errorf("%q was parsed", config)
+		}
+	}
+}
+
+func TestExpires(t *testing.T) {
+	for description, want := range map[string]string{
+		"oli:expires=2018-10-01":                    "2018-10-01T00:00:00Z",
+		"demo, oli:expires=2018-10-01T12:00:00Z ok": "2018-10-01T12:00:00Z",
+		"oli:expires=soon":                          "",
+		"demo":                                      "",
+	} {
+		got, ok := Expires(description)
+		if (ok && got.Format(time.RFC3339) != want) || (!ok && want != "") {
+			t.Errorf("%q: got %s, %v, want %q", description, got, ok, want)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-janitor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state")
+	s, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("failed to load missing state: %s", err)
+	}
+	at := time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC)
+	s.Since["empty"] = map[string]time.Time{"lb": at}
+	s.Traffic["lb"] = Traffic{TotalConnections: 5, Since: at}
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, s) {
+		t.Errorf("got state %+v, want %+v", loaded, s)
+	}
+}
+
+func TestParseServices(t *testing.T) {
+	services, err := parseServices([]byte(`{"items": [
+		{"metadata": {"name": "web", "namespace": "shop"}, "spec": {"type": "LoadBalancer"}},
+		{"metadata": {"name": "db", "namespace": "shop"}, "spec": {"type": "ClusterIP"}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Service{{Namespace: "shop", Name: "web"}}; !reflect.DeepEqual(services, want) {
+		t.Errorf("got services %v, want %v", services, want)
+	}
+}
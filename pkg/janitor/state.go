@@ -0,0 +1,98 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package janitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is what the janitor remembers in between runs.
+type State struct {
+	// Since records per policy and LoadBalancer since when the condition
+	// of the policy holds.
+	Since map[string]map[string]time.Time `json:"since"`
+	// Traffic records the total connections of every LoadBalancer last
+	// seen and since when they did not change.
+	Traffic map[string]Traffic `json:"traffic"`
+}
+
+// Traffic is the last observed total connections of a LoadBalancer.
+type Traffic struct {
+	TotalConnections  int       `json:"total_connections"`
+	ActiveConnections int       `json:"active_connections"`
+	Since             time.Time `json:"since"`
+}
+
+// NewState returns an empty state.
+func NewState() *State {
+	return &State{
+		Since:   map[string]map[string]time.Time{},
+		Traffic: map[string]Traffic{},
+	}
+}
+
+// LoadState reads the state file at path. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	s := NewState()
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read janitor state: %s", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse janitor state %s: %s", path, err)
+	}
+	if s.Since == nil {
+		s.Since = map[string]map[string]time.Time{}
+	}
+	if s.Traffic == nil {
+		s.Traffic = map[string]Traffic{}
+	}
+	return s, nil
+}
+
+// Save writes the state to path. The file is replaced atomically, so an
+// interrupted run never leaves a truncated state behind.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode janitor state: %s", err)
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".")
+	if err != nil {
+		return fmt.Errorf("failed to write janitor state: %s", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write janitor state: %s", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write janitor state: %s", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write janitor state: %s", err)
+	}
+	return nil
+}
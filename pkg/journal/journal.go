@@ -0,0 +1,188 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package journal implements the checkpoint file of a delete run. The journal
+// is a JSON lines file: the first line holds the plan, every following line
+// records one object which was confirmed deleted.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+const (
+	recordPlan    = "plan"
+	recordDeleted = "deleted"
+)
+
+type record struct {
+	Type           string         `json:"type"`
+	Time           time.Time      `json:"time"`
+	Plan           *client.Plan   `json:"plan,omitempty"`
+	LoadBalancerID string         `json:"loadbalancer_id,omitempty"`
+	Object         *client.Object `json:"object,omitempty"`
+}
+
+// Journal records the progress of a delete run.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	// Plan is the plan the run was started with.
+	Plan client.Plan
+	// Done holds the IDs of all objects recorded as deleted.
+	Done map[string]bool
+}
+
+// Create starts a new journal for plan at path. An existing file is never
+// overwritten.
+func Create(path string, plan client.Plan) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal %s", err)
+	}
+	j := &Journal{file: f, Plan: plan, Done: map[string]bool{}}
+	if err := j.write(record{Type: recordPlan, Plan: &plan}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// Open reads an existing journal and opens it for appending further records.
+// A truncated last line, e.g. from a killed run, is cut off so that the next
+// record starts on a line of its own.
+func Open(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal %s", err)
+	}
+	j := &Journal{file: f, Done: map[string]bool{}}
+	reader := bufio.NewReader(f)
+	var hasPlan bool
+	// complete is the size of all lines ending with a newline
+	var complete int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			f.Close()
+			return nil, fmt.Errorf("failed to read journal %s", err)
+		}
+		if len(data) == 0 {
+			break
+		}
+		var r record
+		if uerr := json.Unmarshal(data, &r); uerr != nil {
+			fmt.Fprintf(os.Stderr, "ignoring unreadable line %d of journal %s\n", line, path)
+		} else {
+			switch r.Type {
+			case recordPlan:
+				if r.Plan != nil {
+					j.Plan = *r.Plan
+					hasPlan = true
+				}
+			case recordDeleted:
+				if r.Object != nil {
+					j.Done[r.Object.ID] = true
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		complete += int64(len(data))
+	}
+	if !hasPlan {
+		f.Close()
+		return nil, fmt.Errorf("journal %s contains no plan", path)
+	}
+	if err := j.terminate(complete); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// terminate makes sure the journal ends with a newline. A last line without
+// one is cut off at complete, unless it holds a readable record.
+func (j *Journal) terminate(complete int64) error {
+	info, err := j.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read journal %s", err)
+	}
+	if info.Size() == complete {
+		return nil
+	}
+	if _, err := j.file.Seek(complete, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read journal %s", err)
+	}
+	data, err := ioutil.ReadAll(j.file)
+	if err != nil {
+		return fmt.Errorf("failed to read journal %s", err)
+	}
+	var r record
+	if json.Unmarshal(data, &r) == nil {
+		_, err = j.file.Write([]byte{'\n'})
+	} else {
+		err = j.file.Truncate(complete)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to repair journal %s", err)
+	}
+	return j.file.Sync()
+}
+
+// Record marks an object as deleted. The record is synced to disk before
+// Record returns.
+func (j *Journal) Record(loadbalancerID string, obj client.Object) error {
+	j.mu.Lock()
+	j.Done[obj.ID] = true
+	j.mu.Unlock()
+	return j.write(record{Type: recordDeleted, LoadBalancerID: loadbalancerID, Object: &obj})
+}
+
+// Name returns the path of the journal file.
+func (j *Journal) Name() string {
+	return j.file.Name()
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+func (j *Journal) write(r record) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	r.Time = time.Now().UTC()
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal record %s", err)
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal %s", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+var testPlan = client.Plan{Entries: []client.PlanEntry{{
+	LoadBalancerID: "lb-1",
+	Name:           "web",
+	Steps: []client.Object{
+		{Kind: client.KindListener, ID: "listener-1"},
+		{Kind: client.KindLoadBalancer, ID: "lb-1"},
+	},
+}}}
+
+func tempJournal(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "oli-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "test.journal"), func() { os.RemoveAll(dir) }
+}
+
+func TestCreateAndOpen(t *testing.T) {
+	path, cleanup := tempJournal(t)
+	defer cleanup()
+
+	j, err := Create(path, testPlan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Record("lb-1", testPlan.Entries[0].Steps[0]); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	if _, err := Create(path, testPlan); err == nil {
+		t.Errorf("Create overwrote an existing journal")
+	}
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if !reflect.DeepEqual(j.Plan, testPlan) {
+		t.Errorf("got plan %+v, want %+v", j.Plan, testPlan)
+	}
+	if want := map[string]bool{"listener-1": true}; !reflect.DeepEqual(j.Done, want) {
+		t.Errorf("got done %v, want %v", j.Done, want)
+	}
+}
+
+func TestResume(t *testing.T) {
+	path, cleanup := tempJournal(t)
+	defer cleanup()
+
+	j, err := Create(path, testPlan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Record("lb-1", testPlan.Entries[0].Steps[0])
+	j.Close()
+
+	// the resumed run appends to the journal
+	j, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Record("lb-1", testPlan.Entries[0].Steps[1])
+	j.Close()
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if want := map[string]bool{"listener-1": true, "lb-1": true}; !reflect.DeepEqual(j.Done, want) {
+		t.Errorf("got done %v, want %v", j.Done, want)
+	}
+}
+
+func TestOpenTruncated(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		tail string
+		want map[string]bool
+	}{
+		{"cut off record", `{"type":"deleted","object":{"kind":"listener","id":"list`, map[string]bool{"lb-1": true}},
+		{"missing newline", `{"type":"deleted","object":{"kind":"listener","id":"listener-1"}}`, map[string]bool{"listener-1": true, "lb-1": true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path, cleanup := tempJournal(t)
+			defer cleanup()
+
+			j, err := Create(path, testPlan)
+			if err != nil {
+				t.Fatal(err)
+			}
+			j.Close()
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(tc.tail)
+			f.Close()
+
+			j, err = Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			j.Record("lb-1", testPlan.Entries[0].Steps[1])
+			j.Close()
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+				if !bytes.HasPrefix(line, []byte("{")) || !bytes.HasSuffix(line, []byte("}")) {
+					t.Errorf("line %d is not a record: %s", i+1, line)
+				}
+			}
+			j, err = Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer j.Close()
+			if !reflect.DeepEqual(j.Done, tc.want) {
+				t.Errorf("got done %v, want %v", j.Done, tc.want)
+			}
+		})
+	}
+}
+
+func TestOpenWithoutPlan(t *testing.T) {
+	path, cleanup := tempJournal(t)
+	defer cleanup()
+	if err := ioutil.WriteFile(path, []byte(`{"type":"deleted","object":{"kind":"listener","id":"listener-1"}}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Errorf("opened a journal without a plan")
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exports the state of the LBaaS objects of a tenant in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// Collection stages, used as label of the error counter.
+const (
+	StageInventory = "inventory"
+	StageStats     = "stats"
+)
+
+// Exporter collects the inventory on every Refresh and serves the metrics of
+// the last successful collection. Refresh and ServeHTTP may be called
+// concurrently.
+type Exporter struct {
+	client client.OpenStackProvider
+	// Stats enables the collection of the traffic statistics of every
+	// LoadBalancer, one API call each.
+	Stats bool
+
+	mu          sync.Mutex
+	families    []*family
+	collections float64
+	errors      map[string]float64
+	duration    float64
+	lastSuccess time.Time
+}
+
+// NewExporter returns an Exporter collecting with c.
+func NewExporter(c client.OpenStackProvider, stats bool) *Exporter {
+	return &Exporter{client: c, Stats: stats, errors: map[string]float64{StageInventory: 0, StageStats: 0}}
+}
+
+// Refresh collects the inventory and, if enabled, the statistics. If the
+// inventory cannot be collected the previous metrics are kept. Statistics
+// of single LoadBalancers which cannot be read are left out.
+func (e *Exporter) Refresh(ctx context.Context) error {
+	start := time.Now()
+	inv, err := inventory.Collect(ctx, e.client)
+	if err != nil {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.collections++
+		e.errors[StageInventory]++
+		e.duration = time.Since(start).Seconds()
+		return err
+	}
+	families := inventoryFamilies(inv)
+
+	var firstErr error
+	statsErrors := 0
+	if e.Stats {
+		stats := statsFamilies()
+		for _, lb := range inv.LoadBalancers {
+			s, err := e.client.GetLoadBalancerStats(ctx, lb.ID)
+			if err != nil {
+				statsErrors++
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to get stats of loadbalancer %s: %s", lb.ID, err)
+				}
+				continue
+			}
+			labels := []label{{"loadbalancer_id", lb.ID}, {"name", lb.Name}}
+			stats[0].add(float64(s.ActiveConnections), labels...)
+			stats[1].add(float64(s.TotalConnections), labels...)
+			stats[2].add(float64(s.BytesIn), labels...)
+			stats[3].add(float64(s.BytesOut), labels...)
+			stats[4].add(float64(s.RequestErrors), labels...)
+		}
+		families = append(families, stats...)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.families = families
+	e.collections++
+	e.errors[StageStats] += float64(statsErrors)
+	e.duration = time.Since(start).Seconds()
+	e.lastSuccess = time.Now()
+	return firstErr
+}
+
+// ServeHTTP writes the metrics in the text exposition format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the text exposition format.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.Lock()
+	families := append([]*family(nil), e.families...)
+	collections := &family{name: "oli_collections_total", help: "Number of inventory collections.", typ: "counter"}
+	collections.add(e.collections)
+	errors := &family{name: "oli_collection_errors_total", help: "Number of failed inventory and stats collections.", typ: "counter"}
+	for stage, n := range e.errors {
+		errors.add(n, label{"stage", stage})
+	}
+	duration := &family{name: "oli_collection_duration_seconds", help: "Duration of the last collection.", typ: "gauge"}
+	duration.add(e.duration)
+	lastSuccess := &family{name: "oli_last_successful_collection_timestamp_seconds", help: "Time of the last successful inventory collection.", typ: "gauge"}
+	if !e.lastSuccess.IsZero() {
+		lastSuccess.add(float64(e.lastSuccess.UnixNano()) / 1e9)
+	}
+	e.mu.Unlock()
+
+	families = append(families, collections, errors, duration, lastSuccess)
+	cw := &countingWriter{w: w}
+	for _, f := range families {
+		f.write(cw)
+	}
+	return cw.n, cw.err
+}
+
+// inventoryFamilies counts the objects by kind and status, empty
+// LoadBalancers and orphans.
+func inventoryFamilies(inv *inventory.Inventory) []*family {
+	status := func(name, help string) *family {
+		return &family{name: name, help: help, typ: "gauge"}
+	}
+	lbs := status("oli_loadbalancers", "Number of LoadBalancers by provisioning and operating status.")
+	listeners := status("oli_listeners", "Number of listeners by provisioning status.")
+	pools := status("oli_pools", "Number of pools by provisioning and operating status.")
+	members := status("oli_members", "Number of members by provisioning and operating status.")
+	monitors := status("oli_health_monitors", "Number of health monitors by provisioning status.")
+	policies := status("oli_l7_policies", "Number of L7 policies by provisioning and operating status.")
+	empty := status("oli_empty_loadbalancers", "Number of LoadBalancers without listeners and pools.")
+	orphans := status("oli_orphans", "Number of objects whose parents do not exist, by kind.")
+
+	both := func(provisioning, operating string) []label {
+		return []label{{"operating_status", operating}, {"provisioning_status", provisioning}}
+	}
+	emptyCount := 0
+	for _, lb := range inv.LoadBalancers {
+		lbs.inc(both(lb.ProvisioningStatus, lb.OperatingStatus)...)
+		if len(inv.ListenersOf(lb.ID)) == 0 && len(inv.PoolsOf(lb.ID)) == 0 {
+			emptyCount++
+		}
+	}
+	empty.add(float64(emptyCount))
+	for _, l := range inv.Listeners {
+		listeners.inc(label{"provisioning_status", l.ProvisioningStatus})
+	}
+	for _, p := range inv.Pools {
+		pools.inc(both(p.ProvisioningStatus, p.OperatingStatus)...)
+		for _, m := range inv.Members[p.ID] {
+			members.inc(both(m.ProvisioningStatus, m.OperatingStatus)...)
+		}
+	}
+	for _, m := range inv.Monitors {
+		monitors.inc(label{"provisioning_status", m.ProvisioningStatus})
+	}
+	for _, p := range inv.L7Policies {
+		policies.inc(both(p.ProvisioningStatus, p.OperatingStatus)...)
+	}
+	// every kind is exported, so alerts do not depend on absent series
+	for _, kind := range []string{client.KindListener, client.KindPool, client.KindHealthMonitor, client.KindL7Policy} {
+		orphans.add(0, label{"kind", kind})
+	}
+	for _, o := range inv.Orphans() {
+		orphans.inc(label{"kind", o.Kind})
+	}
+	return []*family{lbs, listeners, pools, members, monitors, policies, empty, orphans}
+}
+
+// statsFamilies are the families of the per LoadBalancer statistics, filled
+// by Refresh in this order.
+func statsFamilies() []*family {
+	return []*family{
+		{name: "oli_loadbalancer_active_connections", help: "Active connections of a LoadBalancer.", typ: "gauge"},
+		{name: "oli_loadbalancer_connections_total", help: "Total connections handled by a LoadBalancer.", typ: "counter"},
+		{name: "oli_loadbalancer_bytes_in_total", help: "Bytes received by a LoadBalancer.", typ: "counter"},
+		{name: "oli_loadbalancer_bytes_out_total", help: "Bytes sent by a LoadBalancer.", typ: "counter"},
+		{name: "oli_loadbalancer_request_errors_total", help: "Request errors of a LoadBalancer.", typ: "counter"},
+	}
+}
+
+type label struct {
+	name, value string
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+// family is a metric with all its samples.
+type family struct {
+	name, help, typ string
+	samples         []*sample
+}
+
+// add sets the sample with the given labels, which must be sorted by name.
+func (f *family) add(value float64, labels ...label) {
+	f.sample(labels).value = value
+}
+
+// inc increments the sample with the given labels, which must be sorted by
+// name.
+func (f *family) inc(labels ...label) {
+	f.sample(labels).value++
+}
+
+func (f *family) sample(labels []label) *sample {
+	for _, s := range f.samples {
+		if equalLabels(s.labels, labels) {
+			return s
+		}
+	}
+	s := &sample{labels: labels}
+	f.samples = append(f.samples, s)
+	return s
+}
+
+func equalLabels(a, b []label) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// write writes the family with its samples sorted by labels.
+func (f *family) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+	var lines []string
+	for _, s := range f.samples {
+		var pairs []string
+		for _, l := range s.labels {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, l.name, labelEscaper.Replace(l.value)))
+		}
+		line := f.name
+		if len(pairs) > 0 {
+			line += "{" + strings.Join(pairs, ",") + "}"
+		}
+		lines = append(lines, line+" "+strconv.FormatFloat(s.value, 'f', -1, 64))
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,120 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+func TestExporter(t *testing.T) {
+	s := fakecloud.NewServer()
+	defer s.Close()
+	web := s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "web"})
+	listener := s.AddListener(web.ID, listeners.Listener{Name: "http", Protocol: "HTTP", ProtocolPort: 80})
+	pool := s.AddPool(web.ID, listener.ID, pools.Pool{Name: "backends", Protocol: "HTTP"})
+	s.AddMember(pool.ID, pools.Member{Address: "10.1.0.5", ProtocolPort: 8080})
+	s.AddLoadBalancer(loadbalancers.LoadBalancer{Name: "unused"})
+	s.SetStats(web.ID, loadbalancers.Stats{ActiveConnections: 3, TotalConnections: 1500000, BytesIn: 42})
+	opts := s.AuthOptions()
+	osClient, err := client.NewOpenStackProvider(client.Config{AuthOptions: &opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExporter(osClient, true)
+	if err := e.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %s", err)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE oli_loadbalancers gauge\n",
+		`oli_loadbalancers{operating_status="ONLINE",provisioning_status="ACTIVE"} 2`,
+		`oli_listeners{provisioning_status="ACTIVE"} 1`,
+		`oli_members{operating_status="ONLINE",provisioning_status="ACTIVE"} 1`,
+		"oli_empty_loadbalancers 1\n",
+		`oli_orphans{kind="pool"} 0`,
+		`oli_loadbalancer_active_connections{loadbalancer_id="` + web.ID + `",name="web"} 3`,
+		`oli_loadbalancer_connections_total{loadbalancer_id="` + web.ID + `",name="web"} 1500000`,
+		"oli_collections_total 1\n",
+		`oli_collection_errors_total{stage="inventory"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, out)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("got content type %q", ct)
+	}
+
+	// the metrics of the last collection are kept if the cloud is gone
+	s.Close()
+	if err := e.Refresh(context.Background()); err == nil {
+		t.Fatalf("refresh without cloud succeeded")
+	}
+	var b bytes.Buffer
+	e.WriteTo(&b)
+	for _, want := range []string{"oli_empty_loadbalancers 1\n", "oli_collections_total 2\n", `oli_collection_errors_total{stage="inventory"} 1`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestInventoryFamilies(t *testing.T) {
+	inv := &inventory.Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{{ID: "lb", ProvisioningStatus: "ERROR", OperatingStatus: "OFFLINE"}},
+		Listeners:     []listeners.Listener{{ID: "lost", ProvisioningStatus: "ACTIVE", Loadbalancers: []listeners.LoadBalancerID{{ID: "gone"}}}},
+		Pools:         []pools.Pool{{ID: "lost-pool"}},
+	}
+	var b bytes.Buffer
+	for _, f := range inventoryFamilies(inv) {
+		f.write(&b)
+	}
+	for _, want := range []string{
+		`oli_loadbalancers{operating_status="OFFLINE",provisioning_status="ERROR"} 1`,
+		`oli_orphans{kind="listener"} 1`,
+		`oli_orphans{kind="pool"} 1`,
+		`oli_orphans{kind="health monitor"} 0`,
+		`oli_pools{operating_status="",provisioning_status=""} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	f := &family{name: "m", help: "help", typ: "gauge"}
+	f.add(1, label{"name", "a \"b\"\\c\nd"})
+	var b bytes.Buffer
+	f.write(&b)
+	if want := `m{name="a \"b\"\\c\nd"} 1`; !strings.Contains(b.String(), want) {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migrate moves LoadBalancers from Neutron LBaaS v2 to Octavia. A
+// LoadBalancer is exported as a spec, translated to what Octavia accepts and
+// restored on the Octavia endpoint. Both are then compared before the
+// original may be deleted.
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/spec"
+)
+
+// Report records the migration of a single LoadBalancer.
+type Report struct {
+	SourceID   string    `json:"source_id"`
+	TargetID   string    `json:"target_id,omitempty"`
+	MigratedAt time.Time `json:"migrated_at"`
+	// Fields lists the fields whose value was changed or which need
+	// attention on the Octavia side.
+	Fields []Field `json:"fields"`
+	// Dropped lists the fields which could not be carried over.
+	Dropped []Field `json:"dropped"`
+	// Mapping maps the IDs of the original objects to the migrated ones.
+	Mapping []client.IDMapping `json:"mapping"`
+	Checks  []Check            `json:"checks"`
+	// Deleted is the report of deleting the original, if it was deleted.
+	Deleted *client.DeleteReport `json:"deleted,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+// Field is a field of an object which was changed or dropped.
+type Field struct {
+	Kind  string `json:"kind"`
+	ID    string `json:"id"`
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new,omitempty"`
+	Note  string `json:"note,omitempty"`
+}
+
+// Check is the outcome of comparing the original with the migrated
+// LoadBalancer.
+type Check struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// providers maps Neutron LBaaS providers to their Octavia equivalent.
+var providers = map[string]string{
+	"haproxy": "amphora",
+	"octavia": "amphora",
+}
+
+// Translate returns a copy of a Neutron LBaaS spec which can be restored on
+// Octavia, together with a report of everything that was changed or dropped.
+func Translate(s *spec.Spec) (*spec.Spec, *Report) {
+	report := &Report{SourceID: s.LoadBalancer.ID, MigratedAt: time.Now().UTC(), Fields: []Field{}, Dropped: []Field{}}
+	// a deep copy, so that the original spec is left untouched
+	data, _ := json.Marshal(s)
+	t := &spec.Spec{}
+	json.Unmarshal(data, t)
+
+	lb := &t.LoadBalancer
+	if lb.Provider != "" {
+		if provider, ok := providers[lb.Provider]; ok {
+			report.Fields = append(report.Fields, Field{Kind: client.KindLoadBalancer, ID: lb.ID, Field: "provider", Old: lb.Provider, New: provider})
+			lb.Provider = provider
+		} else {
+			report.Dropped = append(report.Dropped, Field{Kind: client.KindLoadBalancer, ID: lb.ID, Field: "provider", Old: lb.Provider,
+				Note: "no Octavia equivalent, the default provider is used"})
+			lb.Provider = ""
+		}
+	}
+	if lb.Flavor != "" {
+		report.Dropped = append(report.Dropped, Field{Kind: client.KindLoadBalancer, ID: lb.ID, Field: "flavor", Old: lb.Flavor,
+			Note: "Neutron LBaaS flavors do not exist in Octavia"})
+		lb.Flavor = ""
+	}
+	if lb.VipAddress != "" {
+		report.Dropped = append(report.Dropped, Field{Kind: client.KindLoadBalancer, ID: lb.ID, Field: "vip_address", Old: lb.VipAddress,
+			Note: "the address is in use until the original is deleted, Octavia picks a new one"})
+		lb.VipAddress = ""
+	}
+	for _, l := range lb.Listeners {
+		if l.DefaultTLSContainerRef != "" || len(l.SNIContainerRefs) > 0 {
+			report.Fields = append(report.Fields, Field{Kind: client.KindListener, ID: l.ID, Field: "tls_container_refs", Old: l.DefaultTLSContainerRef, New: l.DefaultTLSContainerRef,
+				Note: "Octavia needs read access to the containers in Barbican"})
+		}
+	}
+	return t, report
+}
+
+// Verify compares the translated spec of the original with the spec and the
+// statuses of the migrated LoadBalancer, and the member health of both.
+func Verify(source, target *spec.Spec, sourceStatus, targetStatus *loadbalancers.StatusTree) []Check {
+	var checks []Check
+	check := func(name string, ok bool, detail string, args ...interface{}) {
+		checks = append(checks, Check{Name: name, OK: ok, Detail: fmt.Sprintf(detail, args...)})
+	}
+
+	same := func(name, what, source, target string) {
+		if source == target {
+			check(name, true, "both %s %s", what, source)
+		} else {
+			check(name, false, "original %s %s, migrated %s", what, source, target)
+		}
+	}
+	same("topology", "have", counts(source), counts(target))
+	same("listeners", "listen on", listenerPorts(source), listenerPorts(target))
+	same("members", "balance to", memberAddresses(source), memberAddresses(target))
+
+	if targetStatus == nil || targetStatus.Loadbalancer == nil {
+		check("provisioning", false, "no status of the migrated loadbalancer")
+	} else {
+		status := targetStatus.Loadbalancer.ProvisioningStatus
+		check("provisioning", status == "ACTIVE", "migrated loadbalancer is %s", status)
+	}
+
+	// members which are healthy on the original must be healthy on the
+	// migrated LoadBalancer as well
+	sourceHealth, targetHealth := memberHealth(sourceStatus), memberHealth(targetStatus)
+	var unhealthy []string
+	for member, status := range sourceHealth {
+		if status == "ONLINE" && targetHealth[member] != "ONLINE" && targetHealth[member] != "NO_MONITOR" {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s (%s)", member, targetHealth[member]))
+		}
+	}
+	sort.Strings(unhealthy)
+	if len(unhealthy) == 0 {
+		check("health", true, "all members online on the original are online on the migrated loadbalancer")
+	} else {
+		check("health", false, "members online on the original but not on the migrated loadbalancer: %v", unhealthy)
+	}
+	return checks
+}
+
+// Passed reports whether all checks passed.
+func Passed(checks []Check) bool {
+	for _, c := range checks {
+		if !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes the report as JSON to path.
+func (r *Report) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode migration report: %s", err)
+	}
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write migration report: %s", err)
+	}
+	return nil
+}
+
+func counts(s *spec.Spec) string {
+	var policies, rules, members, monitors int
+	for _, l := range s.LoadBalancer.Listeners {
+		policies += len(l.L7Policies)
+		for _, p := range l.L7Policies {
+			rules += len(p.Rules)
+		}
+	}
+	for _, p := range s.LoadBalancer.Pools {
+		members += len(p.Members)
+		if p.Monitor != nil {
+			monitors++
+		}
+	}
+	return fmt.Sprintf("%d listeners, %d pools, %d members, %d health monitors, %d l7 policies, %d l7 rules",
+		len(s.LoadBalancer.Listeners), len(s.LoadBalancer.Pools), members, monitors, policies, rules)
+}
+
+func listenerPorts(s *spec.Spec) string {
+	var ports []string
+	for _, l := range s.LoadBalancer.Listeners {
+		ports = append(ports, fmt.Sprintf("%s:%d", l.Protocol, l.ProtocolPort))
+	}
+	sort.Strings(ports)
+	return strings.Join(ports, ", ")
+}
+
+func memberAddresses(s *spec.Spec) string {
+	var addresses []string
+	for _, p := range s.LoadBalancer.Pools {
+		for _, m := range p.Members {
+			addresses = append(addresses, fmt.Sprintf("%s:%d", m.Address, m.ProtocolPort))
+		}
+	}
+	sort.Strings(addresses)
+	return strings.Join(addresses, ", ")
+}
+
+// memberHealth returns the operating status of all members by address and
+// port.
+func memberHealth(statuses *loadbalancers.StatusTree) map[string]string {
+	health := map[string]string{}
+	if statuses == nil || statuses.Loadbalancer == nil {
+		return health
+	}
+	for _, l := range statuses.Loadbalancer.Listeners {
+		for _, p := range l.Pools {
+			for _, m := range p.Members {
+				health[fmt.Sprintf("%s:%d", m.Address, m.ProtocolPort)] = m.OperatingStatus
+			}
+		}
+	}
+	return health
+}
@@ -0,0 +1,78 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/spec"
+)
+
+func TestTranslate(t *testing.T) {
+	original := &spec.Spec{Version: spec.Version, LoadBalancer: spec.LoadBalancer{
+		ID:          "lb",
+		VipSubnetID: "subnet",
+		VipAddress:  "10.0.0.10",
+		Provider:    "f5networks",
+		Flavor:      "gold",
+		Listeners:   []spec.Listener{{ID: "listener", Protocol: "TERMINATED_HTTPS", ProtocolPort: 443, DefaultTLSContainerRef: "ref"}},
+	}}
+	translated, report := Translate(original)
+
+	if lb := translated.LoadBalancer; lb.Provider != "" || lb.Flavor != "" || lb.VipAddress != "" || lb.VipSubnetID != "subnet" {
+		t.Errorf("got translated loadbalancer %+v", lb)
+	}
+	if original.LoadBalancer.Provider != "f5networks" {
+		t.Errorf("the original spec was changed")
+	}
+	var dropped []string
+	for _, f := range report.Dropped {
+		dropped = append(dropped, f.Field)
+	}
+	if want := []string{"provider", "flavor", "vip_address"}; !reflect.DeepEqual(dropped, want) {
+		t.Errorf("got dropped fields %v, want %v", dropped, want)
+	}
+	if len(report.Fields) != 1 || report.Fields[0].Field != "tls_container_refs" {
+		t.Errorf("got fields %+v, want the TLS container refs", report.Fields)
+	}
+}
+
+func TestVerifyMemberHealth(t *testing.T) {
+	s := &spec.Spec{LoadBalancer: spec.LoadBalancer{
+		Listeners: []spec.Listener{{Protocol: "HTTP", ProtocolPort: 80}},
+		Pools:     []spec.Pool{{Members: []spec.Member{{Address: "10.0.0.5", ProtocolPort: 80}}}},
+	}}
+	status := func(memberStatus string) *loadbalancers.StatusTree {
+		return &loadbalancers.StatusTree{Loadbalancer: &loadbalancers.LoadBalancer{
+			ProvisioningStatus: "ACTIVE",
+			Listeners: []listeners.Listener{{Pools: []pools.Pool{{Members: []pools.Member{
+				{Address: "10.0.0.5", ProtocolPort: 80, OperatingStatus: memberStatus},
+			}}}}},
+		}}
+	}
+
+	if checks := Verify(s, s, status("ONLINE"), status("ONLINE")); !Passed(checks) {
+		t.Errorf("checks failed: %+v", checks)
+	}
+	checks := Verify(s, s, status("ONLINE"), status("ERROR"))
+	if Passed(checks) || checks[len(checks)-1].Name != "health" || checks[len(checks)-1].OK {
+		t.Errorf("health check passed for an unhealthy member: %+v", checks)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import "fmt"
+
+// Config configures the notifiers, as found below "notifications" in the oli
+// config file.
+type Config struct {
+	Webhooks []WebhookConfig `mapstructure:"webhooks"`
+	Slack    []SlackConfig   `mapstructure:"slack"`
+	Email    []EmailConfig   `mapstructure:"email"`
+}
+
+// WebhookConfig configures a Webhook.
+type WebhookConfig struct {
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// SlackConfig configures a Slack notifier. Channel and username are
+// optional.
+type SlackConfig struct {
+	URL      string `mapstructure:"url"`
+	Channel  string `mapstructure:"channel"`
+	Username string `mapstructure:"username"`
+}
+
+// EmailConfig configures an Email notifier. Server, from and to are required,
+// username and password only if the server asks for authentication.
+type EmailConfig struct {
+	Server   string   `mapstructure:"server"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+}
+
+// New creates the configured notifiers. It returns nil if none are
+// configured.
+func New(c Config) (Notifiers, error) {
+	var n Notifiers
+	for _, w := range c.Webhooks {
+		if w.URL == "" {
+			return nil, fmt.Errorf("webhook notification without url")
+		}
+		n = append(n, &Webhook{URL: w.URL, Headers: w.Headers})
+	}
+	for _, s := range c.Slack {
+		if s.URL == "" {
+			return nil, fmt.Errorf("slack notification without url")
+		}
+		n = append(n, &Slack{URL: s.URL, Channel: s.Channel, Username: s.Username})
+	}
+	for _, e := range c.Email {
+		if e.Server == "" || e.From == "" || len(e.To) == 0 {
+			return nil, fmt.Errorf("email notification needs server, from and to")
+		}
+		n = append(n, &Email{Server: e.Server, From: e.From, To: e.To, Username: e.Username, Password: e.Password})
+	}
+	return n, nil
+}
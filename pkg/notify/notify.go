@@ -0,0 +1,192 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notify sends notifications about cleanup runs to webhooks, Slack
+// and by e-mail.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+// Kinds of events
+const (
+	// KindNotice announces LoadBalancers which are going to be deleted.
+	KindNotice = "notice"
+	// KindSummary reports the outcome of a deletion run.
+	KindSummary = "summary"
+)
+
+// Event is a notification about a cleanup run.
+type Event struct {
+	Kind string `json:"kind"`
+	// Source is the command which sent the event, e.g. janitor.
+	Source string    `json:"source"`
+	Time   time.Time `json:"time"`
+	DryRun bool      `json:"dry_run"`
+	// Candidates are set for notices.
+	Candidates []Candidate `json:"candidates,omitempty"`
+	// Results are set for summaries.
+	Results []Result `json:"results,omitempty"`
+}
+
+// Candidate is a LoadBalancer which is going to be deleted.
+type Candidate struct {
+	LoadBalancerID string `json:"loadbalancer_id"`
+	Name           string `json:"name"`
+	Owner          string `json:"owner,omitempty"`
+	// Reasons tell why the LoadBalancer is deleted, e.g. the janitor
+	// policies matching it.
+	Reasons []string `json:"reasons,omitempty"`
+	// DueAt is when the LoadBalancer is deleted, zero means right away.
+	DueAt time.Time `json:"due_at,omitempty"`
+}
+
+// Result is the outcome of deleting a LoadBalancer.
+type Result struct {
+	LoadBalancerID string   `json:"loadbalancer_id"`
+	Name           string   `json:"name"`
+	Owner          string   `json:"owner,omitempty"`
+	Deleted        int      `json:"deleted"`
+	Skipped        int      `json:"skipped"`
+	Failed         int      `json:"failed"`
+	Protected      int      `json:"protected"`
+	Interrupted    bool     `json:"interrupted,omitempty"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// NewResult summarizes a delete report.
+func NewResult(report *client.DeleteReport, name, owner string) Result {
+	r := Result{
+		LoadBalancerID: report.LoadBalancerID,
+		Name:           name,
+		Owner:          owner,
+		Deleted:        len(report.Deleted),
+		Skipped:        len(report.Skipped),
+		Failed:         len(report.Failed),
+		Protected:      len(report.Protected),
+		Interrupted:    report.Interrupted,
+	}
+	for _, f := range report.Failed {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to delete %s %s: %s", f.Kind, f.ID, f.Err))
+	}
+	return r
+}
+
+// Notifier delivers events.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// Notifiers delivers events to all of its notifiers.
+type Notifiers []Notifier
+
+// Notify sends the event to every notifier, even if one fails, and returns
+// the first error.
+func (n Notifiers) Notify(ctx context.Context, e Event) error {
+	var firstErr error
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, e); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+var (
+	ownerField     = regexp.MustCompile(`(?i)\bowner\s*[=:]\s*(\S+)`)
+	emailAddress   = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	kubernetesName = regexp.MustCompile(`^kube_service_([^_]+)_([^_]+)_`)
+)
+
+// Owner guesses the owner of a LoadBalancer: an "owner=<owner>" or
+// "owner: <owner>" in the description, else the first e-mail address in the
+// description, else the cluster and namespace of a Kubernetes service
+// LoadBalancer named kube_service_<cluster>_<namespace>_<service>.
+func Owner(name, description string) string {
+	if m := ownerField.FindStringSubmatch(description); m != nil {
+		return strings.TrimRight(m[1], ",;.)")
+	}
+	if address := emailAddress.FindString(description); address != "" {
+		return address
+	}
+	if m := kubernetesName.FindStringSubmatch(name); m != nil {
+		return m[1] + "/" + m[2]
+	}
+	return ""
+}
+
+// Text renders an event as a short human readable message. The first line is
+// a headline.
+func Text(e Event) string {
+	var b strings.Builder
+	mode := ""
+	if e.DryRun {
+		mode = " (dry run)"
+	}
+	switch e.Kind {
+	case KindNotice:
+		fmt.Fprintf(&b, "oli %s: %d LoadBalancers are going to be deleted%s\n", e.Source, len(e.Candidates), mode)
+		for _, c := range e.Candidates {
+			fmt.Fprintf(&b, "- %s", label(c.Name, c.LoadBalancerID))
+			if c.Owner != "" {
+				fmt.Fprintf(&b, ", owner %s", c.Owner)
+			}
+			if len(c.Reasons) > 0 {
+				fmt.Fprintf(&b, ", matched %s", strings.Join(c.Reasons, ", "))
+			}
+			if !c.DueAt.IsZero() {
+				fmt.Fprintf(&b, ", due %s", c.DueAt.UTC().Format(time.RFC3339))
+			}
+			b.WriteString("\n")
+		}
+	case KindSummary:
+		deleted, failed := 0, 0
+		for _, r := range e.Results {
+			if r.Failed > 0 || r.Interrupted {
+				failed++
+			} else if r.Deleted > 0 {
+				deleted++
+			}
+		}
+		fmt.Fprintf(&b, "oli %s: %d of %d LoadBalancers deleted, %d failed%s\n", e.Source, deleted, len(e.Results), failed, mode)
+		for _, r := range e.Results {
+			fmt.Fprintf(&b, "- %s: %d deleted, %d skipped, %d failed, %d protected", label(r.Name, r.LoadBalancerID), r.Deleted, r.Skipped, r.Failed, r.Protected)
+			if r.Owner != "" {
+				fmt.Fprintf(&b, ", owner %s", r.Owner)
+			}
+			if r.Interrupted {
+				b.WriteString(", interrupted")
+			}
+			b.WriteString("\n")
+			for _, err := range r.Errors {
+				fmt.Fprintf(&b, "  %s\n", err)
+			}
+		}
+	}
+	return b.String()
+}
+
+func label(name, id string) string {
+	if name == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", name, id)
+}
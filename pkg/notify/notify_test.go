@@ -0,0 +1,262 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afritzler/oli/pkg/client"
+)
+
+var testEvent = Event{
+	Kind:   KindNotice,
+	Source: "janitor",
+	Time:   time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC),
+	Candidates: []Candidate{
+		{LoadBalancerID: "lb-1", Name: "web", Owner: "shop", Reasons: []string{"empty"}, DueAt: time.Date(2018, 10, 16, 10, 0, 0, 0, time.UTC)},
+		{LoadBalancerID: "lb-2"},
+	},
+}
+
+func TestOwner(t *testing.T) {
+	for _, tc := range []struct{ name, description, want string }{
+		{"web", "owner=team-shop, frontend", "team-shop"},
+		{"web", "frontend (Owner: alice)", "alice"},
+		{"web", "ask jane.doe@example.com", "jane.doe@example.com"},
+		{"kube_service_prod_shop_web", "", "prod/shop"},
+		{"web", "frontend", ""},
+	} {
+		if got := Owner(tc.name, tc.description); got != tc.want {
+			t.Errorf("%s %q: got owner %q, want %q", tc.name, tc.description, got, tc.want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	want := `oli janitor: 2 LoadBalancers are going to be deleted
+- web (lb-1), owner shop, matched empty, due 2018-10-16T10:00:00Z
+- lb-2
+`
+	if got := Text(testEvent); got != want {
+		t.Errorf("got notice\n%s\nwant\n%s", got, want)
+	}
+
+	report := &client.DeleteReport{
+		LoadBalancerID: "lb-1",
+		Deleted:        []client.Object{{Kind: client.KindListener, ID: "listener-1"}},
+		Failed:         []client.Failure{{Object: client.Object{Kind: client.KindLoadBalancer, ID: "lb-1"}, Err: errors.New("conflict")}},
+	}
+	summary := Event{Kind: KindSummary, Source: "delete", DryRun: true, Results: []Result{NewResult(report, "web", "")}}
+	want = `oli delete: 0 of 1 LoadBalancers deleted, 1 failed (dry run)
+- web (lb-1): 1 deleted, 0 skipped, 1 failed, 0 protected
+  failed to delete loadbalancer lb-1: conflict
+`
+	if got := Text(summary); got != want {
+		t.Errorf("got summary\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHTTPNotifiers(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	n, err := New(Config{
+		Webhooks: []WebhookConfig{{URL: server.URL + "/hook", Headers: map[string]string{"Authorization": "Bearer token"}}},
+		Slack:    []SlackConfig{{URL: server.URL + "/slack", Channel: "#ops"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), testEvent); err != nil {
+		t.Fatalf("notify failed: %s", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if requests[0].URL.Path != "/hook" || requests[0].Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("got webhook request %s with headers %v", requests[0].URL, requests[0].Header)
+	}
+	if bodies[0]["kind"] != KindNotice || len(bodies[0]["candidates"].([]interface{})) != 2 {
+		t.Errorf("got webhook body %v", bodies[0])
+	}
+	if requests[1].URL.Path != "/slack" || bodies[1]["channel"] != "#ops" || bodies[1]["text"] != Text(testEvent) {
+		t.Errorf("got slack request %s with body %v", requests[1].URL, bodies[1])
+	}
+
+	status = http.StatusInternalServerError
+	if err := n.Notify(context.Background(), testEvent); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("got error %v from a failing webhook", err)
+	}
+	if len(requests) != 4 {
+		t.Errorf("a failing notifier stopped the others")
+	}
+}
+
+// smtpStandIn accepts a single mail and sends what it received to the
+// returned channel.
+func smtpStandIn(t *testing.T) (string, <-chan []string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan []string, 1)
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		var lines []string
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				reply("235 accepted")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					lines = append(lines, strings.TrimRight(line, "\r\n"))
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				received <- lines
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return l.Addr().String(), received
+}
+
+func TestEmail(t *testing.T) {
+	addr, received := smtpStandIn(t)
+	_, port, _ := net.SplitHostPort(addr)
+	n, err := New(Config{Email: []EmailConfig{{
+		Server:   "localhost:" + port,
+		From:     "oli@example.com",
+		To:       []string{"ops@example.com", "shop@example.com"},
+		Username: "oli",
+		Password: "secret",
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), testEvent); err != nil {
+		t.Fatalf("notify failed: %s", err)
+	}
+	lines := <-received
+	text := strings.Join(lines, "\n")
+	auth := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00oli\x00secret"))
+	for _, want := range []string{
+		auth,
+		"MAIL FROM:<oli@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"RCPT TO:<shop@example.com>",
+		"Subject: oli janitor: 2 LoadBalancers are going to be deleted",
+		"- web (lb-1), owner shop, matched empty, due 2018-10-16T10:00:00Z",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("mail does not contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, c := range []Config{
+		{Webhooks: []WebhookConfig{{}}},
+		{Slack: []SlackConfig{{}}},
+		{Email: []EmailConfig{{Server: "localhost:25", From: "oli@example.com"}}},
+	} {
+		if _, err := New(c); err == nil {
+			t.Errorf("config %+v was accepted", c)
+		}
+	}
+	if n, err := New(Config{}); n != nil || err != nil {
+		t.Errorf("got notifiers %v, %v without config", n, err)
+	}
+}
+
+func TestEmailTimeout(t *testing.T) {
+	// a server which accepts connections but never answers
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	m := &Email{Server: l.Addr().String(), From: "oli@example.com", To: []string{"ops@example.com"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- m.Notify(ctx, testEvent) }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("mail to a silent server succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("notify ignored the deadline")
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// httpClient is shared by the HTTP backends.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Webhook posts every event as JSON to a URL.
+type Webhook struct {
+	URL string
+	// Headers are added to every request, e.g. for authorization.
+	Headers map[string]string
+}
+
+// Notify posts the event.
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %s", err)
+	}
+	return post(ctx, w.URL, w.Headers, body)
+}
+
+// Slack posts every event as text message to a Slack compatible incoming
+// webhook.
+type Slack struct {
+	URL string
+	// Channel and Username override the defaults of the webhook.
+	Channel  string
+	Username string
+}
+
+// Notify posts the event as message.
+func (s *Slack) Notify(ctx context.Context, e Event) error {
+	msg := map[string]string{"text": Text(e)}
+	if s.Channel != "" {
+		msg["channel"] = s.Channel
+	}
+	if s.Username != "" {
+		msg["username"] = s.Username
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %s", err)
+	}
+	return post(ctx, s.URL, nil, body)
+}
+
+func post(ctx context.Context, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to notify %s: %s", url, err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to notify %s: %s", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to notify %s: %s %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import "fmt"
+
+// ANSI color codes.
+const (
+	ColorRed    = "31"
+	ColorGreen  = "32"
+	ColorYellow = "33"
+)
+
+// Colorize wraps s in the given ANSI color if enabled is set.
+func Colorize(enabled bool, color, s string) string {
+	if !enabled {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%sm%s\x1b[0m", color, s)
+}
@@ -0,0 +1,113 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+type column struct {
+	header string
+	path   jsonPathExpr
+}
+
+// newCustomColumns returns a Renderer printing a table with a row per
+// LoadBalancer. The columns are given as HEADER:path pairs separated by
+// commas, e.g. NAME:.name,VIP:.vip_address. Paths are JSONPath expressions
+// relative to the LoadBalancer, with or without braces; $ refers to the
+// inventory. Several results are joined with commas, no result is shown as
+// <none>.
+func newCustomColumns(arg string, opts Options) (Renderer, error) {
+	if arg == "" {
+		return nil, fmt.Errorf("columns are required, e.g. NAME:.name")
+	}
+	var columns []column
+	for _, spec := range splitColumns(arg) {
+		parts := strings.SplitN(spec, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid column %q, must be HEADER:path", spec)
+		}
+		expr := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(expr, "{") && strings.HasSuffix(expr, "}") {
+			expr = expr[1 : len(expr)-1]
+		}
+		path, err := parsePath(expr)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column{header: parts[0], path: path})
+	}
+	return RendererFunc(func(w io.Writer, inv *inventory.Inventory) error {
+		m, err := model(inv)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		var headers []string
+		for _, c := range columns {
+			headers = append(headers, c.header)
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		lbs, _ := m.(map[string]interface{})["loadbalancers"].([]interface{})
+		for _, lb := range lbs {
+			var cells []string
+			for _, c := range columns {
+				var values []string
+				for _, v := range c.path.eval(m, lb) {
+					values = append(values, format(v))
+				}
+				cell := strings.Join(values, ",")
+				if len(values) == 0 {
+					cell = "<none>"
+				}
+				cells = append(cells, cell)
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}), nil
+}
+
+// splitColumns splits the column specs at commas outside of brackets, so
+// filters like [?(@.a=="x,y")] stay intact.
+func splitColumns(s string) []string {
+	var specs []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			specs = append(specs, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(specs, s[start:])
+}
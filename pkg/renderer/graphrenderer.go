@@ -0,0 +1,286 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// Graph formats.
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// graph is the topology of an inventory. Unlike the tree it has an edge for
+// every reference, so pools shared by several listeners and the redirect
+// pools of L7 policies show up as such.
+type graph struct {
+	nodes []graphNode
+	edges []graphEdge
+}
+
+type graphNode struct {
+	id    string
+	kind  string
+	lines []string
+	// subnet groups LoadBalancers by VIP subnet and members by their
+	// subnet.
+	subnet string
+	orphan bool
+}
+
+type graphEdge struct {
+	from, to string
+	// label marks edges which are not plain parent-child relations.
+	label string
+}
+
+// kindLabels are the node prefixes, as used in the tree.
+var kindLabels = map[string]string{
+	client.KindLoadBalancer:  "LB",
+	client.KindListener:      "L",
+	client.KindPool:          "P",
+	client.KindMember:        "M",
+	client.KindHealthMonitor: "HM",
+	client.KindL7Policy:      "L7",
+}
+
+func newGraph(inv *inventory.Inventory) *graph {
+	g := &graph{}
+	orphans := map[string]bool{}
+	for _, o := range inv.Orphans() {
+		orphans[o.ID] = true
+	}
+	exists := map[string]bool{}
+	addNode := func(kind, id, name, subnet string, lines ...string) {
+		exists[id] = true
+		g.nodes = append(g.nodes, graphNode{
+			id:     id,
+			kind:   kind,
+			lines:  append([]string{fmt.Sprintf("[%s] %s", kindLabels[kind], name)}, lines...),
+			subnet: subnet,
+			orphan: orphans[id],
+		})
+	}
+	seen := map[graphEdge]bool{}
+	addEdge := func(from, to, label string) {
+		e := graphEdge{from: from, to: to, label: label}
+		if exists[from] && exists[to] && !seen[e] {
+			seen[e] = true
+			g.edges = append(g.edges, e)
+		}
+	}
+	status := func(statuses ...string) string {
+		var parts []string
+		for _, s := range statuses {
+			if s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, "/")
+	}
+
+	for _, lb := range inv.LoadBalancers {
+		addNode(client.KindLoadBalancer, lb.ID, lb.Name, lb.VipSubnetID, lb.VipAddress, status(lb.ProvisioningStatus, lb.OperatingStatus))
+	}
+	for _, l := range inv.Listeners {
+		addNode(client.KindListener, l.ID, l.Name, "", fmt.Sprintf("%s:%d", l.Protocol, l.ProtocolPort), status(l.ProvisioningStatus))
+	}
+	for _, p := range inv.Pools {
+		addNode(client.KindPool, p.ID, p.Name, "", p.LBMethod, status(p.ProvisioningStatus, p.OperatingStatus))
+		for _, m := range inv.Members[p.ID] {
+			addNode(client.KindMember, m.ID, m.Name, m.SubnetID, fmt.Sprintf("%s:%d", m.Address, m.ProtocolPort), status(m.ProvisioningStatus, m.OperatingStatus))
+		}
+	}
+	for _, m := range inv.Monitors {
+		addNode(client.KindHealthMonitor, m.ID, m.Name, "", m.Type, status(m.ProvisioningStatus))
+	}
+	for _, p := range inv.L7Policies {
+		addNode(client.KindL7Policy, p.ID, p.Name, "", p.Action, status(p.ProvisioningStatus, p.OperatingStatus))
+	}
+
+	for _, l := range inv.Listeners {
+		for _, lb := range l.Loadbalancers {
+			addEdge(lb.ID, l.ID, "")
+		}
+		if l.DefaultPoolID != "" {
+			addEdge(l.ID, l.DefaultPoolID, "")
+		}
+	}
+	for _, p := range inv.Pools {
+		listenerEdges := 0
+		for _, l := range p.Listeners {
+			if exists[l.ID] {
+				addEdge(l.ID, p.ID, "")
+				listenerEdges++
+			}
+		}
+		// pools only attached to the LoadBalancer
+		if listenerEdges == 0 {
+			for _, lb := range p.Loadbalancers {
+				addEdge(lb.ID, p.ID, "")
+			}
+		}
+		for _, m := range inv.Members[p.ID] {
+			addEdge(p.ID, m.ID, "")
+		}
+	}
+	for _, m := range inv.Monitors {
+		for _, p := range m.Pools {
+			addEdge(p.ID, m.ID, "")
+		}
+	}
+	for _, p := range inv.L7Policies {
+		addEdge(p.ListenerID, p.ID, "")
+		if p.RedirectPoolID != "" {
+			addEdge(p.ID, p.RedirectPoolID, "redirect")
+		}
+	}
+	return g
+}
+
+// subnets returns the node indexes grouped by subnet, the subnets sorted.
+// Nodes without subnet are under "".
+func (g *graph) subnets() ([]string, map[string][]int) {
+	groups := map[string][]int{}
+	for i, n := range g.nodes {
+		groups[n.subnet] = append(groups[n.subnet], i)
+	}
+	var subnets []string
+	for subnet := range groups {
+		if subnet != "" {
+			subnets = append(subnets, subnet)
+		}
+	}
+	sort.Strings(subnets)
+	return subnets, groups
+}
+
+// RenderGraph renders the topology of an inventory as a Graphviz DOT or a
+// Mermaid flowchart. Orphans are drawn red and dashed, nodes with a subnet are
+// grouped into a cluster per subnet.
+func RenderGraph(inv *inventory.Inventory, format string) (string, error) {
+	g := newGraph(inv)
+	switch format {
+	case FormatDOT:
+		return g.dot(), nil
+	case FormatMermaid:
+		return g.mermaid(), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q, must be one of %s, %s", format, FormatDOT, FormatMermaid)
+}
+
+func (g *graph) dot() string {
+	b := &strings.Builder{}
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	quote := func(s string) string {
+		return `"` + escape.Replace(s) + `"`
+	}
+	node := func(indent string, n graphNode) {
+		var lines []string
+		for _, l := range n.lines {
+			if l != "" {
+				lines = append(lines, escape.Replace(l))
+			}
+		}
+		fmt.Fprintf(b, "%s%s [label=\"%s\"", indent, quote(n.id), strings.Join(lines, `\n`))
+		if n.orphan {
+			b.WriteString(`, color=red, fontcolor=red, style="rounded,dashed"`)
+		}
+		b.WriteString("];\n")
+	}
+
+	b.WriteString("digraph oli {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=Helvetica];\n")
+	subnets, groups := g.subnets()
+	for i, subnet := range subnets {
+		fmt.Fprintf(b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(b, "    label=%s;\n", quote("subnet "+subnet))
+		b.WriteString("    style=dashed;\n")
+		for _, n := range groups[subnet] {
+			node("    ", g.nodes[n])
+		}
+		b.WriteString("  }\n")
+	}
+	for _, n := range groups[""] {
+		node("  ", g.nodes[n])
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(b, "  %s -> %s", quote(e.from), quote(e.to))
+		if e.label != "" {
+			fmt.Fprintf(b, " [label=%s, style=dashed]", quote(e.label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (g *graph) mermaid() string {
+	b := &strings.Builder{}
+	// Mermaid IDs must be plain words, objects are numbered instead
+	ids := map[string]string{}
+	for i, n := range g.nodes {
+		ids[n.id] = fmt.Sprintf("n%d", i)
+	}
+	escape := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	node := func(indent string, n graphNode) {
+		var lines []string
+		for _, l := range n.lines {
+			if l != "" {
+				lines = append(lines, escape.Replace(l))
+			}
+		}
+		fmt.Fprintf(b, "%s%s[\"%s\"]\n", indent, ids[n.id], strings.Join(lines, "<br/>"))
+	}
+
+	b.WriteString("flowchart LR\n")
+	subnets, groups := g.subnets()
+	for i, subnet := range subnets {
+		fmt.Fprintf(b, "  subgraph subnet%d[\"subnet %s\"]\n", i, escape.Replace(subnet))
+		for _, n := range groups[subnet] {
+			node("    ", g.nodes[n])
+		}
+		b.WriteString("  end\n")
+	}
+	for _, n := range groups[""] {
+		node("  ", g.nodes[n])
+	}
+	for _, e := range g.edges {
+		if e.label != "" {
+			fmt.Fprintf(b, "  %s -. %s .-> %s\n", ids[e.from], escape.Replace(e.label), ids[e.to])
+		} else {
+			fmt.Fprintf(b, "  %s --> %s\n", ids[e.from], ids[e.to])
+		}
+	}
+	var orphans []string
+	for _, n := range g.nodes {
+		if n.orphan {
+			orphans = append(orphans, ids[n.id])
+		}
+	}
+	if len(orphans) > 0 {
+		b.WriteString("  classDef orphan stroke:#d00,stroke-width:2px,stroke-dasharray:5 5,color:#d00\n")
+		fmt.Fprintf(b, "  class %s orphan\n", strings.Join(orphans, ","))
+	}
+	return b.String()
+}
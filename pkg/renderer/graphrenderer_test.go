@@ -0,0 +1,100 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/l7policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+func graphInventory() *inventory.Inventory {
+	return &inventory.Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{{ID: "lb", Name: "web", VipSubnetID: "subnet-1", VipAddress: "10.0.0.5"}},
+		Listeners: []listeners.Listener{
+			{ID: "http", Name: "http", Protocol: "HTTP", ProtocolPort: 80, Loadbalancers: []listeners.LoadBalancerID{{ID: "lb"}}},
+			{ID: "alt", Name: "alt", Protocol: "HTTP", ProtocolPort: 8080, Loadbalancers: []listeners.LoadBalancerID{{ID: "lb"}}},
+		},
+		Pools: []pools.Pool{
+			// shared by both listeners
+			{ID: "shared", Name: "shared", Listeners: []pools.ListenerID{{ID: "http"}, {ID: "alt"}}},
+			{ID: "static", Name: "static", Loadbalancers: []pools.LoadBalancerID{{ID: "lb"}}},
+			{ID: "lost", Name: "lost \"pool\"", Listeners: []pools.ListenerID{{ID: "gone"}}},
+		},
+		Members: map[string][]pools.Member{
+			"shared": {{ID: "node-1", Name: "node-1", Address: "10.1.0.5", ProtocolPort: 80, SubnetID: "subnet-2"}},
+		},
+		Monitors:   []monitors.Monitor{{ID: "ping", Name: "ping", Type: "PING", Pools: []monitors.PoolID{{ID: "shared"}}}},
+		L7Policies: []l7policies.L7Policy{{ID: "images", Name: "images", ListenerID: "http", Action: "REDIRECT_TO_POOL", RedirectPoolID: "static"}},
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	out, err := RenderGraph(graphInventory(), FormatDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"digraph oli {",
+		`label="subnet subnet-1";`,
+		`"lb" [label="[LB] web\n10.0.0.5"];`,
+		`"http" -> "shared";`,
+		`"alt" -> "shared";`,
+		`"lb" -> "static";`,
+		`"images" -> "static" [label="redirect", style=dashed];`,
+		`"shared" -> "ping";`,
+		`"lost" [label="[P] lost \"pool\"", color=red`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `"gone"`) {
+		t.Errorf("output has an edge to a missing listener:\n%s", out)
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	out, err := RenderGraph(graphInventory(), FormatMermaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nodes are numbered in inventory order: lb, http, alt, shared, node-1,
+	// static, lost, ping, images
+	for _, want := range []string{
+		"flowchart LR",
+		`subgraph subnet0["subnet subnet-1"]`,
+		`n0["[LB] web<br/>10.0.0.5"]`,
+		`n6["[P] lost #quot;pool#quot;"]`,
+		"n1 --> n3",
+		"n2 --> n3",
+		"n8 -. redirect .-> n5",
+		"class n6 orphan",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if _, err := RenderGraph(graphInventory(), "svg"); err == nil {
+		t.Errorf("unknown format was accepted")
+	}
+}
@@ -0,0 +1,563 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// This file implements the subset of JSONPath known from kubectl:
+//
+//	{.loadbalancers[*].name}
+//	{range .loadbalancers[?(@.provisioning_status=="ERROR")]}{.id}{"\t"}{.name}{"\n"}{end}
+//
+// Text outside of braces is printed as is, {"..."} prints a quoted string.
+// Paths start at the current element, which is the root outside of range, or
+// with $ at the root. They consist of .field, ..field (recursive), .* or [*], ['field'], [n], [a:b]
+// and [?(@.path <op> literal)] filters with the operators ==, !=, <, <=, >
+// and >=, or [?(@.path)] to test for existence. Several results are
+// separated by a space. Missing fields yield no result.
+
+type jsonPathNode interface{}
+
+type jsonPathText string
+
+type jsonPathExpr []segment
+
+type jsonPathRange struct {
+	path jsonPathExpr
+	body []jsonPathNode
+}
+
+type segmentKind int
+
+const (
+	rootSegment segmentKind = iota
+	fieldSegment
+	recursiveSegment
+	wildcardSegment
+	indexSegment
+	sliceSegment
+	filterSegment
+)
+
+type segment struct {
+	kind       segmentKind
+	name       string
+	start, end *int
+	// filter
+	path  jsonPathExpr
+	op    string
+	value interface{}
+}
+
+// parseJSONPath parses a template of text and expressions in braces.
+func parseJSONPath(template string) ([]jsonPathNode, error) {
+	root := []jsonPathNode{}
+	// stack of the open ranges, the innermost last
+	var stack []*jsonPathRange
+	add := func(n jsonPathNode) {
+		if len(stack) == 0 {
+			root = append(root, n)
+		} else {
+			top := stack[len(stack)-1]
+			top.body = append(top.body, n)
+		}
+	}
+	for template != "" {
+		open := strings.Index(template, "{")
+		if open < 0 {
+			add(jsonPathText(template))
+			break
+		}
+		if open > 0 {
+			add(jsonPathText(template[:open]))
+		}
+		end := closingBrace(template, open)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed expression %q", template[open:])
+		}
+		expr := strings.TrimSpace(template[open+1 : end])
+		template = template[end+1:]
+		switch {
+		case strings.HasPrefix(expr, `"`) || strings.HasPrefix(expr, "'"):
+			s, err := unquote(expr)
+			if err != nil {
+				return nil, err
+			}
+			add(jsonPathText(s))
+		case expr == "end":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("{end} without {range}")
+			}
+			r := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			add(r)
+		case strings.HasPrefix(expr, "range "):
+			path, err := parsePath(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, &jsonPathRange{path: path})
+		default:
+			path, err := parsePath(expr)
+			if err != nil {
+				return nil, err
+			}
+			add(path)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("{range} without {end}")
+	}
+	return root, nil
+}
+
+// closingBrace returns the index of the brace closing the one at open,
+// skipping quoted strings.
+func closingBrace(s string, open int) int {
+	var quote byte
+	for i := open + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, "'") {
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	}
+	u, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return u, nil
+}
+
+// parsePath parses a path like $.loadbalancers[0].name.
+func parsePath(s string) (jsonPathExpr, error) {
+	orig := s
+	path := jsonPathExpr{}
+	if strings.HasPrefix(s, "$") {
+		path = append(path, segment{kind: rootSegment})
+		s = s[1:]
+	}
+	s = strings.TrimPrefix(s, "@")
+	for s != "" {
+		switch {
+		case s == ".":
+			s = ""
+		case strings.HasPrefix(s, ".."):
+			name, rest := identifier(s[2:])
+			if name == "" {
+				return nil, fmt.Errorf("invalid path %q: .. must be followed by a field", orig)
+			}
+			path = append(path, segment{kind: recursiveSegment, name: name})
+			s = rest
+		case strings.HasPrefix(s, ".*"):
+			path = append(path, segment{kind: wildcardSegment})
+			s = s[2:]
+		case strings.HasPrefix(s, "."):
+			name, rest := identifier(s[1:])
+			if name == "" {
+				if strings.HasPrefix(rest, "[") {
+					s = rest
+					continue
+				}
+				return nil, fmt.Errorf("invalid path %q: missing field after .", orig)
+			}
+			path = append(path, segment{kind: fieldSegment, name: name})
+			s = rest
+		case strings.HasPrefix(s, "["):
+			end := closingBracket(s)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed [", orig)
+			}
+			seg, err := parseBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %s", orig, err)
+			}
+			path = append(path, seg)
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: expected . or [ at %q", orig, s)
+		}
+	}
+	return path, nil
+}
+
+func identifier(s string) (string, string) {
+	i := 0
+	for i < len(s) && s[i] != '.' && s[i] != '[' && s[i] != ' ' && s[i] != ')' {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// closingBracket returns the index of the bracket closing the one at 0,
+// skipping nested brackets and quoted strings.
+func closingBracket(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseBracket(s string) (segment, error) {
+	switch {
+	case s == "*":
+		return segment{kind: wildcardSegment}, nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		name, err := unquote(s)
+		return segment{kind: fieldSegment, name: name}, err
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		return parseFilter(strings.TrimSpace(s[2 : len(s)-1]))
+	case strings.Contains(s, ":"):
+		parts := strings.SplitN(s, ":", 2)
+		seg := segment{kind: sliceSegment}
+		for i, p := range parts {
+			if p = strings.TrimSpace(p); p == "" {
+				continue
+			}
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return segment{}, fmt.Errorf("invalid slice [%s]", s)
+			}
+			if i == 0 {
+				seg.start = &n
+			} else {
+				seg.end = &n
+			}
+		}
+		return seg, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return segment{}, fmt.Errorf("invalid index [%s]", s)
+	}
+	return segment{kind: indexSegment, start: &n}, nil
+}
+
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func parseFilter(s string) (segment, error) {
+	if !strings.HasPrefix(s, "@") && !strings.HasPrefix(s, "$") {
+		return segment{}, fmt.Errorf("filter %q must start with @ or $", s)
+	}
+	seg := segment{kind: filterSegment}
+	left, literal := s, ""
+	for _, op := range filterOperators {
+		if i := strings.Index(s, op); i >= 0 {
+			left, seg.op, literal = strings.TrimSpace(s[:i]), op, strings.TrimSpace(s[i+len(op):])
+			break
+		}
+	}
+	switch {
+	case seg.op == "":
+	case strings.HasPrefix(literal, "'") || strings.HasPrefix(literal, `"`):
+		v, err := unquote(literal)
+		if err != nil {
+			return segment{}, err
+		}
+		seg.value = v
+	case literal == "true" || literal == "false":
+		seg.value = literal == "true"
+	default:
+		f, err := strconv.ParseFloat(literal, 64)
+		if err != nil {
+			return segment{}, fmt.Errorf("invalid literal %q in filter", literal)
+		}
+		seg.value = f
+	}
+	path, err := parsePath(left)
+	if err != nil {
+		return segment{}, err
+	}
+	seg.path = path
+	return seg, nil
+}
+
+// eval returns the results of a path starting at the current value. Paths
+// starting with $ start at root instead.
+func (path jsonPathExpr) eval(root, current interface{}) []interface{} {
+	values := []interface{}{current}
+	for _, seg := range path {
+		var next []interface{}
+		for _, v := range values {
+			next = append(next, seg.eval(root, v)...)
+		}
+		values = next
+	}
+	return values
+}
+
+func (seg segment) eval(root, v interface{}) []interface{} {
+	switch seg.kind {
+	case rootSegment:
+		return []interface{}{root}
+	case fieldSegment:
+		if m, ok := v.(map[string]interface{}); ok {
+			if field, ok := m[seg.name]; ok {
+				return []interface{}{field}
+			}
+		}
+	case recursiveSegment:
+		var result []interface{}
+		walk(v, func(v interface{}) {
+			if m, ok := v.(map[string]interface{}); ok {
+				if field, ok := m[seg.name]; ok {
+					result = append(result, field)
+				}
+			}
+		})
+		return result
+	case wildcardSegment:
+		return children(v)
+	case indexSegment, sliceSegment:
+		a, ok := v.([]interface{})
+		if !ok {
+			return nil
+		}
+		resolve := func(n *int, def int) int {
+			if n == nil {
+				return def
+			}
+			i := *n
+			if i < 0 {
+				i += len(a)
+			}
+			if i < 0 {
+				i = 0
+			}
+			if i > len(a) {
+				i = len(a)
+			}
+			return i
+		}
+		if seg.kind == indexSegment {
+			i := *seg.start
+			if i < 0 {
+				i += len(a)
+			}
+			if i < 0 || i >= len(a) {
+				return nil
+			}
+			return []interface{}{a[i]}
+		}
+		start, end := resolve(seg.start, 0), resolve(seg.end, len(a))
+		if start >= end {
+			return nil
+		}
+		return a[start:end]
+	case filterSegment:
+		var result []interface{}
+		for _, item := range children(v) {
+			if seg.matches(root, item) {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// children returns the elements of an array or the values of an object,
+// sorted by key.
+func children(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		var keys []string
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var result []interface{}
+		for _, k := range keys {
+			result = append(result, v[k])
+		}
+		return result
+	}
+	return nil
+}
+
+// walk calls f for v and all values nested in it.
+func walk(v interface{}, f func(interface{})) {
+	f(v)
+	for _, child := range children(v) {
+		walk(child, f)
+	}
+}
+
+func (seg segment) matches(root, item interface{}) bool {
+	results := seg.path.eval(root, item)
+	if len(results) == 0 {
+		return false
+	}
+	left := results[0]
+	if seg.op == "" {
+		return left != nil && left != false && left != ""
+	}
+	switch right := seg.value.(type) {
+	case float64:
+		l, ok := left.(float64)
+		if !ok {
+			return seg.op == "!="
+		}
+		switch seg.op {
+		case "==":
+			return l == right
+		case "!=":
+			return l != right
+		case "<":
+			return l < right
+		case "<=":
+			return l <= right
+		case ">":
+			return l > right
+		case ">=":
+			return l >= right
+		}
+	case string:
+		l, ok := left.(string)
+		if !ok {
+			return seg.op == "!="
+		}
+		switch seg.op {
+		case "==":
+			return l == right
+		case "!=":
+			return l != right
+		case "<":
+			return l < right
+		case "<=":
+			return l <= right
+		case ">":
+			return l > right
+		case ">=":
+			return l >= right
+		}
+	case bool:
+		switch seg.op {
+		case "==":
+			return left == right
+		case "!=":
+			return left != right
+		}
+	}
+	return false
+}
+
+// format prints a result: strings as is, numbers without exponent and
+// objects and arrays as JSON.
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func executeJSONPath(w io.Writer, nodes []jsonPathNode, root, current interface{}) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case jsonPathText:
+			if _, err := io.WriteString(w, string(n)); err != nil {
+				return err
+			}
+		case jsonPathExpr:
+			var parts []string
+			for _, v := range n.eval(root, current) {
+				parts = append(parts, format(v))
+			}
+			if _, err := io.WriteString(w, strings.Join(parts, " ")); err != nil {
+				return err
+			}
+		case *jsonPathRange:
+			for _, v := range n.path.eval(root, current) {
+				if err := executeJSONPath(w, n.body, root, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// newJSONPath returns a Renderer printing a JSONPath template on the
+// inventory model.
+func newJSONPath(arg string, opts Options) (Renderer, error) {
+	if arg == "" {
+		return nil, fmt.Errorf("a template is required")
+	}
+	nodes, err := parseJSONPath(arg)
+	if err != nil {
+		return nil, err
+	}
+	return RendererFunc(func(w io.Writer, inv *inventory.Inventory) error {
+		m, err := model(inv)
+		if err != nil {
+			return err
+		}
+		return executeJSONPath(w, nodes, m, m)
+	}), nil
+}
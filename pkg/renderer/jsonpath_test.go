@@ -0,0 +1,79 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	var data interface{}
+	json.Unmarshal([]byte(`{
+		"loadbalancers": [
+			{"id": "a", "name": "web", "provisioning_status": "ACTIVE", "port": 80, "up": true},
+			{"id": "b", "name": "api", "provisioning_status": "ERROR", "port": 8080, "up": false},
+			{"id": "c", "name": "db", "provisioning_status": "ACTIVE", "port": 5432, "up": true}
+		],
+		"members": {"p2": [{"address": "10.0.0.2"}], "p1": [{"address": "10.0.0.1"}]},
+		"title": "inv"
+	}`), &data)
+
+	for _, tc := range []struct {
+		template, want string
+	}{
+		{`{.title}`, "inv"},
+		{`name: {.loadbalancers[0].name}`, "name: web"},
+		{`{$.loadbalancers[-1].name}`, "db"},
+		{`{.loadbalancers[*].id}`, "a b c"},
+		{`{.loadbalancers[0:2].id}`, "a b"},
+		{`{.loadbalancers[1:].id}`, "b c"},
+		{`{.loadbalancers[*]['name']}`, "web api db"},
+		{`{..address}`, "10.0.0.1 10.0.0.2"},
+		{`{.members.*[*].address}`, "10.0.0.1 10.0.0.2"},
+		{`{.loadbalancers[?(@.provisioning_status=="ERROR")].name}`, "api"},
+		{`{.loadbalancers[?(@.port>=5432)].name}`, "api db"},
+		{`{.loadbalancers[?(@.up==false)].name}`, "api"},
+		{`{.loadbalancers[?(@.up)].name}`, "web db"},
+		{`{.loadbalancers[?(@.name!='web')].id}`, "b c"},
+		{`{.loadbalancers[5].name}{.missing}`, ""},
+		{`{.loadbalancers[0].port}`, "80"},
+		{`{range .loadbalancers[*]}{.id}{"\t"}{.name}{"\n"}{end}`, "a\tweb\nb\tapi\nc\tdb\n"},
+		{`{range .loadbalancers[?(@.up)]}{.name}@{$.title} {end}`, "web@inv db@inv "},
+		{`{.members.p1}`, `[{"address":"10.0.0.1"}]`},
+		{`{"{literal}"}`, "{literal}"},
+	} {
+		nodes, err := parseJSONPath(tc.template)
+		if err != nil {
+			t.Errorf("%s: %s", tc.template, err)
+			continue
+		}
+		var out bytes.Buffer
+		if err := executeJSONPath(&out, nodes, data, data); err != nil {
+			t.Errorf("%s: %s", tc.template, err)
+			continue
+		}
+		if out.String() != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.template, out.String(), tc.want)
+		}
+	}
+
+	for _, template := range []string{`{.a`, `{range .a}`, `{end}`, `{.a[x]}`, `{.a[?(b)]}`, `{a}`, `{"unterminated}`} {
+		if _, err := parseJSONPath(template); err == nil {
+			t.Errorf("%s was parsed", template)
+		}
+	}
+}
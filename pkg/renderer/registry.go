@@ -0,0 +1,163 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// Renderer writes an inventory in an output format.
+type Renderer interface {
+	Render(w io.Writer, inv *inventory.Inventory) error
+}
+
+// RendererFunc adapts a function to a Renderer.
+type RendererFunc func(w io.Writer, inv *inventory.Inventory) error
+
+// Render calls f.
+func (f RendererFunc) Render(w io.Writer, inv *inventory.Inventory) error {
+	return f(w, inv)
+}
+
+// Options are passed to every Factory.
+type Options struct {
+	// Color allows ANSI colors in the output.
+	Color bool
+}
+
+// Factory creates a Renderer. arg is the part of the output format after the
+// first "=", like the template of "go-template=...".
+type Factory func(arg string, opts Options) (Renderer, error)
+
+var registry = map[string]Factory{}
+
+// Register adds an output format. It panics if the name is taken.
+func Register(name string, f Factory) {
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("renderer: output format %s registered twice", name))
+	}
+	registry[name] = f
+}
+
+// Formats returns the names of all output formats, sorted.
+func Formats() []string {
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the Renderer of an output format, given as "name" or
+// "name=arg".
+func New(format string, opts Options) (Renderer, error) {
+	name, arg := format, ""
+	if i := strings.Index(format, "="); i >= 0 {
+		name, arg = format[:i], format[i+1:]
+	}
+	f, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q, must be one of %s", name, strings.Join(Formats(), ", "))
+	}
+	r, err := f(arg, opts)
+	if err != nil {
+		return nil, fmt.Errorf("output format %s: %s", name, err)
+	}
+	return r, nil
+}
+
+func init() {
+	Register("tree", withoutArg(func(opts Options) Renderer {
+		return RendererFunc(func(w io.Writer, inv *inventory.Inventory) error {
+			_, err := fmt.Fprintln(w, RenderTree(inv, opts.Color))
+			return err
+		})
+	}))
+	for _, format := range []string{FormatDOT, FormatMermaid} {
+		format := format
+		Register(format, withoutArg(func(opts Options) Renderer {
+			return RendererFunc(func(w io.Writer, inv *inventory.Inventory) error {
+				graph, err := RenderGraph(inv, format)
+				if err != nil {
+					return err
+				}
+				_, err = io.WriteString(w, graph)
+				return err
+			})
+		}))
+	}
+	Register("json", withoutArg(func(opts Options) Renderer {
+		return RendererFunc(func(w io.Writer, inv *inventory.Inventory) error {
+			data, err := json.MarshalIndent(inv, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode inventory: %s", err)
+			}
+			_, err = fmt.Fprintf(w, "%s\n", data)
+			return err
+		})
+	}))
+	Register("go-template", newGoTemplate)
+	Register("go-template-file", fromFile(newGoTemplate))
+	Register("jsonpath", newJSONPath)
+	Register("jsonpath-file", fromFile(newJSONPath))
+	Register("custom-columns", newCustomColumns)
+}
+
+// withoutArg is the Factory of an output format which takes no argument.
+func withoutArg(f func(opts Options) Renderer) Factory {
+	return func(arg string, opts Options) (Renderer, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("takes no argument")
+		}
+		return f(opts), nil
+	}
+}
+
+// fromFile is the Factory of an output format whose argument is read from the
+// file named by arg.
+func fromFile(f Factory) Factory {
+	return func(arg string, opts Options) (Renderer, error) {
+		if arg == "" {
+			return nil, fmt.Errorf("a file is required")
+		}
+		data, err := ioutil.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %s", arg, err)
+		}
+		return f(string(data), opts)
+	}
+}
+
+// model returns the inventory as decoded JSON, with the same keys as a
+// snapshot. Templates, JSONPath and custom columns work on it.
+func model(inv *inventory.Inventory) (interface{}, error) {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode inventory: %s", err)
+	}
+	var m interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory: %s", err)
+	}
+	return m, nil
+}
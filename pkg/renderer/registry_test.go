@@ -0,0 +1,82 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, format string) string {
+	r, err := New(format, Options{})
+	if err != nil {
+		t.Fatalf("%s: %s", format, err)
+	}
+	var out bytes.Buffer
+	if err := r.Render(&out, graphInventory()); err != nil {
+		t.Fatalf("%s: %s", format, err)
+	}
+	return out.String()
+}
+
+func TestNew(t *testing.T) {
+	for _, format := range []string{"yaml", "tree=x", "go-template", "go-template={{.nope", "jsonpath={.a", "custom-columns=NAME", "go-template-file=/does/not/exist"} {
+		if _, err := New(format, Options{}); err == nil {
+			t.Errorf("%s was accepted", format)
+		}
+	}
+	for _, want := range []string{"custom-columns", "dot", "go-template", "json", "jsonpath", "mermaid", "tree"} {
+		if !strings.Contains(strings.Join(Formats(), " "), want) {
+			t.Errorf("format %s is not registered", want)
+		}
+	}
+}
+
+func TestGoTemplate(t *testing.T) {
+	out := render(t, `go-template={{range .loadbalancers}}{{.name}} {{.vip_address}}{{"\n"}}{{end}}{{len .pools}}`)
+	if want := "web 10.0.0.5\n3"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+
+	f, err := ioutil.TempFile("", "oli-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(`{{range $pool, $members := .members}}{{$pool}}={{len $members}}{{end}}`)
+	f.Close()
+	if out := render(t, "go-template-file="+f.Name()); out != "shared=1" {
+		t.Errorf("got %q from the template file", out)
+	}
+}
+
+func TestCustomColumns(t *testing.T) {
+	out := render(t, `custom-columns=NAME:.name,VIP:{.vip_address},LISTENERS:$.listeners[?(@.protocol=="HTTP")].name,FLAVOR:.flavor_id`)
+	want := "NAME   VIP        LISTENERS   FLAVOR\n" +
+		"web    10.0.0.5   http,alt    <none>\n"
+	if out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	out := render(t, "json")
+	if !strings.Contains(out, `"vip_address": "10.0.0.5"`) {
+		t.Errorf("unexpected JSON:\n%s", out)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// newGoTemplate returns a Renderer executing a Go template on the inventory
+// model, e.g. {{range .loadbalancers}}{{.name}}{{"\n"}}{{end}}.
+func newGoTemplate(arg string, opts Options) (Renderer, error) {
+	if arg == "" {
+		return nil, fmt.Errorf("a template is required")
+	}
+	t, err := template.New("output").Parse(arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %s", err)
+	}
+	return RendererFunc(func(w io.Writer, inv *inventory.Inventory) error {
+		m, err := model(inv)
+		if err != nil {
+			return err
+		}
+		if err := t.Execute(w, m); err != nil {
+			return fmt.Errorf("failed to execute template: %s", err)
+		}
+		return nil
+	}), nil
+}
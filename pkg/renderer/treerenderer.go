@@ -16,12 +16,15 @@ package renderer
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
 	"github.com/xlab/treeprint"
+
+	"github.com/afritzler/oli/pkg/inventory"
 )
 
 const (
@@ -39,16 +42,40 @@ type TreeRenderer interface {
 }
 
 type treerenderer struct {
-	tree treeprint.Tree
+	tree  treeprint.Tree
+	color bool
 }
 
-func NewTreeRenderer() TreeRenderer {
+// NewTreeRenderer returns a TreeRenderer. If color is set, provisioning and
+// operating statuses are colored with ANSI escapes.
+func NewTreeRenderer(color bool) TreeRenderer {
 	tree := treeprint.New()
-	return &treerenderer{tree: tree}
+	return &treerenderer{tree: tree, color: color}
+}
+
+// RenderTree renders all objects of an inventory as a tree with legend.
+func RenderTree(inv *inventory.Inventory, color bool) string {
+	r := NewTreeRenderer(color)
+	for _, lb := range inv.LoadBalancers {
+		r.AddLoadBalancer(lb)
+	}
+	for _, listener := range inv.Listeners {
+		r.AddListener(listener)
+	}
+	for _, pool := range inv.Pools {
+		r.AddPool(pool)
+		for _, member := range inv.Members[pool.ID] {
+			r.AddMember(pool.ID, member)
+		}
+	}
+	for _, monitor := range inv.Monitors {
+		r.AddMonitor(monitor)
+	}
+	return r.GetTreeStringWithLegend()
 }
 
 func (t *treerenderer) AddLoadBalancer(loadbalancer loadbalancers.LoadBalancer) treeprint.Tree {
-	t.tree.AddMetaBranch(loadbalancer.ID, t.renderName("LB", loadbalancer.Name, loadbalancer.AdminStateUp))
+	t.tree.AddMetaBranch(loadbalancer.ID, t.renderLoadBalancer(loadbalancer))
 	return t.tree
 }
 
@@ -56,9 +83,9 @@ func (t *treerenderer) AddListener(listener listeners.Listener) treeprint.Tree {
 	for _, lb := range listener.Loadbalancers {
 		lbNode := t.tree.FindByMeta(lb.ID)
 		if lbNode == nil {
-			t.addOrphan(listener.ID, t.renderName("L", listener.Name, listener.AdminStateUp))
+			t.addOrphan(listener.ID, t.renderListener(listener))
 		} else {
-			lbNode.AddMetaNode(listener.ID, t.renderName("L", listener.Name, listener.AdminStateUp))
+			lbNode.AddMetaNode(listener.ID, t.renderListener(listener))
 		}
 	}
 	return t.tree
@@ -68,9 +95,9 @@ func (t *treerenderer) AddPool(pool pools.Pool) treeprint.Tree {
 	for _, listener := range pool.Listeners {
 		lbNode := t.tree.FindByMeta(listener.ID)
 		if lbNode == nil {
-			t.addOrphan(pool.ID, t.renderName("P", pool.Name, pool.AdminStateUp))
+			t.addOrphan(pool.ID, t.renderPool(pool))
 		} else {
-			lbNode.AddMetaNode(pool.ID, t.renderName("P", pool.Name, pool.AdminStateUp))
+			lbNode.AddMetaNode(pool.ID, t.renderPool(pool))
 		}
 	}
 	return t.tree
@@ -80,9 +107,9 @@ func (t *treerenderer) AddMonitor(monitor monitors.Monitor) treeprint.Tree {
 	for _, pool := range monitor.Pools {
 		lbNode := t.tree.FindByMeta(pool.ID)
 		if lbNode == nil {
-			t.addOrphan(monitor.ID, t.renderName("HM", monitor.Name, monitor.AdminStateUp))
+			t.addOrphan(monitor.ID, t.renderMonitor(monitor))
 		} else {
-			lbNode.AddMetaNode(monitor.ID, t.renderName("HM", monitor.Name, monitor.AdminStateUp))
+			lbNode.AddMetaNode(monitor.ID, t.renderMonitor(monitor))
 		}
 	}
 	return t.tree
@@ -92,9 +119,9 @@ func (t *treerenderer) AddMonitor(monitor monitors.Monitor) treeprint.Tree {
 func (t *treerenderer) AddMember(poolid string, member pools.Member) treeprint.Tree {
 	lbNode := t.tree.FindByMeta(poolid)
 	if lbNode == nil {
-		t.addOrphan(member.ID, t.renderName("M", member.Name, member.AdminStateUp))
+		t.addOrphan(member.ID, t.renderMember(member))
 	} else {
-		lbNode.AddMetaNode(member.ID, t.renderName("M", member.Name, member.AdminStateUp))
+		lbNode.AddMetaNode(member.ID, t.renderMember(member))
 	}
 	return t.tree
 }
@@ -118,6 +145,57 @@ func (t *treerenderer) GetTreeStringWithLegend() string {
 	return t.tree.String() + "\n" + legend
 }
 
-func (t *treerenderer) renderName(kind string, name string, state bool) string {
-	return fmt.Sprintf("[%s] %s Up: %t", kind, name, state)
+func (t *treerenderer) renderLoadBalancer(lb loadbalancers.LoadBalancer) string {
+	return t.renderName("LB", lb.Name, lb.AdminStateUp, "VIP: "+lb.VipAddress, lb.ProvisioningStatus, lb.OperatingStatus)
+}
+
+func (t *treerenderer) renderListener(listener listeners.Listener) string {
+	return t.renderName("L", listener.Name, listener.AdminStateUp, fmt.Sprintf("%s:%d", listener.Protocol, listener.ProtocolPort), listener.ProvisioningStatus, "")
+}
+
+func (t *treerenderer) renderPool(pool pools.Pool) string {
+	return t.renderName("P", pool.Name, pool.AdminStateUp, pool.LBMethod, pool.ProvisioningStatus, pool.OperatingStatus)
+}
+
+func (t *treerenderer) renderMember(member pools.Member) string {
+	details := fmt.Sprintf("%s:%d weight %d", member.Address, member.ProtocolPort, member.Weight)
+	return t.renderName("M", member.Name, member.AdminStateUp, details, member.ProvisioningStatus, member.OperatingStatus)
+}
+
+func (t *treerenderer) renderMonitor(monitor monitors.Monitor) string {
+	details := fmt.Sprintf("%s delay %ds timeout %ds retries %d", monitor.Type, monitor.Delay, monitor.Timeout, monitor.MaxRetries)
+	return t.renderName("HM", monitor.Name, monitor.AdminStateUp, details, monitor.ProvisioningStatus, "")
+}
+
+// renderName renders a node as its kind, name, details, provisioning and
+// operating status and admin state. Empty statuses are left out.
+func (t *treerenderer) renderName(kind string, name string, state bool, details string, statuses ...string) string {
+	parts := []string{fmt.Sprintf("[%s] %s", kind, name), details}
+	for _, status := range statuses {
+		if status != "" {
+			parts = append(parts, t.renderStatus(status))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("Up: %t", state))
+	return strings.Join(parts, " ")
+}
+
+// renderStatus colors failed statuses red, transitional and degraded ones
+// yellow and healthy ones green. Others, like NO_MONITOR, are not colored.
+func (t *treerenderer) renderStatus(status string) string {
+	if !t.color {
+		return status
+	}
+	var color string
+	switch {
+	case status == "ERROR" || status == "OFFLINE":
+		color = ColorRed
+	case strings.HasPrefix(status, "PENDING_") || status == "DEGRADED":
+		color = ColorYellow
+	case status == "ACTIVE" || status == "ONLINE":
+		color = ColorGreen
+	default:
+		return status
+	}
+	return Colorize(true, color, status)
 }
@@ -0,0 +1,69 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+)
+
+func renderTree(color bool) string {
+	r := NewTreeRenderer(color)
+	r.AddLoadBalancer(loadbalancers.LoadBalancer{ID: "lb", Name: "web", VipAddress: "10.0.0.5", ProvisioningStatus: "ACTIVE", OperatingStatus: "DEGRADED", AdminStateUp: true})
+	r.AddListener(listeners.Listener{ID: "listener", Name: "http", Protocol: "HTTP", ProtocolPort: 80, ProvisioningStatus: "PENDING_UPDATE", Loadbalancers: []listeners.LoadBalancerID{{ID: "lb"}}})
+	r.AddPool(pools.Pool{ID: "pool", Name: "backends", LBMethod: "ROUND_ROBIN", ProvisioningStatus: "ERROR", OperatingStatus: "NO_MONITOR", Listeners: []pools.ListenerID{{ID: "listener"}}})
+	r.AddMember("pool", pools.Member{ID: "member", Name: "node-1", Address: "10.1.0.5", ProtocolPort: 8080, Weight: 1, OperatingStatus: "OFFLINE"})
+	r.AddMonitor(monitors.Monitor{ID: "monitor", Name: "ping", Type: "HTTP", Delay: 5, Timeout: 3, MaxRetries: 2, Pools: []monitors.PoolID{{ID: "pool"}}})
+	return r.GetTreeString()
+}
+
+func TestTreeRendererDetails(t *testing.T) {
+	out := renderTree(false)
+	for _, want := range []string{
+		"[LB] web VIP: 10.0.0.5 ACTIVE DEGRADED Up: true",
+		"[L] http HTTP:80 PENDING_UPDATE Up: false",
+		"[P] backends ROUND_ROBIN ERROR NO_MONITOR Up: false",
+		"[M] node-1 10.1.0.5:8080 weight 1 OFFLINE Up: false",
+		"[HM] ping HTTP delay 5s timeout 3s retries 2 Up: false",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("output contains colors:\n%s", out)
+	}
+}
+
+func TestTreeRendererColors(t *testing.T) {
+	out := renderTree(true)
+	for _, want := range []string{
+		"\x1b[32mACTIVE\x1b[0m",
+		"\x1b[33mDEGRADED\x1b[0m",
+		"\x1b[33mPENDING_UPDATE\x1b[0m",
+		"\x1b[31mERROR\x1b[0m",
+		"\x1b[31mOFFLINE\x1b[0m",
+		" NO_MONITOR ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
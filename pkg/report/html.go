@@ -0,0 +1,393 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report writes inventory reports for people who do not use oli
+// themselves, like the owners of LoadBalancers.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// Region is the inventory of a region.
+type Region struct {
+	Name      string
+	Inventory *inventory.Inventory
+	// Stats holds the statistics of the LoadBalancers by ID. It is nil if
+	// they were not collected, e.g. for snapshots.
+	Stats map[string]*loadbalancers.Stats
+}
+
+type page struct {
+	GeneratedAt   string
+	Summaries     []summary
+	LoadBalancers []loadBalancerRow
+	Trees         []node
+	Orphans       []objectRow
+	Idle          []objectRow
+	Violations    []objectRow
+	HasStats      bool
+}
+
+// summary counts LoadBalancers by a key, like the region or a status.
+type summary struct {
+	Title  string
+	Counts []count
+}
+
+type count struct {
+	Key string
+	N   int
+}
+
+type loadBalancerRow struct {
+	Region, Project, ID, Name, VIP string
+	Provisioning, Operating        string
+	Listeners, Pools, Members      int
+	// Connections is the number of active and total connections, if
+	// known.
+	Connections string
+}
+
+// objectRow is an object listed in one of the sections, with the reason why.
+type objectRow struct {
+	Region, Kind, ID, Name, Reason string
+}
+
+// node is a node of a topology tree.
+type node struct {
+	Label    string
+	Statuses []string
+	Children []node
+}
+
+// WriteHTML writes a single static HTML file with summary counts, a sortable
+// table of all LoadBalancers, their topology as collapsible trees, orphans,
+// idle LoadBalancers and violations of the rules of inventory.Violations.
+// LoadBalancers are idle if they have neither listeners nor pools or, if
+// statistics were collected, never had a connection.
+func WriteHTML(w io.Writer, generatedAt time.Time, regions []Region) error {
+	p := &page{GeneratedAt: generatedAt.UTC().Format(time.RFC1123)}
+	byRegion := map[string]int{}
+	byProject := map[string]int{}
+	byProvisioning := map[string]int{}
+	byOperating := map[string]int{}
+	for _, r := range regions {
+		inv := r.Inventory
+		if r.Stats != nil {
+			p.HasStats = true
+		}
+		for _, lb := range inv.LoadBalancers {
+			byRegion[r.Name]++
+			byProject[lb.TenantID]++
+			byProvisioning[lb.ProvisioningStatus]++
+			byOperating[lb.OperatingStatus]++
+
+			row := loadBalancerRow{
+				Region:       r.Name,
+				Project:      lb.TenantID,
+				ID:           lb.ID,
+				Name:         lb.Name,
+				VIP:          lb.VipAddress,
+				Provisioning: lb.ProvisioningStatus,
+				Operating:    lb.OperatingStatus,
+				Listeners:    len(inv.ListenersOf(lb.ID)),
+			}
+			lbPools := inv.PoolsOf(lb.ID)
+			row.Pools = len(lbPools)
+			for _, pool := range lbPools {
+				row.Members += len(inv.Members[pool.ID])
+			}
+			stats := r.Stats[lb.ID]
+			if stats != nil {
+				row.Connections = fmt.Sprintf("%d / %d", stats.ActiveConnections, stats.TotalConnections)
+			}
+			p.LoadBalancers = append(p.LoadBalancers, row)
+			p.Trees = append(p.Trees, tree(r.Name, inv, lb))
+
+			switch {
+			case row.Listeners == 0 && row.Pools == 0:
+				p.Idle = append(p.Idle, objectRow{r.Name, client.KindLoadBalancer, lb.ID, lb.Name, "has neither listeners nor pools"})
+			case stats != nil && stats.TotalConnections == 0:
+				p.Idle = append(p.Idle, objectRow{r.Name, client.KindLoadBalancer, lb.ID, lb.Name, "never had a connection"})
+			}
+		}
+		names := objectNames(inv)
+		for _, o := range inv.Orphans() {
+			p.Orphans = append(p.Orphans, objectRow{r.Name, o.Kind, o.ID, names[o.ID], "parent does not exist"})
+		}
+		for _, v := range inv.Violations() {
+			p.Violations = append(p.Violations, objectRow{r.Name, v.Kind, v.ID, v.Name, fmt.Sprintf("%s: %s", v.Rule, v.Message)})
+		}
+	}
+	p.Summaries = []summary{
+		{"Regions", counts(byRegion)},
+		{"Projects", counts(byProject)},
+		{"Provisioning status", counts(byProvisioning)},
+		{"Operating status", counts(byOperating)},
+	}
+	if err := htmlTemplate.Execute(w, p); err != nil {
+		return fmt.Errorf("failed to write HTML report: %s", err)
+	}
+	return nil
+}
+
+// counts sorts the counts by number, then key. Empty keys are shown as
+// "unknown".
+func counts(m map[string]int) []count {
+	var result []count
+	for key, n := range m {
+		if key == "" {
+			key = "unknown"
+		}
+		result = append(result, count{key, n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].N != result[j].N {
+			return result[i].N > result[j].N
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+func objectNames(inv *inventory.Inventory) map[string]string {
+	names := map[string]string{}
+	for _, l := range inv.Listeners {
+		names[l.ID] = l.Name
+	}
+	for _, p := range inv.Pools {
+		names[p.ID] = p.Name
+	}
+	for _, m := range inv.Monitors {
+		names[m.ID] = m.Name
+	}
+	for _, p := range inv.L7Policies {
+		names[p.ID] = p.Name
+	}
+	return names
+}
+
+// tree returns the topology of a LoadBalancer: listeners with their pools and
+// L7 policies, pools with their members and health monitor.
+func tree(region string, inv *inventory.Inventory, lb loadbalancers.LoadBalancer) node {
+	label := func(kind, name, id string) string {
+		if name == "" {
+			return fmt.Sprintf("%s %s", kind, id)
+		}
+		return fmt.Sprintf("%s %s (%s)", kind, name, id)
+	}
+	monitors := map[string]string{}
+	for _, m := range inv.Monitors {
+		monitors[m.ID] = m.Name
+	}
+	pool := func(id string) node {
+		for _, p := range inv.Pools {
+			if p.ID != id {
+				continue
+			}
+			n := node{Label: label(client.KindPool, p.Name, p.ID) + " " + p.LBMethod, Statuses: []string{p.ProvisioningStatus, p.OperatingStatus}}
+			for _, m := range inv.Members[p.ID] {
+				n.Children = append(n.Children, node{
+					Label:    label(client.KindMember, m.Name, m.ID) + fmt.Sprintf(" %s:%d", m.Address, m.ProtocolPort),
+					Statuses: []string{m.ProvisioningStatus, m.OperatingStatus},
+				})
+			}
+			if p.MonitorID != "" {
+				n.Children = append(n.Children, node{Label: label(client.KindHealthMonitor, monitors[p.MonitorID], p.MonitorID)})
+			}
+			return n
+		}
+		return node{Label: label(client.KindPool, "", id) + " (missing)"}
+	}
+
+	root := node{
+		Label:    fmt.Sprintf("%s: %s %s", region, label(client.KindLoadBalancer, lb.Name, lb.ID), lb.VipAddress),
+		Statuses: []string{lb.ProvisioningStatus, lb.OperatingStatus},
+	}
+	shown := map[string]bool{}
+	for _, l := range inv.ListenersOf(lb.ID) {
+		n := node{Label: label(client.KindListener, l.Name, l.ID) + fmt.Sprintf(" %s:%d", l.Protocol, l.ProtocolPort), Statuses: []string{l.ProvisioningStatus}}
+		for _, p := range inv.PoolsOfListener(l.ID) {
+			shown[p.ID] = true
+			n.Children = append(n.Children, pool(p.ID))
+		}
+		for _, policy := range inv.L7PoliciesOf(l.ID) {
+			text := label(client.KindL7Policy, policy.Name, policy.ID) + " " + policy.Action
+			if policy.RedirectPoolID != "" {
+				text += " to pool " + policy.RedirectPoolID
+			}
+			n.Children = append(n.Children, node{Label: text, Statuses: []string{policy.ProvisioningStatus}})
+		}
+		root.Children = append(root.Children, n)
+	}
+	for _, p := range inv.PoolsOf(lb.ID) {
+		if !shown[p.ID] {
+			root.Children = append(root.Children, pool(p.ID))
+		}
+	}
+	return root
+}
+
+// statusClass is the CSS class of a status, matching the colors of "oli list".
+func statusClass(status string) string {
+	switch {
+	case status == "ERROR" || status == "OFFLINE":
+		return "error"
+	case strings.HasPrefix(status, "PENDING_") || status == "DEGRADED":
+		return "warning"
+	case status == "ACTIVE" || status == "ONLINE":
+		return "ok"
+	}
+	return ""
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"statusClass": statusClass}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>LBaaS inventory report</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0; }
+.generated { color: #777; margin-top: 0.2em; }
+.summaries { display: flex; flex-wrap: wrap; gap: 1em; }
+.summary { border: 1px solid #ddd; border-radius: 4px; padding: 0.5em 1em; min-width: 12em; }
+.summary h3 { margin: 0.3em 0; font-size: 1em; }
+table { border-collapse: collapse; margin: 0.5em 0 1.5em; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.3em 0.8em; text-align: left; }
+th.sortable { cursor: pointer; background: #f4f4f4; }
+th.sortable:after { content: " \2195"; color: #aaa; }
+td.number { text-align: right; }
+code { font-size: 0.9em; }
+.status { font-weight: bold; margin-left: 0.3em; }
+.error { color: #c00; }
+.warning { color: #b80; }
+.ok { color: #080; }
+ul.tree { list-style: none; padding-left: 1.5em; margin: 0.2em 0; }
+details > summary { cursor: pointer; }
+.none { color: #777; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>LBaaS inventory report</h1>
+<p class="generated">Generated {{.GeneratedAt}}, {{len .LoadBalancers}} loadbalancers.</p>
+
+<h2>Summary</h2>
+<div class="summaries">
+{{- range .Summaries}}
+<div class="summary">
+<h3>{{.Title}}</h3>
+<table>
+{{- range .Counts}}
+<tr><td{{with statusClass .Key}} class="{{.}}"{{end}}>{{.Key}}</td><td class="number">{{.N}}</td></tr>
+{{- end}}
+</table>
+</div>
+{{- end}}
+</div>
+
+<h2>Loadbalancers</h2>
+{{- if .LoadBalancers}}
+<table class="sortable">
+<thead><tr>
+<th class="sortable">Region</th><th class="sortable">Project</th><th class="sortable">Name</th><th class="sortable">ID</th><th class="sortable">VIP</th>
+<th class="sortable">Provisioning</th><th class="sortable">Operating</th>
+<th class="sortable">Listeners</th><th class="sortable">Pools</th><th class="sortable">Members</th>
+{{- if .HasStats}}<th class="sortable">Connections (active / total)</th>{{end}}
+</tr></thead>
+<tbody>
+{{- range .LoadBalancers}}
+<tr><td>{{.Region}}</td><td>{{.Project}}</td><td>{{.Name}}</td><td><code>{{.ID}}</code></td><td>{{.VIP}}</td>
+<td{{with statusClass .Provisioning}} class="{{.}}"{{end}}>{{.Provisioning}}</td><td{{with statusClass .Operating}} class="{{.}}"{{end}}>{{.Operating}}</td>
+<td class="number">{{.Listeners}}</td><td class="number">{{.Pools}}</td><td class="number">{{.Members}}</td>
+{{- if $.HasStats}}<td class="number">{{.Connections}}</td>{{end}}
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p class="none">No loadbalancers.</p>
+{{- end}}
+
+<h2>Topology</h2>
+{{- range .Trees}}
+<ul class="tree"><li>{{template "node" .}}</li></ul>
+{{- else}}
+<p class="none">No loadbalancers.</p>
+{{- end}}
+
+<h2>Orphans</h2>
+{{template "objects" .Orphans}}
+
+<h2>Idle loadbalancers</h2>
+{{template "objects" .Idle}}
+
+<h2>Policy violations</h2>
+{{template "objects" .Violations}}
+
+<script>
+// sort a table by the clicked column, numbers numerically
+document.querySelectorAll("table.sortable").forEach(function(table) {
+  table.querySelectorAll("th").forEach(function(th, column) {
+    var ascending = true;
+    th.addEventListener("click", function() {
+      var body = table.tBodies[0];
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function(a, b) {
+        var x = a.cells[column].textContent, y = b.cells[column].textContent;
+        var nx = parseFloat(x), ny = parseFloat(y);
+        var result = !isNaN(nx) && !isNaN(ny) ? nx - ny : x.localeCompare(y);
+        return ascending ? result : -result;
+      });
+      ascending = !ascending;
+      rows.forEach(function(row) { body.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+{{define "statuses"}}{{range .}}{{if .}}<span class="status{{with statusClass .}} {{.}}{{end}}">{{.}}</span>{{end}}{{end}}{{end}}
+{{define "node"}}
+{{- if .Children}}<details><summary>{{.Label}}{{template "statuses" .Statuses}}</summary>
+<ul class="tree">{{range .Children}}<li>{{template "node" .}}</li>{{end}}</ul>
+</details>
+{{- else}}{{.Label}}{{template "statuses" .Statuses}}{{end}}
+{{- end}}
+{{define "objects"}}
+{{- if .}}
+<table class="sortable">
+<thead><tr><th class="sortable">Region</th><th class="sortable">Kind</th><th class="sortable">Name</th><th class="sortable">ID</th><th class="sortable">Reason</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr><td>{{.Region}}</td><td>{{.Kind}}</td><td>{{.Name}}</td><td><code>{{.ID}}</code></td><td>{{.Reason}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p class="none">None.</p>
+{{- end}}
+{{- end}}
+`))
@@ -0,0 +1,86 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+func TestWriteHTML(t *testing.T) {
+	inv := &inventory.Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{
+			{ID: "lb-web", Name: "web", TenantID: "team-a", ProvisioningStatus: "ACTIVE", OperatingStatus: "ONLINE", AdminStateUp: true},
+			{ID: "lb-quiet", Name: "quiet", TenantID: "team-a", ProvisioningStatus: "ACTIVE", AdminStateUp: true},
+			{ID: "lb-empty", Name: "<script>", TenantID: "team-b", ProvisioningStatus: "ERROR", AdminStateUp: true},
+		},
+		Listeners: []listeners.Listener{
+			{ID: "listener-http", Name: "http", Protocol: "HTTP", ProtocolPort: 80, DefaultPoolID: "pool", Loadbalancers: []listeners.LoadBalancerID{{ID: "lb-web"}}},
+			{ID: "listener-quiet", Name: "quiet", Protocol: "TCP", ProtocolPort: 22, Loadbalancers: []listeners.LoadBalancerID{{ID: "lb-quiet"}}},
+		},
+		Pools: []pools.Pool{
+			{ID: "pool", Name: "backends", MonitorID: "monitor", Listeners: []pools.ListenerID{{ID: "listener-http"}}},
+			{ID: "pool-lost", Name: "lost", Listeners: []pools.ListenerID{{ID: "gone"}}},
+		},
+		Members: map[string][]pools.Member{
+			"pool": {{ID: "member", Name: "node-1", Address: "10.1.0.5", ProtocolPort: 8080}},
+		},
+	}
+	stats := map[string]*loadbalancers.Stats{
+		"lb-web":   {ActiveConnections: 3, TotalConnections: 42},
+		"lb-quiet": {},
+		"lb-empty": {},
+	}
+
+	var out bytes.Buffer
+	if err := WriteHTML(&out, time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC), []Region{{Name: "RegionOne", Inventory: inv, Stats: stats}}); err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+	for _, want := range []string{
+		"Generated Sat, 01 Sep 2018 12:00:00 UTC, 3 loadbalancers.",
+		// summaries
+		`<td>team-a</td><td class="number">2</td>`,
+		`<td class="error">ERROR</td><td class="number">1</td>`,
+		// table
+		`<td class="number">3 / 42</td>`,
+		// tree
+		"<summary>RegionOne: loadbalancer web (lb-web) ",
+		"member node-1 (member) 10.1.0.5:8080",
+		// orphans, idle and violations
+		"<td>pool</td><td>lost</td><td><code>pool-lost</code></td><td>parent does not exist</td>",
+		"<td><code>lb-empty</code></td><td>has neither listeners nor pools</td>",
+		"<td><code>lb-quiet</code></td><td>never had a connection</td>",
+		"loadbalancer-error: provisioning status is ERROR",
+		"listener-without-pool: has neither a default pool nor L7 policies",
+		// names are escaped
+		"&lt;script&gt;",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report does not contain %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<td><code>lb-web</code></td><td>never") {
+		t.Errorf("loadbalancer with traffic is idle")
+	}
+}
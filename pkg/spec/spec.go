@@ -0,0 +1,168 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spec holds the declarative description of a LoadBalancer and all
+// its children, as written by "oli backup" and read by "oli restore".
+package spec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Version is the version of the spec format.
+const Version = 1
+
+// Spec describes a LoadBalancer with everything attached to it. IDs are
+// those of the original objects, they are only used to connect the objects
+// of a spec and to report the mapping to the restored ones.
+type Spec struct {
+	Version      int          `yaml:"version"`
+	BackedUpAt   time.Time    `yaml:"backed_up_at"`
+	Source       Source       `yaml:"source,omitempty"`
+	LoadBalancer LoadBalancer `yaml:"loadbalancer"`
+}
+
+// Source describes where a spec was taken from.
+type Source struct {
+	AuthURL   string `yaml:"auth_url,omitempty"`
+	Region    string `yaml:"region,omitempty"`
+	ProjectID string `yaml:"project_id,omitempty"`
+}
+
+type LoadBalancer struct {
+	ID           string     `yaml:"id"`
+	Name         string     `yaml:"name,omitempty"`
+	Description  string     `yaml:"description,omitempty"`
+	VipSubnetID  string     `yaml:"vip_subnet_id"`
+	VipAddress   string     `yaml:"vip_address,omitempty"`
+	Provider     string     `yaml:"provider,omitempty"`
+	Flavor       string     `yaml:"flavor,omitempty"`
+	AdminStateUp bool       `yaml:"admin_state_up"`
+	Listeners    []Listener `yaml:"listeners,omitempty"`
+	Pools        []Pool     `yaml:"pools,omitempty"`
+}
+
+type Listener struct {
+	ID                     string     `yaml:"id"`
+	Name                   string     `yaml:"name,omitempty"`
+	Description            string     `yaml:"description,omitempty"`
+	Protocol               string     `yaml:"protocol"`
+	ProtocolPort           int        `yaml:"protocol_port"`
+	ConnectionLimit        int        `yaml:"connection_limit,omitempty"`
+	DefaultTLSContainerRef string     `yaml:"default_tls_container_ref,omitempty"`
+	SNIContainerRefs       []string   `yaml:"sni_container_refs,omitempty"`
+	DefaultPoolID          string     `yaml:"default_pool_id,omitempty"`
+	AdminStateUp           bool       `yaml:"admin_state_up"`
+	L7Policies             []L7Policy `yaml:"l7policies,omitempty"`
+}
+
+// Pool is attached to the listener ListenerID, or directly to the
+// LoadBalancer if it is empty.
+type Pool struct {
+	ID           string       `yaml:"id"`
+	Name         string       `yaml:"name,omitempty"`
+	Description  string       `yaml:"description,omitempty"`
+	ListenerID   string       `yaml:"listener_id,omitempty"`
+	Protocol     string       `yaml:"protocol"`
+	LBMethod     string       `yaml:"lb_algorithm"`
+	Persistence  *Persistence `yaml:"session_persistence,omitempty"`
+	AdminStateUp bool         `yaml:"admin_state_up"`
+	Members      []Member     `yaml:"members,omitempty"`
+	Monitor      *Monitor     `yaml:"healthmonitor,omitempty"`
+}
+
+type Persistence struct {
+	Type       string `yaml:"type"`
+	CookieName string `yaml:"cookie_name,omitempty"`
+}
+
+type Member struct {
+	ID           string `yaml:"id"`
+	Name         string `yaml:"name,omitempty"`
+	Address      string `yaml:"address"`
+	ProtocolPort int    `yaml:"protocol_port"`
+	Weight       int    `yaml:"weight"`
+	SubnetID     string `yaml:"subnet_id,omitempty"`
+	AdminStateUp bool   `yaml:"admin_state_up"`
+}
+
+type Monitor struct {
+	ID            string `yaml:"id"`
+	Name          string `yaml:"name,omitempty"`
+	Type          string `yaml:"type"`
+	Delay         int    `yaml:"delay"`
+	Timeout       int    `yaml:"timeout"`
+	MaxRetries    int    `yaml:"max_retries"`
+	HTTPMethod    string `yaml:"http_method,omitempty"`
+	URLPath       string `yaml:"url_path,omitempty"`
+	ExpectedCodes string `yaml:"expected_codes,omitempty"`
+	AdminStateUp  bool   `yaml:"admin_state_up"`
+}
+
+type L7Policy struct {
+	ID             string `yaml:"id"`
+	Name           string `yaml:"name,omitempty"`
+	Description    string `yaml:"description,omitempty"`
+	Action         string `yaml:"action"`
+	Position       int32  `yaml:"position,omitempty"`
+	RedirectPoolID string `yaml:"redirect_pool_id,omitempty"`
+	RedirectURL    string `yaml:"redirect_url,omitempty"`
+	AdminStateUp   bool   `yaml:"admin_state_up"`
+	Rules          []Rule `yaml:"rules,omitempty"`
+}
+
+type Rule struct {
+	ID           string `yaml:"id"`
+	Type         string `yaml:"type"`
+	CompareType  string `yaml:"compare_type"`
+	Key          string `yaml:"key,omitempty"`
+	Value        string `yaml:"value"`
+	Invert       bool   `yaml:"invert,omitempty"`
+	AdminStateUp bool   `yaml:"admin_state_up"`
+}
+
+// Write writes the spec as YAML to path.
+func (s *Spec) Write(path string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode spec: %s", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write spec: %s", err)
+	}
+	return nil
+}
+
+// Read reads a spec from a YAML file.
+func Read(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %s", err)
+	}
+	s := &Spec{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse spec %s: %s", path, err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported spec version %d in %s, expected %d", s.Version, path, Version)
+	}
+	if s.LoadBalancer.VipSubnetID == "" {
+		return nil, fmt.Errorf("spec %s has no vip_subnet_id", path)
+	}
+	return s, nil
+}
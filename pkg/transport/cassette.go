@@ -0,0 +1,172 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ExchangesFile is the file of a cassette directory holding the recorded
+// exchanges as JSON lines, in the format of the JSON trace.
+const ExchangesFile = "exchanges.jsonl"
+
+type recorder struct {
+	next http.RoundTripper
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder returns a RoundTripper which appends every request and response
+// passing through next to the cassette in dir, with all credentials redacted.
+// The directory is created if needed, an existing cassette is not overwritten.
+func NewRecorder(next http.RoundTripper, dir string) (http.RoundTripper, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %s", err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, ExchangesFile), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %s", err)
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recorder{next: next, file: file}, nil
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex, resp, err := roundTrip(r.next, req)
+	if ex == nil {
+		return resp, err
+	}
+	data, _ := json.Marshal(ex)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, werr := fmt.Fprintf(r.file, "%s\n", data); werr != nil {
+		return nil, fmt.Errorf("failed to record %s %s: %s", req.Method, req.URL, werr)
+	}
+	return resp, err
+}
+
+type replayer struct {
+	mu sync.Mutex
+	// exchanges holds the recorded exchanges by request key, in order.
+	exchanges map[string][]*Exchange
+	// played counts how often a request key was answered.
+	played map[string]int
+}
+
+// NewReplayer returns a RoundTripper which answers all requests from the
+// cassette in dir without any network access. Requests are matched by method,
+// path and query, the host is ignored. Identical requests are answered in the
+// recorded order, once the recording is exhausted the last answer is
+// repeated.
+func NewReplayer(dir string) (http.RoundTripper, error) {
+	exchanges, err := ReadCassette(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &replayer{exchanges: map[string][]*Exchange{}, played: map[string]int{}}
+	for _, ex := range exchanges {
+		u, err := url.Parse(ex.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse recorded URL %s: %s", ex.URL, err)
+		}
+		key := requestKey(ex.Method, u)
+		r.exchanges[key] = append(r.exchanges[key], ex)
+	}
+	return r, nil
+}
+
+// ReadCassette reads all exchanges recorded in dir.
+func ReadCassette(dir string) ([]*Exchange, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ExchangesFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %s", err)
+	}
+	var exchanges []*Exchange
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		ex := &Exchange{}
+		if err := json.Unmarshal(scanner.Bytes(), ex); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette line %d: %s", line, err)
+		}
+		exchanges = append(exchanges, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %s", err)
+	}
+	return exchanges, nil
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := requestKey(req.Method, req.URL)
+	r.mu.Lock()
+	recorded := r.exchanges[key]
+	n := r.played[key]
+	r.played[key]++
+	r.mu.Unlock()
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+	if n >= len(recorded) {
+		n = len(recorded) - 1
+	}
+	ex := recorded[n]
+	if ex.TransportError != "" {
+		return nil, fmt.Errorf("%s (replayed)", ex.TransportError)
+	}
+	header := http.Header{}
+	for k, v := range ex.ResponseHeader {
+		header[k] = v
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(ex.ResponseBody)),
+		ContentLength: int64(len(ex.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// requestKey identifies a request independent of the host, which may differ
+// between the service catalog of the recording and the replaying client.
+func requestKey(method string, u *url.URL) string {
+	path := "/" + strings.TrimLeft(u.Path, "/")
+	if query := u.Query().Encode(); query != "" {
+		path += "?" + query
+	}
+	return method + " " + path
+}
@@ -0,0 +1,115 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/fakecloud"
+	"github.com/afritzler/oli/pkg/transport"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := fakecloud.NewServer()
+	s.PageSize = 1
+	lb := s.AddLoadBalancer(loadbalancers.LoadBalancer{ID: "web", Name: "web"})
+	s.AddListener(lb.ID, listeners.Listener{ID: "web-listener", Protocol: "HTTP", ProtocolPort: 80})
+	s.AddLoadBalancer(loadbalancers.LoadBalancer{ID: "db", Name: "db"})
+	opts := s.AuthOptions()
+
+	run := func(wrap func(http.RoundTripper) http.RoundTripper) (*client.DeleteReport, []loadbalancers.LoadBalancer) {
+		p, err := client.NewOpenStackProvider(client.Config{AuthOptions: &opts, PollInterval: time.Millisecond, WrapTransport: wrap})
+		if err != nil {
+			t.Fatalf("failed to create provider: %s", err)
+		}
+		lbs, err := p.ListLBaaS(context.Background())
+		if err != nil {
+			t.Fatalf("ListLBaaS failed: %s", err)
+		}
+		report, err := p.DeleteLoadBalancer(context.Background(), lb.ID)
+		if err != nil {
+			t.Fatalf("DeleteLoadBalancer failed: %s", err)
+		}
+		return report, lbs
+	}
+
+	recordedReport, recordedLBs := run(func(next http.RoundTripper) http.RoundTripper {
+		recorder, err := transport.NewRecorder(next, dir)
+		if err != nil {
+			t.Fatalf("failed to create recorder: %s", err)
+		}
+		return recorder
+	})
+	s.Close()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, transport.ExchangesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), fakecloud.Password) {
+		t.Errorf("cassette contains the password")
+	}
+
+	replayer, err := transport.NewReplayer(dir)
+	if err != nil {
+		t.Fatalf("failed to create replayer: %s", err)
+	}
+	replayedReport, replayedLBs := run(func(http.RoundTripper) http.RoundTripper {
+		return replayer
+	})
+	if !reflect.DeepEqual(recordedLBs, replayedLBs) {
+		t.Errorf("replayed loadbalancers %v differ from the recorded %v", replayedLBs, recordedLBs)
+	}
+	if !reflect.DeepEqual(recordedReport, replayedReport) {
+		t.Errorf("replayed report %v differs from the recorded %v", replayedReport, recordedReport)
+	}
+}
+
+func TestReplayUnknownRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oli-cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, transport.ExchangesFile), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := transport.NewReplayer(dir)
+	if err != nil {
+		t.Fatalf("failed to create replayer: %s", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://cloud/v2.0/lbaas/loadbalancers", nil)
+	if _, err := replayer.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "GET /v2.0/lbaas/loadbalancers") {
+		t.Errorf("got error %v, want no recorded response", err)
+	}
+}
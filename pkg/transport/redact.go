@@ -0,0 +1,97 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Redacted replaces every secret in logged or recorded traffic.
+const Redacted = "***"
+
+// secretHeaders are never written out in clear text.
+var secretHeaders = map[string]bool{
+	"X-Auth-Token":    true,
+	"X-Subject-Token": true,
+	"Authorization":   true,
+}
+
+// secretKeys are JSON keys whose values are never written out in clear text,
+// e.g. user passwords and application credential secrets.
+var secretKeys = map[string]bool{
+	"password": true,
+	"secret":   true,
+	"passcode": true,
+}
+
+// RedactHeader returns a copy of h with all secret headers redacted.
+func RedactHeader(h http.Header) http.Header {
+	result := http.Header{}
+	for key, values := range h {
+		if secretHeaders[http.CanonicalHeaderKey(key)] {
+			result[key] = []string{Redacted}
+			continue
+		}
+		result[key] = append([]string(nil), values...)
+	}
+	return result
+}
+
+// RedactBody returns body with all secret JSON values redacted. Bodies which
+// are no JSON are returned unchanged.
+func RedactBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	redacted, err := json.Marshal(redactValue("", v))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func redactValue(parent string, v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			lower := strings.ToLower(key)
+			// Keystone v2 returns the token as access.token.id
+			secret := secretKeys[lower] || (parent == "token" && lower == "id")
+			switch child.(type) {
+			case map[string]interface{}, []interface{}:
+				// e.g. the "password" auth method holds the user, not the secret
+				value[key] = redactValue(lower, child)
+			default:
+				if secret {
+					value[key] = Redacted
+				}
+			}
+		}
+		return value
+	case []interface{}:
+		for i, child := range value {
+			value[i] = redactValue(parent, child)
+		}
+		return value
+	default:
+		return v
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/afritzler/oli/pkg/transport"
+)
+
+func TestRedactHeader(t *testing.T) {
+	for _, tc := range []struct {
+		key    string
+		values []string
+		want   []string
+	}{
+		{"X-Auth-Token", []string{"gAAAAAB-token"}, []string{transport.Redacted}},
+		{"X-Subject-Token", []string{"gAAAAAB-token"}, []string{transport.Redacted}},
+		{"Authorization", []string{"Bearer abc", "Basic def"}, []string{transport.Redacted}},
+		// keys which were not canonicalized are redacted too
+		{"x-auth-token", []string{"gAAAAAB-token"}, []string{transport.Redacted}},
+		{"Content-Type", []string{"application/json"}, []string{"application/json"}},
+	} {
+		h := http.Header{tc.key: tc.values}
+		got := transport.RedactHeader(h)
+		if !reflect.DeepEqual(got[tc.key], tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.key, got[tc.key], tc.want)
+		}
+		if !reflect.DeepEqual(h[tc.key], tc.values) {
+			t.Errorf("%s: the original header was changed to %v", tc.key, h[tc.key])
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			"password auth",
+			`{"auth": {"identity": {"methods": ["password"], "password": {"user": {"name": "admin", "password": "s3cret", "domain": {"name": "Default"}}}}}}`,
+			`{"auth":{"identity":{"methods":["password"],"password":{"user":{"domain":{"name":"Default"},"name":"admin","password":"***"}}}}}`,
+		},
+		{
+			"application credential",
+			`{"auth": {"identity": {"methods": ["application_credential"], "application_credential": {"id": "ac-1", "secret": "s3cret"}}}}`,
+			`{"auth":{"identity":{"application_credential":{"id":"ac-1","secret":"***"},"methods":["application_credential"]}}}`,
+		},
+		{
+			"totp",
+			`{"auth": {"identity": {"methods": ["totp"], "totp": {"user": {"id": "u-1", "passcode": "123456"}}}}}`,
+			`{"auth":{"identity":{"methods":["totp"],"totp":{"user":{"id":"u-1","passcode":"***"}}}}}`,
+		},
+		{
+			"token response",
+			`{"access": {"token": {"id": "gAAAAAB-token", "expires": "2099-01-01T00:00:00Z"}, "user": {"id": "u-1"}}}`,
+			`{"access":{"token":{"expires":"2099-01-01T00:00:00Z","id":"***"},"user":{"id":"u-1"}}}`,
+		},
+		{
+			"loadbalancer",
+			`{"loadbalancer": {"id": "lb-1", "name": "web"}}`,
+			`{"loadbalancer":{"id":"lb-1","name":"web"}}`,
+		},
+		{
+			"no JSON",
+			`password=s3cret`,
+			`password=s3cret`,
+		},
+		{
+			"empty",
+			``,
+			``,
+		},
+	} {
+		if got := string(transport.RedactBody([]byte(tc.body))); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transport provides http.RoundTrippers which are plugged into the
+// gophercloud ProviderClient to observe the traffic of oli.
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Trace formats supported by NewTracer.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Exchange is a single request together with its response.
+type Exchange struct {
+	Time           time.Time   `json:"time"`
+	Method         string      `json:"method"`
+	URL            string      `json:"url"`
+	RequestHeader  http.Header `json:"request_header,omitempty"`
+	RequestBody    string      `json:"request_body,omitempty"`
+	Status         int         `json:"status,omitempty"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+	ResponseBody   string      `json:"response_body,omitempty"`
+	LatencyMillis  int64       `json:"latency_ms"`
+	TransportError string      `json:"error,omitempty"`
+}
+
+type tracer struct {
+	next   http.RoundTripper
+	out    io.Writer
+	format string
+	mu     sync.Mutex
+}
+
+// NewTracer returns a RoundTripper which writes every request and response
+// passing through next to out, with all credentials redacted.
+func NewTracer(next http.RoundTripper, out io.Writer, format string) (http.RoundTripper, error) {
+	if format != FormatText && format != FormatJSON {
+		return nil, fmt.Errorf("unsupported trace format %q, use %s or %s", format, FormatText, FormatJSON)
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &tracer{next: next, out: out, format: format}, nil
+}
+
+func (t *tracer) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex, resp, err := roundTrip(t.next, req)
+	if ex == nil {
+		return resp, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.format == FormatJSON {
+		data, _ := json.Marshal(ex)
+		fmt.Fprintf(t.out, "%s\n", data)
+	} else {
+		writeText(t.out, ex)
+	}
+	return resp, err
+}
+
+// roundTrip sends req through next and captures the redacted exchange. The
+// bodies of request and response are buffered, so that they can still be
+// read by the caller.
+func roundTrip(next http.RoundTripper, req *http.Request) (*Exchange, *http.Response, error) {
+	ex := &Exchange{
+		Time:          time.Now().UTC(),
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		RequestHeader: RedactHeader(req.Header),
+	}
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		ex.RequestBody = string(RedactBody(body))
+	}
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+	ex.LatencyMillis = int64(time.Since(start) / time.Millisecond)
+	if err != nil {
+		ex.TransportError = err.Error()
+		return ex, resp, err
+	}
+	ex.Status = resp.StatusCode
+	ex.ResponseHeader = RedactHeader(resp.Header)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		ex.TransportError = err.Error()
+		return ex, nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	ex.ResponseBody = string(RedactBody(body))
+	return ex, resp, nil
+}
+
+func writeText(out io.Writer, ex *Exchange) {
+	fmt.Fprintf(out, "--> %s %s\n", ex.Method, ex.URL)
+	writeHeader(out, ex.RequestHeader)
+	if ex.RequestBody != "" {
+		fmt.Fprintf(out, "    %s\n", ex.RequestBody)
+	}
+	if ex.TransportError != "" {
+		fmt.Fprintf(out, "<-- error after %dms: %s\n\n", ex.LatencyMillis, ex.TransportError)
+		return
+	}
+	fmt.Fprintf(out, "<-- %d %s (%dms)\n", ex.Status, http.StatusText(ex.Status), ex.LatencyMillis)
+	writeHeader(out, ex.ResponseHeader)
+	if ex.ResponseBody != "" {
+		fmt.Fprintf(out, "    %s\n", ex.ResponseBody)
+	}
+	fmt.Fprintln(out)
+}
+
+func writeHeader(out io.Writer, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range h[key] {
+			fmt.Fprintf(out, "    %s: %s\n", key, value)
+		}
+	}
+}
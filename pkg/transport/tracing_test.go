@@ -0,0 +1,60 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/afritzler/oli/pkg/transport"
+)
+
+type brokenReader struct{}
+
+func (brokenReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+type unreachable struct {
+	t *testing.T
+}
+
+func (u unreachable) RoundTrip(req *http.Request) (*http.Response, error) {
+	u.t.Errorf("request %s %s was sent", req.Method, req.URL)
+	return nil, errors.New("unreachable")
+}
+
+func TestTraceUnreadableBody(t *testing.T) {
+	for _, format := range []string{transport.FormatText, transport.FormatJSON} {
+		var out bytes.Buffer
+		tracer, err := transport.NewTracer(unreachable{t}, &out, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest(http.MethodPost, "http://keystone/v3/auth/tokens", ioutil.NopCloser(brokenReader{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tracer.RoundTrip(req); err == nil {
+			t.Errorf("%s: the request was sent without its body", format)
+		}
+		if out.Len() != 0 {
+			t.Errorf("%s: got trace %q of a request which was not sent", format, out.String())
+		}
+	}
+}
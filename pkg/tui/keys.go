@@ -0,0 +1,93 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"bufio"
+)
+
+// Key is a decoded key press. Printable keys carry their rune, all others
+// one of the Key* constants.
+type Key struct {
+	Rune    rune
+	Special int
+}
+
+// Special keys.
+const (
+	KeyNone = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyCtrlC
+)
+
+// escapes maps the escape sequences of common terminals, without the leading
+// ESC, to special keys.
+var escapes = map[string]int{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[7~": KeyHome, "[8~": KeyEnd,
+	"[5~": KeyPageUp, "[6~": KeyPageDown,
+}
+
+// ReadKey reads a single key press from a terminal in raw mode. A lone ESC is
+// told apart from an escape sequence by whether more input is buffered, since
+// terminals send a sequence at once.
+func ReadKey(r *bufio.Reader) (Key, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return Key{}, err
+	}
+	switch c {
+	case '\r', '\n':
+		return Key{Special: KeyEnter}, nil
+	case 0x7f, 0x08:
+		return Key{Special: KeyBackspace}, nil
+	case 0x03:
+		return Key{Special: KeyCtrlC}, nil
+	case 0x1b:
+	default:
+		return Key{Rune: c}, nil
+	}
+	if r.Buffered() == 0 {
+		return Key{Special: KeyEscape}, nil
+	}
+	seq := ""
+	for r.Buffered() > 0 && len(seq) < 8 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return Key{}, err
+		}
+		seq += string(b)
+		if special, ok := escapes[seq]; ok {
+			return Key{Special: special}, nil
+		}
+		// a sequence ends with a letter or ~ after its first byte
+		if len(seq) > 1 && (b == '~' || b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z') {
+			break
+		}
+	}
+	return Key{}, nil
+}
@@ -0,0 +1,41 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestReadKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("a\x1b[A\x1b[6~\r\x7fü\x03"))
+	want := []Key{{Rune: 'a'}, {Special: KeyUp}, {Special: KeyPageDown}, {Special: KeyEnter}, {Special: KeyBackspace}, {Rune: 'ü'}, {Special: KeyCtrlC}}
+	for i, w := range want {
+		k, err := ReadKey(r)
+		if err != nil {
+			t.Fatalf("key %d: %s", i, err)
+		}
+		if k != w {
+			t.Errorf("key %d: got %+v, want %+v", i, k, w)
+		}
+	}
+
+	// a lone escape is not followed by buffered input
+	k, err := ReadKey(bufio.NewReader(strings.NewReader("\x1b")))
+	if err != nil || k.Special != KeyEscape {
+		t.Errorf("got %+v, %v, want escape", k, err)
+	}
+}
@@ -0,0 +1,593 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tui implements the state and rendering of "oli tui", a full-screen
+// browser over an inventory. It does not touch the terminal itself: keys are
+// fed into a Model, which renders frames as lines of text with ANSI escapes.
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+// Action tells the caller what to do after a key press.
+type Action int
+
+const (
+	// ActionNone asks for the next key.
+	ActionNone Action = iota
+	// ActionQuit ends the browser without deleting anything.
+	ActionQuit
+	// ActionDelete ends the browser, the confirmed plan is returned by Plan.
+	ActionDelete
+)
+
+type mode int
+
+const (
+	browsing mode = iota
+	editingFilter
+	confirming
+)
+
+// statuses are the status filters, in the order "s" cycles through them. A
+// LoadBalancer matches if its provisioning status starts with the filter or
+// its operating status equals it.
+var statuses = []string{"", "ACTIVE", "ERROR", "PENDING", "ONLINE", "OFFLINE", "DEGRADED"}
+
+// Filter selects the LoadBalancers shown in the tree.
+type Filter struct {
+	// Name matches LoadBalancers whose name or ID contains it, ignoring
+	// case.
+	Name      string
+	Status    string
+	EmptyOnly bool
+}
+
+// row is a line of the tree.
+type row struct {
+	inventory.Reference
+	key         string
+	depth       int
+	lbID        string
+	hasChildren bool
+}
+
+// Model is the state of the browser.
+type Model struct {
+	// Width and Height are the size of the terminal.
+	Width, Height int
+
+	inv      *inventory.Inventory
+	dryRun   bool
+	filter   Filter
+	expanded map[string]bool
+	marked   map[string]bool
+	rows     []row
+	cursor   int
+	offset   int
+	mode     mode
+	input    string
+	plan     *client.Plan
+	message  string
+}
+
+// NewModel returns a browser over an inventory. dryRun is shown on the
+// confirmation screen.
+func NewModel(inv *inventory.Inventory, dryRun bool) *Model {
+	m := &Model{Width: 80, Height: 24, inv: inv, dryRun: dryRun, expanded: map[string]bool{}, marked: map[string]bool{}}
+	m.refresh()
+	return m
+}
+
+// Plan returns the confirmed deletion plan after ActionDelete.
+func (m *Model) Plan() *client.Plan {
+	return m.plan
+}
+
+// Marked returns the IDs of the marked LoadBalancers in inventory order.
+func (m *Model) Marked() []string {
+	var ids []string
+	for _, lb := range m.inv.LoadBalancers {
+		if m.marked[lb.ID] {
+			ids = append(ids, lb.ID)
+		}
+	}
+	return ids
+}
+
+// HandleKey updates the state for a key press.
+func (m *Model) HandleKey(k Key) Action {
+	m.message = ""
+	if k.Special == KeyCtrlC {
+		return ActionQuit
+	}
+	switch m.mode {
+	case editingFilter:
+		m.editFilter(k)
+		return ActionNone
+	case confirming:
+		switch {
+		case k.Rune == 'y':
+			return ActionDelete
+		case k.Rune == 'n' || k.Rune == 'q' || k.Special == KeyEscape:
+			m.mode = browsing
+			m.plan = nil
+		}
+		return ActionNone
+	}
+
+	page := m.treeHeight() - 1
+	if page < 1 {
+		page = 1
+	}
+	switch {
+	case k.Rune == 'q':
+		return ActionQuit
+	case k.Special == KeyUp || k.Rune == 'k':
+		m.move(-1)
+	case k.Special == KeyDown || k.Rune == 'j':
+		m.move(1)
+	case k.Special == KeyPageUp:
+		m.move(-page)
+	case k.Special == KeyPageDown:
+		m.move(page)
+	case k.Special == KeyHome || k.Rune == 'g':
+		m.move(-len(m.rows))
+	case k.Special == KeyEnd || k.Rune == 'G':
+		m.move(len(m.rows))
+	case k.Special == KeyRight || k.Rune == 'l' || k.Special == KeyEnter:
+		if r, ok := m.current(); ok && r.hasChildren {
+			m.expanded[r.key] = true
+			m.refresh()
+		}
+	case k.Special == KeyLeft || k.Rune == 'h':
+		m.collapse()
+	case k.Rune == ' ':
+		if r, ok := m.current(); ok {
+			m.marked[r.lbID] = !m.marked[r.lbID]
+			if !m.marked[r.lbID] {
+				delete(m.marked, r.lbID)
+			}
+		}
+	case k.Rune == '/':
+		m.mode = editingFilter
+		m.input = m.filter.Name
+	case k.Rune == 's':
+		for i, s := range statuses {
+			if s == m.filter.Status {
+				m.filter.Status = statuses[(i+1)%len(statuses)]
+				break
+			}
+		}
+		m.refresh()
+	case k.Rune == 'e':
+		m.filter.EmptyOnly = !m.filter.EmptyOnly
+		m.refresh()
+	case k.Rune == 'c':
+		m.filter = Filter{}
+		m.refresh()
+	case k.Rune == 'd':
+		m.confirm()
+	}
+	return ActionNone
+}
+
+func (m *Model) editFilter(k Key) {
+	switch {
+	case k.Special == KeyEnter:
+		m.filter.Name = m.input
+		m.mode = browsing
+		m.refresh()
+	case k.Special == KeyEscape:
+		m.mode = browsing
+	case k.Special == KeyBackspace:
+		if runes := []rune(m.input); len(runes) > 0 {
+			m.input = string(runes[:len(runes)-1])
+		}
+	case k.Rune >= ' ':
+		m.input += string(k.Rune)
+	}
+}
+
+// confirm builds the deletion plan of the marked LoadBalancers.
+func (m *Model) confirm() {
+	ids := m.Marked()
+	if len(ids) == 0 {
+		m.message = "mark loadbalancers with space first"
+		return
+	}
+	plan, err := m.inv.PlanDeletion(ids)
+	if err != nil {
+		m.message = err.Error()
+		return
+	}
+	m.plan = plan
+	m.mode = confirming
+}
+
+func (m *Model) current() (row, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return row{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+func (m *Model) move(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// collapse closes the current row or, if it is closed, jumps to its parent.
+func (m *Model) collapse() {
+	r, ok := m.current()
+	if !ok {
+		return
+	}
+	if m.expanded[r.key] {
+		delete(m.expanded, r.key)
+		m.refresh()
+		return
+	}
+	for i := m.cursor - 1; i >= 0; i-- {
+		if m.rows[i].depth < r.depth {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// refresh rebuilds the rows after the filter or the expanded nodes changed,
+// keeping the cursor on the same row if it is still shown.
+func (m *Model) refresh() {
+	var key string
+	if r, ok := m.current(); ok {
+		key = r.key
+	}
+	m.rows = nil
+	for _, lb := range m.inv.LoadBalancers {
+		if m.matches(lb) {
+			m.addLoadBalancer(lb)
+		}
+	}
+	m.cursor = 0
+	for i, r := range m.rows {
+		if r.key == key {
+			m.cursor = i
+		}
+	}
+}
+
+func (m *Model) matches(lb loadbalancers.LoadBalancer) bool {
+	f := m.filter
+	if f.Name != "" {
+		name := strings.ToLower(f.Name)
+		if !strings.Contains(strings.ToLower(lb.Name), name) && !strings.Contains(strings.ToLower(lb.ID), name) {
+			return false
+		}
+	}
+	if f.Status != "" && !strings.HasPrefix(lb.ProvisioningStatus, f.Status) && lb.OperatingStatus != f.Status {
+		return false
+	}
+	if f.EmptyOnly && (len(m.inv.ListenersOf(lb.ID)) > 0 || len(m.inv.PoolsOf(lb.ID)) > 0) {
+		return false
+	}
+	return true
+}
+
+func (m *Model) addLoadBalancer(lb loadbalancers.LoadBalancer) {
+	add := func(parent string, depth int, kind, id, name string, hasChildren bool) (string, bool) {
+		key := parent + "/" + id
+		m.rows = append(m.rows, row{
+			Reference:   inventory.Reference{Object: client.Object{Kind: kind, ID: id}, Name: name},
+			key:         key,
+			depth:       depth,
+			lbID:        lb.ID,
+			hasChildren: hasChildren,
+		})
+		return key, hasChildren && m.expanded[key]
+	}
+	addPool := func(parent string, depth int, poolID string) {
+		for _, p := range m.inv.Pools {
+			if p.ID != poolID {
+				continue
+			}
+			members := m.inv.Members[p.ID]
+			key, open := add(parent, depth, client.KindPool, p.ID, p.Name, len(members) > 0 || p.MonitorID != "")
+			if !open {
+				return
+			}
+			for _, member := range members {
+				add(key, depth+1, client.KindMember, member.ID, member.Address, false)
+			}
+			if p.MonitorID != "" {
+				name := ""
+				for _, monitor := range m.inv.Monitors {
+					if monitor.ID == p.MonitorID {
+						name = monitor.Name
+					}
+				}
+				add(key, depth+1, client.KindHealthMonitor, p.MonitorID, name, false)
+			}
+		}
+	}
+
+	lbListeners := m.inv.ListenersOf(lb.ID)
+	lbPools := m.inv.PoolsOf(lb.ID)
+	lbKey, open := add("", 0, client.KindLoadBalancer, lb.ID, lb.Name, len(lbListeners)+len(lbPools) > 0)
+	if !open {
+		return
+	}
+	shown := map[string]bool{}
+	for _, l := range lbListeners {
+		listenerPools := m.inv.PoolsOfListener(l.ID)
+		policies := m.inv.L7PoliciesOf(l.ID)
+		key, open := add(lbKey, 1, client.KindListener, l.ID, l.Name, len(listenerPools)+len(policies) > 0)
+		for _, p := range listenerPools {
+			shown[p.ID] = true
+			if open {
+				addPool(key, 2, p.ID)
+			}
+		}
+		if open {
+			for _, p := range policies {
+				add(key, 2, client.KindL7Policy, p.ID, p.Name, false)
+			}
+		}
+	}
+	// pools which are only attached to the LoadBalancer
+	for _, p := range lbPools {
+		if !shown[p.ID] {
+			addPool(lbKey, 1, p.ID)
+		}
+	}
+}
+
+// treeHeight is the number of tree rows which fit on the screen, below the
+// header and above the two footer lines.
+func (m *Model) treeHeight() int {
+	return m.Height - 3
+}
+
+// Render returns the frame for the current state, exactly Height lines.
+func (m *Model) Render() []string {
+	var lines []string
+	header := fmt.Sprintf(" oli tui - %d loadbalancers, %d shown, %d marked", len(m.inv.LoadBalancers), m.countLoadBalancerRows(), len(m.marked))
+	if f := m.describeFilter(); f != "" {
+		header += " - filter: " + f
+	}
+	lines = append(lines, "\x1b[7m"+pad(header, m.Width)+"\x1b[0m")
+
+	var body []string
+	if m.mode == confirming {
+		body = m.renderPlan()
+	} else {
+		body = m.renderBrowser()
+	}
+	for len(body) < m.treeHeight() {
+		body = append(body, "")
+	}
+	lines = append(lines, body[:max(m.treeHeight(), 0)]...)
+
+	switch m.mode {
+	case editingFilter:
+		lines = append(lines, pad(" filter by name: "+m.input+"_", m.Width))
+	case confirming:
+		if m.dryRun {
+			lines = append(lines, pad(" y: run the plan as a dry run   n: back", m.Width))
+		} else {
+			lines = append(lines, pad(" y: DELETE everything listed above   n: back", m.Width))
+		}
+	default:
+		lines = append(lines, pad(" ↑↓ move  → open  ← close  space mark  / name  s status  e empty  c clear  d delete  q quit", m.Width))
+	}
+	lines = append(lines, pad(" "+m.message, m.Width))
+	return lines[:max(m.Height, 0)]
+}
+
+func (m *Model) countLoadBalancerRows() int {
+	n := 0
+	for _, r := range m.rows {
+		if r.depth == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *Model) describeFilter() string {
+	var parts []string
+	if m.filter.Name != "" {
+		parts = append(parts, fmt.Sprintf("name %q", m.filter.Name))
+	}
+	if m.filter.Status != "" {
+		parts = append(parts, "status "+m.filter.Status)
+	}
+	if m.filter.EmptyOnly {
+		parts = append(parts, "empty")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderBrowser renders the tree and, if the terminal is wide enough, the
+// details of the current row next to it.
+func (m *Model) renderBrowser() []string {
+	height := m.treeHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	treeWidth := m.Width
+	var details []string
+	if m.Width >= 100 {
+		treeWidth = m.Width * 55 / 100
+		details = m.renderDetails(m.Width - treeWidth - 3)
+	}
+
+	var lines []string
+	for i := 0; i < height; i++ {
+		line := ""
+		if n := m.offset + i; n < len(m.rows) {
+			line = m.renderRow(m.rows[n], n == m.cursor, treeWidth)
+		} else {
+			line = pad("", treeWidth)
+			if len(m.rows) == 0 && i == 0 {
+				line = pad(" no loadbalancers match", treeWidth)
+			}
+		}
+		if details != nil {
+			detail := ""
+			if i < len(details) {
+				detail = details[i]
+			}
+			line += " │ " + detail
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (m *Model) renderRow(r row, selected bool, width int) string {
+	mark := "   "
+	if r.depth == 0 {
+		mark = "[ ]"
+		if m.marked[r.lbID] {
+			mark = "[x]"
+		}
+	}
+	toggle := " "
+	if r.hasChildren {
+		toggle = "+"
+		if m.expanded[r.key] {
+			toggle = "-"
+		}
+	}
+	name := r.Name
+	if name == "" {
+		name = r.ID
+	}
+	text := fmt.Sprintf("%s %s%s [%s] %s", mark, strings.Repeat("  ", r.depth), toggle, abbreviations[r.Kind], name)
+	if r.depth == 0 {
+		if lb, ok := m.inv.LoadBalancer(r.ID); ok {
+			text += fmt.Sprintf(" (%s/%s)", lb.ProvisioningStatus, lb.OperatingStatus)
+		}
+	}
+	text = pad(text, width)
+	if selected {
+		return "\x1b[7m" + text + "\x1b[0m"
+	}
+	return text
+}
+
+// abbreviations are the kind labels of the tree, as used by "oli list".
+var abbreviations = map[string]string{
+	client.KindLoadBalancer:  "LB",
+	client.KindListener:      "L",
+	client.KindPool:          "P",
+	client.KindMember:        "M",
+	client.KindHealthMonitor: "HM",
+	client.KindL7Policy:      "L7",
+}
+
+func (m *Model) renderDetails(width int) []string {
+	r, ok := m.current()
+	if !ok {
+		return []string{}
+	}
+	d, err := m.inv.Describe(r.ID)
+	if err != nil {
+		return []string{pad(err.Error(), width)}
+	}
+	lines := []string{pad(fmt.Sprintf("%s %s", d.Kind, d.ID), width)}
+	for _, p := range d.Properties {
+		if p.Value != "" {
+			lines = append(lines, pad(fmt.Sprintf("%-20s %s", p.Name, p.Value), width))
+		}
+	}
+	if len(d.Parents) > 0 {
+		lines = append(lines, "", "parents:")
+		for _, p := range d.Parents {
+			lines = append(lines, pad(fmt.Sprintf("  %s %s", p.Kind, refName(p)), width))
+		}
+	}
+	if len(d.Children) > 0 {
+		lines = append(lines, "", "children:")
+		for _, c := range d.Children {
+			lines = append(lines, pad(fmt.Sprintf("  %s %s", c.Kind, refName(c)), width))
+		}
+	}
+	return lines
+}
+
+func refName(r inventory.Reference) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.ID
+}
+
+func (m *Model) renderPlan() []string {
+	title := "Deletion plan:"
+	if m.dryRun {
+		title = "Deletion plan (dry run, nothing will be deleted):"
+	}
+	lines := []string{" " + title, ""}
+	for _, entry := range m.plan.Entries {
+		if entry.Protected {
+			lines = append(lines, pad(fmt.Sprintf(" loadbalancer %s (%s) is protected by %q and is skipped", entry.LoadBalancerID, entry.Name, client.ProtectionMarker), m.Width))
+			continue
+		}
+		lines = append(lines, pad(fmt.Sprintf(" loadbalancer %s (%s):", entry.LoadBalancerID, entry.Name), m.Width))
+		for _, step := range entry.Steps {
+			lines = append(lines, pad(fmt.Sprintf("   delete %s %s", step.Kind, step.ID), m.Width))
+		}
+	}
+	if len(lines) > m.treeHeight() {
+		hidden := len(lines) - m.treeHeight() + 1
+		lines = append(lines[:max(m.treeHeight()-1, 0)], fmt.Sprintf(" ... %d more lines", hidden))
+	}
+	return lines
+}
+
+// pad cuts s to width runes or pads it with spaces.
+func pad(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) > width {
+		runes = runes[:width]
+	}
+	return string(runes) + strings.Repeat(" ", width-len(runes))
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
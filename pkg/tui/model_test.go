@@ -0,0 +1,169 @@
+// Copyright © 2018 NAME HERE <andreas.fritzler@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/listeners"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/lbaas_v2/pools"
+
+	"github.com/afritzler/oli/pkg/client"
+	"github.com/afritzler/oli/pkg/inventory"
+)
+
+func testInventory() *inventory.Inventory {
+	return &inventory.Inventory{
+		LoadBalancers: []loadbalancers.LoadBalancer{
+			{ID: "lb-web", Name: "web", ProvisioningStatus: "ACTIVE", OperatingStatus: "ONLINE"},
+			{ID: "lb-unused", Name: "unused", ProvisioningStatus: "ERROR", OperatingStatus: "OFFLINE"},
+		},
+		Listeners: []listeners.Listener{
+			{ID: "listener-http", Name: "http", Loadbalancers: []listeners.LoadBalancerID{{ID: "lb-web"}}},
+		},
+		Pools: []pools.Pool{
+			{ID: "pool-backends", Name: "backends", Listeners: []pools.ListenerID{{ID: "listener-http"}}},
+		},
+		Members: map[string][]pools.Member{
+			"pool-backends": {{ID: "member-1", Address: "10.1.0.5"}},
+		},
+	}
+}
+
+// keys feeds runes and special keys into the model and returns the last
+// action.
+func keys(m *Model, input ...interface{}) Action {
+	action := ActionNone
+	for _, k := range input {
+		switch k := k.(type) {
+		case string:
+			for _, r := range k {
+				action = m.HandleKey(Key{Rune: r})
+			}
+		case int:
+			action = m.HandleKey(Key{Special: k})
+		}
+	}
+	return action
+}
+
+func screen(m *Model) string {
+	return strings.Join(m.Render(), "\n")
+}
+
+func TestModelTree(t *testing.T) {
+	m := NewModel(testInventory(), true)
+	m.Width, m.Height = 120, 20
+
+	out := screen(m)
+	if !strings.Contains(out, "[LB] web") || !strings.Contains(out, "[LB] unused") || strings.Contains(out, "[L] http") {
+		t.Fatalf("unexpected collapsed tree:\n%s", out)
+	}
+	// expand the listener and the pool
+	keys(m, KeyRight, "j", "l", "j", "l")
+	out = screen(m)
+	for _, want := range []string{"[L] http", "[P] backends", "[M] 10.1.0.5"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expanded tree does not contain %q:\n%s", want, out)
+		}
+	}
+	// the details pane shows the selected pool
+	if !strings.Contains(out, "pool pool-backends") {
+		t.Errorf("details of the pool are missing:\n%s", out)
+	}
+	// h on a collapsed member jumps to its pool, the next h closes it
+	keys(m, "j", "h", "h")
+	if out = screen(m); strings.Contains(out, "[M] 10.1.0.5") {
+		t.Errorf("pool was not collapsed:\n%s", out)
+	}
+	if r, _ := m.current(); r.ID != "pool-backends" {
+		t.Errorf("cursor is on %s, want the pool", r.ID)
+	}
+}
+
+func TestModelFilters(t *testing.T) {
+	m := NewModel(testInventory(), true)
+	m.Width, m.Height = 80, 20
+
+	keys(m, "e")
+	if out := screen(m); strings.Contains(out, "[LB] web") || !strings.Contains(out, "[LB] unused") {
+		t.Errorf("empty filter is not applied:\n%s", out)
+	}
+	keys(m, "c", "/", "WE", KeyEnter)
+	if out := screen(m); !strings.Contains(out, "[LB] web") || strings.Contains(out, "[LB] unused") {
+		t.Errorf("name filter is not applied:\n%s", out)
+	}
+	// cycle the status filter to ERROR
+	keys(m, "c", "s", "s")
+	if out := screen(m); strings.Contains(out, "[LB] web") || !strings.Contains(out, "status ERROR") {
+		t.Errorf("status filter is not applied:\n%s", out)
+	}
+}
+
+func TestModelDelete(t *testing.T) {
+	m := NewModel(testInventory(), false)
+	m.Width, m.Height = 80, 20
+
+	keys(m, "d")
+	if !strings.Contains(screen(m), "mark loadbalancers") {
+		t.Errorf("deleting without marks is not refused:\n%s", screen(m))
+	}
+	// mark both, unmark the first again
+	keys(m, " ", "j", " ", "k", " ")
+	if got := m.Marked(); len(got) != 1 || got[0] != "lb-unused" {
+		t.Fatalf("got marks %v, want lb-unused", got)
+	}
+	keys(m, "d")
+	out := screen(m)
+	if !strings.Contains(out, "delete "+client.KindLoadBalancer+" lb-unused") || !strings.Contains(out, "DELETE") {
+		t.Errorf("plan is not shown:\n%s", out)
+	}
+	// n goes back to browsing, y confirms
+	if action := keys(m, "n", "d", "y"); action != ActionDelete {
+		t.Fatalf("got action %v, want delete", action)
+	}
+	if plan := m.Plan(); len(plan.Entries) != 1 || plan.Entries[0].LoadBalancerID != "lb-unused" {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+	if action := keys(m, KeyCtrlC); action != ActionQuit {
+		t.Errorf("got action %v, want quit", action)
+	}
+}
+
+func TestRenderSize(t *testing.T) {
+	m := NewModel(testInventory(), true)
+	for _, size := range [][2]int{{80, 24}, {120, 5}, {10, 3}} {
+		m.Width, m.Height = size[0], size[1]
+		lines := m.Render()
+		if len(lines) != m.Height {
+			t.Errorf("%dx%d: got %d lines", m.Width, m.Height, len(lines))
+		}
+	}
+
+	// the deletion plan on a tiny terminal
+	keys(m, " d")
+	if m.mode != confirming {
+		t.Fatalf("not confirming a plan")
+	}
+	for _, size := range [][2]int{{80, 24}, {80, 4}, {80, 3}} {
+		m.Width, m.Height = size[0], size[1]
+		lines := m.Render()
+		if len(lines) != m.Height {
+			t.Errorf("plan %dx%d: got %d lines", m.Width, m.Height, len(lines))
+		}
+	}
+}